@@ -5,6 +5,7 @@ go 1.22
 require (
 	gitee.com/chunanyong/dm v1.8.15
 	gitee.com/liuzongyang/libpq v1.0.9
+	github.com/ClickHouse/clickhouse-go/v2 v2.26.0
 	github.com/emirpasic/gods v1.18.1
 	github.com/gin-gonic/gin v1.10.0
 	github.com/glebarez/sqlite v1.11.0
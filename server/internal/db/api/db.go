@@ -26,6 +26,7 @@ import (
 	"mayfly-go/pkg/utils/collx"
 	"mayfly-go/pkg/utils/stringx"
 	"mayfly-go/pkg/ws"
+	"slices"
 	"strings"
 	"time"
 
@@ -39,6 +40,8 @@ type Db struct {
 	DbSqlExecApp application.DbSqlExec `inject:""`
 	MsgApp       msgapp.Msg            `inject:""`
 	TagApp       tagapp.TagTree        `inject:"TagTreeApp"`
+
+	DbSchemaDiffApp application.DbSchemaDiff `inject:""`
 }
 
 // @router /api/dbs [get]
@@ -155,6 +158,7 @@ func (d *Db) ExecSql(rc *req.Ctx) {
 	if execResAll != nil {
 		colAndRes["columns"] = execResAll.Columns
 		colAndRes["res"] = execResAll.Res
+		colAndRes["warnings"] = execResAll.Warnings
 	}
 	rc.ResData = colAndRes
 }
@@ -261,7 +265,11 @@ func (d *Db) ExecSqlFile(rc *req.Ctx) {
 			execReq.Sql = sql
 			_, err = d.DbSqlExecApp.Exec(rc.MetaCtx, execReq)
 		} else {
-			_, err = dbConn.Exec(sql)
+			// 不记录执行记录的sql同样需要审核
+			err = d.DbSqlExecApp.ReviewSql(rc.MetaCtx, dbConn, sql)
+			if err == nil {
+				_, err = dbConn.Exec(sql)
+			}
 		}
 
 		biz.ErrIsNilAppendErr(err, "%s")
@@ -422,6 +430,89 @@ func (d *Db) CopyTable(rc *req.Ctx) {
 	biz.ErrIsNilAppendErr(err, "拷贝表失败: %s")
 }
 
+// 对比两个库的表结构差异，并生成将目标库变更为源库结构的sql，生成的sql可通过执行sql（包括审批流程）进行执行
+func (d *Db) SchemaDiff(rc *req.Ctx) {
+	form := req.BindJsonAndValid(rc, new(form.DbSchemaDiffForm))
+	rc.ReqParam = form
+
+	loginAccountId := rc.GetLoginAccount().Id
+	srcConn, err := d.DbApp.GetDbConn(form.SrcDbId, form.SrcDb)
+	biz.ErrIsNilAppendErr(err, "获取源库连接失败: %s")
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(loginAccountId, srcConn.Info.CodePath...), "%s")
+
+	targetConn, err := d.DbApp.GetDbConn(form.TargetDbId, form.TargetDb)
+	biz.ErrIsNilAppendErr(err, "获取目标库连接失败: %s")
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(loginAccountId, targetConn.Info.CodePath...), "%s")
+
+	res, err := d.DbSchemaDiffApp.Diff(rc.MetaCtx, &dto.DbSchemaDiff{
+		SrcConn:    srcConn,
+		TargetConn: targetConn,
+		TableNames: form.TableNames,
+		AllowDrop:  form.AllowDrop,
+	})
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+// 获取数据库当前活跃会话，仅返回该库配置的数据库下的会话
+func (d *Db) Sessions(rc *req.Ctx) {
+	dbConn := d.getDbConn(rc)
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(rc.GetLoginAccount().Id, dbConn.Info.CodePath...), "%s")
+
+	smd, err := dbConn.GetMetaData().GetSessionMetaData()
+	biz.ErrIsNil(err)
+	rc.ResData = d.getDbSessions(getDbId(rc), smd)
+}
+
+// 终止数据库会话
+func (d *Db) KillSession(rc *req.Ctx) {
+	dbConn, smd, sessionId := d.getSessionMetaData(rc)
+	rc.ReqParam = fmt.Sprintf("%s -> 会话: %s", dbConn.Info.GetLogDesc(), sessionId)
+	biz.ErrIsNilAppendErr(smd.KillSession(sessionId), "终止会话失败: %s")
+}
+
+// 取消数据库会话正在执行的sql
+func (d *Db) CancelSessionQuery(rc *req.Ctx) {
+	dbConn, smd, sessionId := d.getSessionMetaData(rc)
+	rc.ReqParam = fmt.Sprintf("%s -> 会话: %s", dbConn.Info.GetLogDesc(), sessionId)
+	biz.ErrIsNilAppendErr(smd.CancelQuery(sessionId), "取消会话查询失败: %s")
+}
+
+// 获取会话管理接口，并校验目标会话属于该库配置的数据库
+func (d *Db) getSessionMetaData(rc *req.Ctx) (*dbi.DbConn, dbi.SessionMetaData, string) {
+	form := req.BindJsonAndValid(rc, new(form.DbSessionForm))
+
+	dbId := getDbId(rc)
+	dbConn, err := d.DbApp.GetDbConn(dbId, form.Db)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(rc.GetLoginAccount().Id, dbConn.Info.CodePath...), "%s")
+
+	smd, err := dbConn.GetMetaData().GetSessionMetaData()
+	biz.ErrIsNil(err)
+	biz.IsTrue(slices.ContainsFunc(d.getDbSessions(dbId, smd), func(session dbi.DbSession) bool {
+		return session.Id == form.SessionId
+	}), "会话不存在或不属于该库配置的数据库")
+	return dbConn, smd, form.SessionId
+}
+
+// 获取该库配置的数据库下的会话，实例上其他数据库的会话不可见
+func (d *Db) getDbSessions(dbId uint64, smd dbi.SessionMetaData) []dbi.DbSession {
+	db, err := d.DbApp.GetById(dbId)
+	biz.ErrIsNilAppendErr(err, "获取数据库信息失败: %s")
+	sessions, err := smd.GetSessions()
+	biz.ErrIsNilAppendErr(err, "获取会话信息失败: %s")
+
+	// 有schema的库配置为'database/schema'格式，会话所在库可能为database或schema
+	dbNames := make([]string, 0)
+	for _, dbName := range strings.Fields(db.Database) {
+		dbNames = append(dbNames, dbName)
+		dbNames = append(dbNames, strings.Split(dbName, "/")...)
+	}
+	return collx.ArrayFilter(sessions, func(session dbi.DbSession) bool {
+		return session.Db != "" && slices.ContainsFunc(dbNames, func(dbName string) bool { return strings.EqualFold(dbName, session.Db) })
+	})
+}
+
 func getDbId(rc *req.Ctx) uint64 {
 	dbId := rc.PathParamInt("dbId")
 	biz.IsTrue(dbId > 0, "dbId错误")
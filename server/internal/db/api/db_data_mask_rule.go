@@ -0,0 +1,50 @@
+package api
+
+import (
+	"mayfly-go/internal/db/api/form"
+	"mayfly-go/internal/db/api/vo"
+	"mayfly-go/internal/db/application"
+	"mayfly-go/internal/db/application/dto"
+	"mayfly-go/internal/db/domain/entity"
+
+	tagapp "mayfly-go/internal/tag/application"
+	tagentity "mayfly-go/internal/tag/domain/entity"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+)
+
+type DbDataMaskRule struct {
+	DbDataMaskRuleApp application.DbDataMaskRule `inject:""`
+	TagTreeRelateApp  tagapp.TagTreeRelate       `inject:"TagTreeRelateApp"`
+}
+
+func (d *DbDataMaskRule) DbDataMaskRules(rc *req.Ctx) {
+	cond := req.BindQuery(rc, new(entity.DbDataMaskRule))
+
+	var vos []*vo.DbDataMaskRuleVO
+	err := d.DbDataMaskRuleApp.ListByCondToAny(cond, &vos)
+	biz.ErrIsNil(err)
+
+	d.TagTreeRelateApp.FillTagInfo(tagentity.TagRelateTypeDbDataMask, collx.ArrayMap(vos, func(rvo *vo.DbDataMaskRuleVO) tagentity.IRelateTag {
+		return rvo
+	})...)
+
+	rc.ResData = vos
+}
+
+func (d *DbDataMaskRule) Save(rc *req.Ctx) {
+	ruleForm := new(form.DbDataMaskRuleForm)
+	rule := req.BindJsonAndCopyTo[*entity.DbDataMaskRule](rc, ruleForm, new(entity.DbDataMaskRule))
+	rc.ReqParam = ruleForm
+
+	err := d.DbDataMaskRuleApp.SaveRule(rc.MetaCtx, &dto.SaveDbDataMaskRule{
+		Rule:      rule,
+		CodePaths: ruleForm.CodePaths,
+	})
+	biz.ErrIsNil(err)
+}
+
+func (d *DbDataMaskRule) Delete(rc *req.Ctx) {
+	biz.ErrIsNil(d.DbDataMaskRuleApp.DeleteRule(rc.MetaCtx, uint64(rc.PathParamInt("id"))))
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mayfly-go/internal/db/api/form"
+	"mayfly-go/internal/db/application"
+	"mayfly-go/internal/db/application/dto"
+	"mayfly-go/internal/db/domain/entity"
+	tagapp "mayfly-go/internal/tag/application"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/stringx"
+)
+
+type DbQueryJob struct {
+	DbQueryJobApp application.DbQueryJob `inject:""`
+	DbApp         application.Db         `inject:""`
+	TagApp        tagapp.TagTree         `inject:"TagTreeApp"`
+}
+
+// 获取当前账号提交的异步查询任务
+func (d *DbQueryJob) DbQueryJobs(rc *req.Ctx) {
+	queryCond, page := req.BindQueryAndPage(rc, new(entity.DbQueryJobQuery))
+	queryCond.CreatorId = rc.GetLoginAccount().Id
+
+	res, err := d.DbQueryJobApp.GetPageList(queryCond, page, new([]entity.DbQueryJob), "id DESC")
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+// 提交异步查询任务
+func (d *DbQueryJob) Submit(rc *req.Ctx) {
+	jobForm := req.BindJsonAndValid(rc, new(form.DbQueryJobForm))
+
+	dbConn, err := d.DbApp.GetDbConn(jobForm.DbId, jobForm.Db)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(rc.GetLoginAccount().Id, dbConn.Info.CodePath...), "%s")
+
+	sqlBytes, err := base64.StdEncoding.DecodeString(jobForm.Sql)
+	biz.ErrIsNilAppendErr(err, "sql解码失败: %s")
+	sql := stringx.TrimSpaceAndBr(string(sqlBytes))
+	biz.NotEmpty(sql, "sql不能为空")
+	rc.ReqParam = fmt.Sprintf("%s [%s]\n-> %s", dbConn.Info.GetLogDesc(), jobForm.Format, sql)
+
+	job, err := d.DbQueryJobApp.Submit(rc.MetaCtx, &dto.SubmitDbQueryJob{
+		DbId:   jobForm.DbId,
+		Db:     jobForm.Db,
+		Sql:    sql,
+		Format: entity.DbQueryJobFormat(jobForm.Format),
+		Remark: jobForm.Remark,
+		DbConn: dbConn,
+	})
+	biz.ErrIsNil(err)
+	rc.ResData = job.Id
+}
+
+func (d *DbQueryJob) Cancel(rc *req.Ctx) {
+	biz.ErrIsNil(d.DbQueryJobApp.Cancel(rc.MetaCtx, uint64(rc.PathParamInt("id"))))
+}
+
+func (d *DbQueryJob) Delete(rc *req.Ctx) {
+	biz.ErrIsNil(d.DbQueryJobApp.Delete(rc.MetaCtx, uint64(rc.PathParamInt("id"))))
+}
+
+// 下载异步查询任务导出文件
+func (d *DbQueryJob) Download(rc *req.Ctx) {
+	job, file, err := d.DbQueryJobApp.OpenFile(rc.MetaCtx, uint64(rc.PathParamInt("id")))
+	biz.ErrIsNil(err)
+	defer file.Close()
+
+	rc.ReqParam = fmt.Sprintf("jobId: %d, file: %s", job.Id, job.FileName)
+	rc.Download(file, job.FileName)
+}
@@ -1,11 +1,17 @@
 package api
 
 import (
+	"context"
+	"fmt"
 	"mayfly-go/internal/db/application"
+	"mayfly-go/internal/db/config"
+	"mayfly-go/internal/db/dbm/dbi"
 	"mayfly-go/internal/db/domain/entity"
+	tagapp "mayfly-go/internal/tag/application"
 	"mayfly-go/pkg/biz"
 	"mayfly-go/pkg/req"
 	"mayfly-go/pkg/utils/collx"
+	"time"
 
 	"github.com/may-fly/cast"
 
@@ -14,6 +20,8 @@ import (
 
 type DbSqlExec struct {
 	DbSqlExecApp application.DbSqlExec `inject:""`
+	DbApp        application.Db        `inject:""`
+	TagApp       tagapp.TagTree        `inject:"TagTreeApp"`
 }
 
 func (d *DbSqlExec) DbSqlExecs(rc *req.Ctx) {
@@ -28,3 +36,35 @@ func (d *DbSqlExec) DbSqlExecs(rc *req.Ctx) {
 	biz.ErrIsNil(err)
 	rc.ResData = res
 }
+
+// 获取sql执行记录的回滚sql
+func (d *DbSqlExec) RollbackSql(rc *req.Ctx) {
+	dbSqlExec, dbConn := d.getSqlExecAndConn(rc)
+	rc.ReqParam = fmt.Sprintf("%s -> 获取sql执行记录[%d]回滚sql", dbConn.Info.GetLogDesc(), dbSqlExec.Id)
+	sqls, err := d.DbSqlExecApp.GenRollbackSql(rc.MetaCtx, dbSqlExec, dbConn)
+	biz.ErrIsNil(err)
+	rc.ResData = sqls
+}
+
+// 回滚sql执行记录，若库关联了审批流程，则需审批通过后执行
+func (d *DbSqlExec) Rollback(rc *req.Ctx) {
+	dbSqlExec, dbConn := d.getSqlExecAndConn(rc)
+	rc.ReqParam = fmt.Sprintf("%s -> 回滚sql执行记录[%d]: %s", dbConn.Info.GetLogDesc(), dbSqlExec.Id, dbSqlExec.Sql)
+
+	ctx, cancel := context.WithTimeout(rc.MetaCtx, time.Duration(config.GetDbms().SqlExecTl)*time.Second)
+	defer cancel()
+
+	res, err := d.DbSqlExecApp.Rollback(ctx, dbSqlExec, dbConn)
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+func (d *DbSqlExec) getSqlExecAndConn(rc *req.Ctx) (*entity.DbSqlExec, *dbi.DbConn) {
+	dbSqlExec, err := d.DbSqlExecApp.GetById(uint64(rc.PathParamInt("id")))
+	biz.ErrIsNil(err, "sql执行记录不存在")
+
+	dbConn, err := d.DbApp.GetDbConn(dbSqlExec.DbId, dbSqlExec.Db)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(rc.GetLoginAccount().Id, dbConn.Info.CodePath...), "%s")
+	return dbSqlExec, dbConn
+}
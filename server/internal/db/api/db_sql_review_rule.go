@@ -0,0 +1,50 @@
+package api
+
+import (
+	"mayfly-go/internal/db/api/form"
+	"mayfly-go/internal/db/api/vo"
+	"mayfly-go/internal/db/application"
+	"mayfly-go/internal/db/application/dto"
+	"mayfly-go/internal/db/domain/entity"
+
+	tagapp "mayfly-go/internal/tag/application"
+	tagentity "mayfly-go/internal/tag/domain/entity"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+)
+
+type DbSqlReviewRule struct {
+	DbSqlReviewRuleApp application.DbSqlReviewRule `inject:""`
+	TagTreeRelateApp   tagapp.TagTreeRelate        `inject:"TagTreeRelateApp"`
+}
+
+func (d *DbSqlReviewRule) DbSqlReviewRules(rc *req.Ctx) {
+	cond := req.BindQuery(rc, new(entity.DbSqlReviewRule))
+
+	var vos []*vo.DbSqlReviewRuleVO
+	err := d.DbSqlReviewRuleApp.ListByCondToAny(cond, &vos)
+	biz.ErrIsNil(err)
+
+	d.TagTreeRelateApp.FillTagInfo(tagentity.TagRelateTypeDbSqlReview, collx.ArrayMap(vos, func(rvo *vo.DbSqlReviewRuleVO) tagentity.IRelateTag {
+		return rvo
+	})...)
+
+	rc.ResData = vos
+}
+
+func (d *DbSqlReviewRule) Save(rc *req.Ctx) {
+	ruleForm := new(form.DbSqlReviewRuleForm)
+	rule := req.BindJsonAndCopyTo[*entity.DbSqlReviewRule](rc, ruleForm, new(entity.DbSqlReviewRule))
+	rc.ReqParam = ruleForm
+
+	err := d.DbSqlReviewRuleApp.SaveRule(rc.MetaCtx, &dto.SaveDbSqlReviewRule{
+		Rule:      rule,
+		CodePaths: ruleForm.CodePaths,
+	})
+	biz.ErrIsNil(err)
+}
+
+func (d *DbSqlReviewRule) Delete(rc *req.Ctx) {
+	biz.ErrIsNil(d.DbSqlReviewRuleApp.DeleteRule(rc.MetaCtx, uint64(rc.PathParamInt("id"))))
+}
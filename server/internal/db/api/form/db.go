@@ -35,3 +35,57 @@ type DbCopyTableForm struct {
 	TableName string `binding:"required" json:"tableName"`
 	CopyData  bool   `json:"copyData"` // 是否复制数据
 }
+
+// 表结构对比
+type DbSchemaDiffForm struct {
+	SrcDbId    uint64   `binding:"required" json:"srcDbId"`
+	SrcDb      string   `binding:"required" json:"srcDb"`
+	TargetDbId uint64   `binding:"required" json:"targetDbId"`
+	TargetDb   string   `binding:"required" json:"targetDb"`
+	TableNames []string `json:"tableNames"` // 需要对比的表，为空则对比全部表
+	AllowDrop  bool     `json:"allowDrop"`  // 是否生成删除表、删除列的sql
+}
+
+type DbDataMaskRuleForm struct {
+	Id            uint64   `json:"id"`
+	Name          string   `json:"name" binding:"required"`
+	Db            string   `json:"db"`                          // 库名，为空则匹配所有库
+	Table         string   `json:"table"`                       // 表名，为空则匹配所有表
+	Columns       []string `json:"columns" binding:"required"`  // 需要脱敏的列名
+	MaskType      string   `json:"maskType" binding:"required"` // 脱敏方式 phone、idcard、email、regex、hash、full
+	Pattern       string   `json:"pattern"`                     // 正则脱敏时匹配的正则表达式
+	Replacement   string   `json:"replacement"`                 // 正则脱敏时的替换内容
+	RowColumn     string   `json:"rowColumn"`                   // 行级脱敏条件列
+	RowPattern    string   `json:"rowPattern"`                  // 行级脱敏条件列值需匹配的正则表达式
+	ExemptRoleIds []int    `json:"exemptRoleIds"`               // 豁免脱敏的角色id
+	Status        int8     `json:"status" binding:"required"`
+	Remark        string   `json:"remark"`
+
+	CodePaths []string `json:"codePaths"`
+}
+
+type DbSqlReviewRuleForm struct {
+	Id       uint64 `json:"id"`
+	Name     string `json:"name" binding:"required"`
+	RuleType string `json:"ruleType" binding:"required"` // 规则类型
+	Level    string `json:"level" binding:"required"`    // 处理级别 error、warn
+	Param    string `json:"param"`                       // 规则参数
+	Status   int8   `json:"status" binding:"required"`
+	Remark   string `json:"remark"`
+
+	CodePaths []string `json:"codePaths"`
+}
+
+type DbQueryJobForm struct {
+	DbId   uint64 `binding:"required" json:"dbId"`   // 数据库id
+	Db     string `binding:"required" json:"db"`     // 数据库名
+	Sql    string `binding:"required" json:"sql"`    // 查询sql，base64编码
+	Format string `binding:"required" json:"format"` // 导出格式 csv、xlsx、json
+	Remark string `json:"remark"`
+}
+
+// 数据库会话操作表单
+type DbSessionForm struct {
+	Db        string `binding:"required" json:"db"`        // 数据库名
+	SessionId string `binding:"required" json:"sessionId"` // 会话id
+}
@@ -2,6 +2,8 @@ package vo
 
 import (
 	"mayfly-go/internal/db/domain/entity"
+	tagentity "mayfly-go/internal/tag/domain/entity"
+	"mayfly-go/pkg/model"
 	"time"
 )
 
@@ -27,3 +29,41 @@ type DbListVO struct {
 	Modifier   *string    `json:"modifier"`
 	ModifierId *int64     `json:"modifierId"`
 }
+
+type DbDataMaskRuleVO struct {
+	tagentity.RelateTags // 标签信息
+	model.Model
+
+	Name          string              `json:"name"`
+	Db            string              `json:"db"`
+	Table         string              `json:"table"`
+	Columns       model.Slice[string] `json:"columns"`
+	MaskType      string              `json:"maskType"`
+	Pattern       string              `json:"pattern"`
+	Replacement   string              `json:"replacement"`
+	RowColumn     string              `json:"rowColumn"`
+	RowPattern    string              `json:"rowPattern"`
+	ExemptRoleIds model.Slice[int]    `json:"exemptRoleIds"`
+	Status        int8                `json:"status"`
+	Remark        string              `json:"remark"`
+}
+
+func (r *DbDataMaskRuleVO) GetRelateId() uint64 {
+	return r.Id
+}
+
+type DbSqlReviewRuleVO struct {
+	tagentity.RelateTags // 标签信息
+	model.Model
+
+	Name     string `json:"name"`
+	RuleType string `json:"ruleType"`
+	Level    string `json:"level"`
+	Param    string `json:"param"`
+	Status   int8   `json:"status"`
+	Remark   string `json:"remark"`
+}
+
+func (r *DbSqlReviewRuleVO) GetRelateId() uint64 {
+	return r.Id
+}
@@ -13,6 +13,10 @@ func InitIoc() {
 	ioc.Register(new(dbSqlAppImpl), ioc.WithComponentName("DbSqlApp"))
 	ioc.Register(new(dataSyncAppImpl), ioc.WithComponentName("DbDataSyncTaskApp"))
 	ioc.Register(new(dbTransferAppImpl), ioc.WithComponentName("DbTransferTaskApp"))
+	ioc.Register(new(dbSchemaDiffAppImpl), ioc.WithComponentName("DbSchemaDiffApp"))
+	ioc.Register(new(dbDataMaskRuleAppImpl), ioc.WithComponentName("DbDataMaskRuleApp"))
+	ioc.Register(new(dbSqlReviewRuleAppImpl), ioc.WithComponentName("DbSqlReviewRuleApp"))
+	ioc.Register(new(dbQueryJobAppImpl), ioc.WithComponentName("DbQueryJobApp"))
 
 	ioc.Register(newDbScheduler(), ioc.WithComponentName("DbScheduler"))
 	ioc.Register(new(DbBackupApp), ioc.WithComponentName("DbBackupApp"))
@@ -33,6 +37,7 @@ func Init() {
 		}
 		GetDataSyncTaskApp().InitCronJob()
 		GetDbTransferTaskApp().InitJob()
+		GetDbQueryJobApp().InitJob()
 		InitDbFlowHandler()
 	})()
 }
@@ -59,3 +64,7 @@ func GetDataSyncTaskApp() DataSyncTask {
 func GetDbTransferTaskApp() DbTransferTask {
 	return ioc.Get[DbTransferTask]("DbTransferTaskApp")
 }
+
+func GetDbQueryJobApp() DbQueryJob {
+	return ioc.Get[DbQueryJob]("DbQueryJobApp")
+}
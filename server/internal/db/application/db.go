@@ -16,6 +16,7 @@ import (
 	"mayfly-go/pkg/errorx"
 	"mayfly-go/pkg/model"
 	"mayfly-go/pkg/utils/collx"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -53,6 +54,7 @@ type dbAppImpl struct {
 	dbSqlExecApp        DbSqlExec               `inject:"DbSqlExecApp"`
 	tagApp              tagapp.TagTree          `inject:"TagTreeApp"`
 	resourceAuthCertApp tagapp.ResourceAuthCert `inject:"ResourceAuthCertApp"`
+	dbDataMaskRuleApp   DbDataMaskRule          `inject:"DbDataMaskRuleApp"`
 }
 
 var _ (Db) = (*dbAppImpl)(nil)
@@ -232,6 +234,11 @@ func (d *dbAppImpl) DumpDb(ctx context.Context, reqParam *dto.DumpDb) error {
 	if err != nil {
 		return err
 	}
+	// 导出的数据同样需要脱敏
+	dataMasker, err := d.dbDataMaskRuleApp.GetDataMasker(ctx, dbConn)
+	if err != nil {
+		return err
+	}
 	writer.WriteString("\n-- ----------------------------")
 	writer.WriteString("\n-- 导出平台: mayfly-go")
 	writer.WriteString(fmt.Sprintf("\n-- 导出时间: %s ", time.Now().Format("2006-01-02 15:04:05")))
@@ -306,10 +313,17 @@ func (d *dbAppImpl) DumpDb(ctx context.Context, reqParam *dto.DumpDb) error {
 				quoteColNames = append(quoteColNames, dbMeta.QuoteIdentifier(col.ColumnName))
 			}
 
+			tableMasker := dataMasker.ForTables(tableName)
 			_, _ = dbConn.WalkTableRows(ctx, quoteTableName, func(row map[string]any, _ []*dbi.QueryColumn) error {
+				maskedColumns := tableMasker.MaskRow(row, nil)
 				rowValues := make([]string, len(columnMap[tableName]))
 				for i, col := range columnMap[tableName] {
-					rowValues[i] = dataHelper.WrapValue(row[col.ColumnName], dataHelper.GetDataType(string(col.DataType)))
+					dataType := dataHelper.GetDataType(string(col.DataType))
+					// 脱敏后的值均为字符串
+					if slices.Contains(maskedColumns, col.ColumnName) {
+						dataType = dbi.DataTypeString
+					}
+					rowValues[i] = dataHelper.WrapValue(row[col.ColumnName], dataType)
 				}
 
 				beforeInsert := dumpHelper.BeforeInsertSql(quoteSchema, quoteTableName)
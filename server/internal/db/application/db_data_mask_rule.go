@@ -0,0 +1,391 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"mayfly-go/internal/db/application/dto"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/internal/db/domain/repository"
+	sysapp "mayfly-go/internal/sys/application"
+	sysentity "mayfly-go/internal/sys/domain/entity"
+	tagapp "mayfly-go/internal/tag/application"
+	tagentity "mayfly-go/internal/tag/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/collx"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kanzihuang/vitess/go/vt/sqlparser"
+)
+
+type DbDataMaskRule interface {
+	base.App[*entity.DbDataMaskRule]
+
+	SaveRule(ctx context.Context, param *dto.SaveDbDataMaskRule) error
+
+	DeleteRule(ctx context.Context, id uint64) error
+
+	// GetDataMasker 获取当前登录账号访问指定库数据时的脱敏器，无需脱敏则返回nil
+	GetDataMasker(ctx context.Context, dbConn *dbi.DbConn) (*DataMasker, error)
+}
+
+type dbDataMaskRuleAppImpl struct {
+	base.AppImpl[*entity.DbDataMaskRule, repository.DbDataMaskRule]
+
+	tagTreeRelateApp tagapp.TagTreeRelate `inject:"TagTreeRelateApp"`
+	roleApp          sysapp.Role          `inject:"RoleApp"`
+}
+
+var _ (DbDataMaskRule) = (*dbDataMaskRuleAppImpl)(nil)
+
+// 注入DbDataMaskRuleRepo
+func (d *dbDataMaskRuleAppImpl) InjectDbDataMaskRuleRepo(repo repository.DbDataMaskRule) {
+	d.Repo = repo
+}
+
+func (d *dbDataMaskRuleAppImpl) SaveRule(ctx context.Context, param *dto.SaveDbDataMaskRule) error {
+	rule := param.Rule
+	if len(rule.Columns) == 0 {
+		return errorx.NewBiz("脱敏列不能为空")
+	}
+	if rule.MaskType == entity.DataMaskTypeRegex && rule.Replacement == "" {
+		rule.Replacement = "***"
+	}
+	if _, err := newDataMaskRule(rule); err != nil {
+		return err
+	}
+
+	return d.Tx(ctx, func(ctx context.Context) error {
+		return d.Save(ctx, rule)
+	}, func(ctx context.Context) error {
+		return d.tagTreeRelateApp.RelateTag(ctx, tagentity.TagRelateTypeDbDataMask, rule.Id, param.CodePaths...)
+	})
+}
+
+func (d *dbDataMaskRuleAppImpl) DeleteRule(ctx context.Context, id uint64) error {
+	_, err := d.GetById(id)
+	if err != nil {
+		return errorx.NewBiz("该脱敏规则不存在")
+	}
+
+	return d.Tx(ctx, func(ctx context.Context) error {
+		return d.DeleteById(ctx, id)
+	}, func(ctx context.Context) error {
+		return d.tagTreeRelateApp.DeleteByCond(ctx, &tagentity.TagTreeRelate{
+			RelateType: tagentity.TagRelateTypeDbDataMask,
+			RelateId:   id,
+		})
+	})
+}
+
+func (d *dbDataMaskRuleAppImpl) GetDataMasker(ctx context.Context, dbConn *dbi.DbConn) (*DataMasker, error) {
+	ruleIds, err := d.tagTreeRelateApp.GetRelateIds(ctx, tagentity.TagRelateTypeDbDataMask, dbConn.Info.CodePath...)
+	if err != nil {
+		return nil, errorx.NewBiz("获取数据脱敏规则失败: %s", err.Error())
+	}
+	if len(ruleIds) == 0 {
+		return nil, nil
+	}
+	rules, err := d.GetByIds(ruleIds)
+	if err != nil {
+		return nil, errorx.NewBiz("获取数据脱敏规则失败: %s", err.Error())
+	}
+
+	// 当前账号拥有的角色，用于判断是否豁免脱敏
+	var roleIds []int
+	if la := contextx.GetLoginAccount(ctx); la != nil {
+		accountRoles, err := d.roleApp.GetAccountRoles(la.Id)
+		if err != nil {
+			return nil, errorx.NewBiz("获取账号角色信息失败: %s", err.Error())
+		}
+		roleIds = collx.ArrayMap(accountRoles, func(ar *sysentity.AccountRole) int { return int(ar.RoleId) })
+	}
+
+	masker := &DataMasker{}
+	for _, rule := range rules {
+		if rule.Status != entity.DbDataMaskRuleStatusEnable || !matchMaskDb(rule.Db, dbConn.Info.Database) {
+			continue
+		}
+		if slices.ContainsFunc(rule.ExemptRoleIds, func(roleId int) bool { return slices.Contains(roleIds, roleId) }) {
+			continue
+		}
+		maskRule, err := newDataMaskRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		masker.rules = append(masker.rules, maskRule)
+	}
+	if len(masker.rules) == 0 {
+		return nil, nil
+	}
+	return masker, nil
+}
+
+// 规则库名为空则匹配所有库，有schema的库可只指定database部分
+func matchMaskDb(ruleDb string, database string) bool {
+	if ruleDb == "" {
+		return true
+	}
+	return strings.EqualFold(ruleDb, database) || strings.EqualFold(ruleDb, strings.Split(database, "/")[0])
+}
+
+// DataMasker 数据脱敏器，按照脱敏规则对查询结果进行脱敏
+type DataMasker struct {
+	rules []*dataMaskRule
+}
+
+// ForTables 获取仅包含指定表相关规则的脱敏器，tables为空表示无法确定访问的表，则保留所有规则
+func (dm *DataMasker) ForTables(tables ...string) *DataMasker {
+	if dm == nil || len(tables) == 0 {
+		return dm
+	}
+
+	rules := collx.ArrayFilter(dm.rules, func(rule *dataMaskRule) bool {
+		return rule.Table == "" || slices.ContainsFunc(tables, func(table string) bool { return strings.EqualFold(rule.Table, table) })
+	})
+	if len(rules) == 0 {
+		return nil
+	}
+	return &DataMasker{rules: rules}
+}
+
+// MaskRows 对行数据进行脱敏，columns为查询结果列信息，为nil则按结果列名匹配规则
+func (dm *DataMasker) MaskRows(rows []map[string]any, columns *SelectColumns) {
+	if dm == nil {
+		return
+	}
+	for _, row := range rows {
+		dm.MaskRow(row, columns)
+	}
+}
+
+// MaskRow 对单行数据进行脱敏，columns为查询结果列信息，为nil则按结果列名匹配规则，返回被脱敏的列名
+func (dm *DataMasker) MaskRow(row map[string]any, columns *SelectColumns) []string {
+	if dm == nil {
+		return nil
+	}
+
+	// 先计算所有脱敏值再回写，避免行级条件列被提前脱敏
+	masked := make(map[string]any)
+	for column, val := range row {
+		if val == nil {
+			continue
+		}
+		if columns != nil && columns.maskAll {
+			masked[column] = fullMask
+			continue
+		}
+		name, refs := columns.resolve(column)
+		// 非直接列引用的结果列（函数、运算、子查询等）引用了脱敏列时无法按规则部分脱敏，直接全部脱敏
+		if slices.ContainsFunc(refs, func(ref string) bool { return dm.matchRule(ref, row) != nil }) {
+			masked[column] = fullMask
+			continue
+		}
+		if rule := dm.matchRule(name, row); rule != nil {
+			masked[column] = rule.mask(val)
+		}
+	}
+	maskedColumns := make([]string, 0, len(masked))
+	for column, val := range masked {
+		row[column] = val
+		maskedColumns = append(maskedColumns, column)
+	}
+	return maskedColumns
+}
+
+// 获取与列及行数据匹配的脱敏规则
+func (dm *DataMasker) matchRule(column string, row map[string]any) *dataMaskRule {
+	for _, rule := range dm.rules {
+		if rule.matchColumn(column) && rule.matchRow(row) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// 全部脱敏时的替换值
+const fullMask = "******"
+
+// SelectColumns 查询结果列信息，用于确定结果列对应的原列
+type SelectColumns struct {
+	columns map[string]string   // 直接列引用的结果列，小写结果列名 -> 原列名
+	derived map[string][]string // 非直接列引用的结果列，小写结果列名 -> 表达式引用的列名
+	others  []string            // 无法确定结果列名的表达式引用的列名，作用于其余所有结果列
+	maskAll bool                // 是否全部脱敏所有结果列
+}
+
+// 无法解析查询语句时的结果列信息，无法确定结果列对应的原列（如别名、类型转换等），故全部脱敏所有结果列
+var unparsedSelectColumns = &SelectColumns{maskAll: true}
+
+// 获取结果列对应的原列名及需全部脱敏判断的引用列名
+func (sc *SelectColumns) resolve(column string) (string, []string) {
+	if sc == nil {
+		return column, nil
+	}
+	lowerColumn := strings.ToLower(column)
+	if origin, ok := sc.columns[lowerColumn]; ok {
+		return origin, nil
+	}
+	if refs, ok := sc.derived[lowerColumn]; ok {
+		return column, refs
+	}
+	return column, sc.others
+}
+
+type dataMaskRule struct {
+	*entity.DbDataMaskRule
+
+	regexp    *regexp.Regexp
+	rowRegexp *regexp.Regexp
+}
+
+func newDataMaskRule(rule *entity.DbDataMaskRule) (*dataMaskRule, error) {
+	maskRule := &dataMaskRule{DbDataMaskRule: rule}
+	switch rule.MaskType {
+	case entity.DataMaskTypePhone, entity.DataMaskTypeIdCard, entity.DataMaskTypeEmail, entity.DataMaskTypeHash, entity.DataMaskTypeFull:
+	case entity.DataMaskTypeRegex:
+		p, err := regexp.Compile(rule.Pattern)
+		if err != nil || rule.Pattern == "" {
+			return nil, errorx.NewBiz("脱敏规则[%s]正则表达式有误", rule.Name)
+		}
+		maskRule.regexp = p
+	default:
+		return nil, errorx.NewBiz("不支持的脱敏方式: %s", rule.MaskType)
+	}
+
+	if rule.RowColumn != "" {
+		p, err := regexp.Compile(rule.RowPattern)
+		if err != nil {
+			return nil, errorx.NewBiz("脱敏规则[%s]行级条件正则表达式有误", rule.Name)
+		}
+		maskRule.rowRegexp = p
+	}
+	return maskRule, nil
+}
+
+func (r *dataMaskRule) matchColumn(column string) bool {
+	return slices.ContainsFunc(r.Columns, func(c string) bool { return strings.EqualFold(c, column) })
+}
+
+// 判断行数据是否满足行级脱敏条件，未配置条件列则所有行均需脱敏，结果集中不含条件列时同样脱敏
+func (r *dataMaskRule) matchRow(row map[string]any) bool {
+	if r.rowRegexp == nil {
+		return true
+	}
+	val, ok := getRowValue(row, r.RowColumn)
+	if !ok {
+		return true
+	}
+	return val != nil && r.rowRegexp.MatchString(anyx.ToString(val))
+}
+
+func (r *dataMaskRule) mask(val any) any {
+	str := anyx.ToString(val)
+	switch r.MaskType {
+	case entity.DataMaskTypePhone, entity.DataMaskTypeIdCard:
+		return maskMiddle(str, 3, 4)
+	case entity.DataMaskTypeEmail:
+		at := strings.LastIndex(str, "@")
+		if at <= 0 {
+			return maskMiddle(str, 1, 0)
+		}
+		return string([]rune(str[:at])[0]) + "***" + str[at:]
+	case entity.DataMaskTypeRegex:
+		return r.regexp.ReplaceAllString(str, r.Replacement)
+	case entity.DataMaskTypeHash:
+		sum := sha256.Sum256([]byte(str))
+		return hex.EncodeToString(sum[:])
+	default:
+		return fullMask
+	}
+}
+
+// 保留前keepPrefix个及后keepSuffix个字符，其余字符使用*替换，长度不足时全部替换
+func maskMiddle(s string, keepPrefix, keepSuffix int) string {
+	runes := []rune(s)
+	if len(runes) <= keepPrefix+keepSuffix {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:keepPrefix]) + strings.Repeat("*", len(runes)-keepPrefix-keepSuffix) + string(runes[len(runes)-keepSuffix:])
+}
+
+// 获取查询语句涉及的所有表名及查询结果列信息
+func getSelectTablesAndColumns(stmt sqlparser.Statement) ([]string, *SelectColumns) {
+	tables := getTableNames(stmt)
+
+	sc := &SelectColumns{columns: make(map[string]string), derived: make(map[string][]string)}
+	sel, ok := stmt.(*sqlparser.Select)
+	// union、派生表、with等无法确定结果列来源，则所有结果列均视为引用了查询列表中的所有列
+	if !ok || hasDerivedTable(stmt) {
+		sc.others = getSelectExprColumns(stmt)
+		return tables, sc
+	}
+
+	for _, expr := range sel.SelectExprs {
+		aliasedExpr, ok := expr.(*sqlparser.AliasedExpr)
+		if !ok {
+			continue
+		}
+		if col, ok := aliasedExpr.Expr.(*sqlparser.ColName); ok {
+			name := col.Name.String()
+			if !aliasedExpr.As.IsEmpty() {
+				name = aliasedExpr.As.String()
+			}
+			sc.columns[strings.ToLower(name)] = col.Name.String()
+			continue
+		}
+
+		refs := getColumnNames(aliasedExpr.Expr)
+		if aliasedExpr.As.IsEmpty() {
+			// 未指定别名时结果列名由数据库决定，无法准确匹配
+			sc.others = append(sc.others, refs...)
+			continue
+		}
+		sc.derived[strings.ToLower(aliasedExpr.As.String())] = refs
+	}
+	return tables, sc
+}
+
+// 判断语句是否包含派生表或with子句
+func hasDerivedTable(stmt sqlparser.Statement) bool {
+	found := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node.(type) {
+		case *sqlparser.DerivedTable, *sqlparser.With:
+			found = true
+			return false, nil
+		}
+		return !found, nil
+	}, stmt)
+	return found
+}
+
+// 获取语句中所有查询列表（包括子查询）引用的列名
+func getSelectExprColumns(stmt sqlparser.Statement) []string {
+	columns := make([]string, 0)
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if sel, ok := node.(*sqlparser.Select); ok {
+			columns = append(columns, getColumnNames(sel.SelectExprs)...)
+		}
+		return true, nil
+	}, stmt)
+	return columns
+}
+
+// 获取节点中引用的所有列名
+func getColumnNames(node sqlparser.SQLNode) []string {
+	columns := make([]string, 0)
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if col, ok := node.(*sqlparser.ColName); ok {
+			columns = append(columns, col.Name.String())
+		}
+		return true, nil
+	}, node)
+	return columns
+}
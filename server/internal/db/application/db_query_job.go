@@ -0,0 +1,319 @@
+package application
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"mayfly-go/internal/db/application/dto"
+	"mayfly-go/internal/db/config"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/internal/db/domain/repository"
+	msgapp "mayfly-go/internal/msg/application"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	"mayfly-go/pkg/base"
+	pkgconfig "mayfly-go/pkg/config"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/scheduler"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kanzihuang/vitess/go/vt/sqlparser"
+)
+
+type DbQueryJob interface {
+	base.App[*entity.DbQueryJob]
+
+	// GetPageList 分页获取异步查询任务
+	GetPageList(condition *entity.DbQueryJobQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+
+	// Submit 提交异步查询任务，任务在后台执行并将查询结果导出至文件，执行结束后通过系统消息通知
+	Submit(ctx context.Context, param *dto.SubmitDbQueryJob) (*entity.DbQueryJob, error)
+
+	// Cancel 取消执行中的查询任务
+	Cancel(ctx context.Context, id uint64) error
+
+	// Delete 删除查询任务及其导出文件
+	Delete(ctx context.Context, id uint64) error
+
+	// OpenFile 打开查询任务的导出文件
+	OpenFile(ctx context.Context, id uint64) (*entity.DbQueryJob, io.ReadCloser, error)
+
+	// InitJob 重置因服务重启而中断的任务，并定时清理过期的导出文件
+	InitJob()
+}
+
+type dbQueryJobAppImpl struct {
+	base.AppImpl[*entity.DbQueryJob, repository.DbQueryJob]
+
+	dbDataMaskRuleApp  DbDataMaskRule  `inject:"DbDataMaskRuleApp"`
+	dbSqlReviewRuleApp DbSqlReviewRule `inject:"DbSqlReviewRuleApp"`
+	msgApp             msgapp.Msg      `inject:"MsgApp"`
+
+	cancelFuncs sync.Map // 执行中任务的取消函数 jobId -> context.CancelFunc
+}
+
+var _ (DbQueryJob) = (*dbQueryJobAppImpl)(nil)
+
+// 注入DbQueryJobRepo
+func (d *dbQueryJobAppImpl) InjectDbQueryJobRepo(repo repository.DbQueryJob) {
+	d.Repo = repo
+}
+
+func (d *dbQueryJobAppImpl) GetPageList(condition *entity.DbQueryJobQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
+	return d.GetRepo().GetPageList(condition, pageParam, toEntity, orderBy...)
+}
+
+func (d *dbQueryJobAppImpl) Submit(ctx context.Context, param *dto.SubmitDbQueryJob) (*entity.DbQueryJob, error) {
+	if _, err := newQueryResultWriter(param.Format, io.Discard); err != nil {
+		return nil, err
+	}
+
+	dbConn := param.DbConn
+	stmt, err := sqlparser.Parse(param.Sql)
+	if err != nil {
+		// 解析失败则仅允许select开头的查询语句（with语句可能包含数据修改，如pgsql的WITH d AS (DELETE ...)）
+		if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(param.Sql)), "select") {
+			return nil, errorx.NewBiz("异步查询任务仅支持查询语句")
+		}
+	} else if _, ok := stmt.(sqlparser.SelectStatement); !ok {
+		return nil, errorx.NewBiz("异步查询任务仅支持查询语句")
+	}
+
+	report, err := d.dbSqlReviewRuleApp.Review(ctx, dbConn, param.Sql, stmt)
+	if err != nil {
+		return nil, err
+	}
+	if report != nil && report.HasError() {
+		return nil, errorx.NewBiz("SQL审核未通过: %s", strings.Join(report.Msgs(entity.SqlReviewLevelError), "; "))
+	}
+
+	job := &entity.DbQueryJob{
+		DbId:     param.DbId,
+		Db:       param.Db,
+		Sql:      param.Sql,
+		Format:   param.Format,
+		Status:   entity.DbQueryJobStatusRunning,
+		FileName: fmt.Sprintf("%s-%s.%s.%s", dbConn.Info.Name, strings.ReplaceAll(param.Db, "/", "_"), time.Now().Format("20060102150405"), param.Format),
+		Remark:   param.Remark,
+	}
+	if err := d.Insert(ctx, job); err != nil {
+		return nil, err
+	}
+
+	// 任务执行不受请求上下文影响，仅可通过取消任务终止
+	jobCtx, cancel := context.WithCancel(contextx.WithLoginAccount(context.Background(), contextx.GetLoginAccount(ctx)))
+	d.cancelFuncs.Store(job.Id, cancel)
+	go d.run(jobCtx, job, dbConn, stmt)
+	return job, nil
+}
+
+func (d *dbQueryJobAppImpl) Cancel(ctx context.Context, id uint64) error {
+	job, err := d.getAccountJob(ctx, id)
+	if err != nil {
+		return err
+	}
+	if job.Status != entity.DbQueryJobStatusRunning {
+		return errorx.NewBiz("该任务未在执行")
+	}
+
+	if cancel, ok := d.cancelFuncs.Load(id); ok {
+		cancel.(context.CancelFunc)()
+		return nil
+	}
+	// 不存在取消函数说明任务已中断，直接修改状态即可
+	update := &entity.DbQueryJob{Status: entity.DbQueryJobStatusCanceled}
+	update.Id = id
+	return d.UpdateById(ctx, update)
+}
+
+func (d *dbQueryJobAppImpl) Delete(ctx context.Context, id uint64) error {
+	job, err := d.getAccountJob(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, ok := d.cancelFuncs.Load(id); ok {
+		return errorx.NewBiz("该任务正在执行中，请先取消任务")
+	}
+	return d.deleteJob(ctx, job)
+}
+
+func (d *dbQueryJobAppImpl) OpenFile(ctx context.Context, id uint64) (*entity.DbQueryJob, io.ReadCloser, error) {
+	job, err := d.getAccountJob(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != entity.DbQueryJobStatusSuccess {
+		return nil, nil, errorx.NewBiz("该任务未执行成功，无导出文件")
+	}
+	if job.ExpireTime != nil && job.ExpireTime.Before(time.Now()) {
+		return nil, nil, errorx.NewBiz("导出文件已过期")
+	}
+
+	file, err := os.Open(job.FilePath)
+	if err != nil {
+		return nil, nil, errorx.NewBiz("打开导出文件失败: %s", err.Error())
+	}
+	return job, file, nil
+}
+
+func (d *dbQueryJobAppImpl) InitJob() {
+	d.UpdateByCond(context.TODO(), &entity.DbQueryJob{Status: entity.DbQueryJobStatusFail, ErrMsg: "服务重启，任务已中断"}, &entity.DbQueryJob{Status: entity.DbQueryJobStatusRunning})
+
+	scheduler.AddFun("@every 30m", func() {
+		jobs, err := d.ListByCond(model.NewCond().Le("expire_time", time.Now()))
+		if err != nil {
+			logx.Errorf("获取过期的异步查询任务失败: %s", err.Error())
+			return
+		}
+		for _, job := range jobs {
+			if err := d.deleteJob(context.Background(), job); err != nil {
+				logx.Warnf("删除过期的异步查询任务[%d]失败: %s", job.Id, err.Error())
+			}
+		}
+	})
+}
+
+// 获取当前登录账号提交的任务
+func (d *dbQueryJobAppImpl) getAccountJob(ctx context.Context, id uint64) (*entity.DbQueryJob, error) {
+	job, err := d.GetById(id)
+	if err != nil {
+		return nil, errorx.NewBiz("该查询任务不存在")
+	}
+	if la := contextx.GetLoginAccount(ctx); la == nil || la.Id != job.CreatorId {
+		return nil, errorx.NewBiz("无权操作该查询任务")
+	}
+	return job, nil
+}
+
+func (d *dbQueryJobAppImpl) deleteJob(ctx context.Context, job *entity.DbQueryJob) error {
+	if err := d.DeleteById(ctx, job.Id); err != nil {
+		return err
+	}
+	removeJobFile(job.FilePath)
+	return nil
+}
+
+func (d *dbQueryJobAppImpl) run(ctx context.Context, job *entity.DbQueryJob, dbConn *dbi.DbConn, stmt sqlparser.Statement) {
+	defer func() {
+		if cancel, ok := d.cancelFuncs.LoadAndDelete(job.Id); ok {
+			cancel.(context.CancelFunc)()
+		}
+	}()
+
+	dbmsConf := config.GetDbms()
+	job.FilePath = filepath.Join(dbmsConf.QueryJobPath, fmt.Sprintf("%d.%s", job.Id, job.Format))
+	rowCount, err := d.export(ctx, job, dbConn, stmt)
+
+	now := time.Now()
+	update := &entity.DbQueryJob{RowCount: rowCount, FilePath: job.FilePath, EndTime: &now}
+	update.Id = job.Id
+	switch {
+	case err == nil:
+		update.Status = entity.DbQueryJobStatusSuccess
+		if fileInfo, err := os.Stat(job.FilePath); err == nil {
+			update.FileSize = fileInfo.Size()
+		}
+		expireTime := now.Add(time.Duration(dbmsConf.QueryJobExpireHours) * time.Hour)
+		update.ExpireTime = &expireTime
+	case ctx.Err() != nil:
+		update.Status = entity.DbQueryJobStatusCanceled
+		removeJobFile(job.FilePath)
+	default:
+		update.Status = entity.DbQueryJobStatusFail
+		update.ErrMsg = err.Error()
+		removeJobFile(job.FilePath)
+	}
+
+	// 任务取消后ctx已失效，需使用不可取消的ctx更新任务信息
+	updateCtx := context.WithoutCancel(ctx)
+	if err := d.UpdateById(updateCtx, update); err != nil {
+		logx.Errorf("更新异步查询任务[%d]执行结果失败: %s", job.Id, err.Error())
+	}
+
+	la := contextx.GetLoginAccount(ctx)
+	if la == nil {
+		return
+	}
+	switch update.Status {
+	case entity.DbQueryJobStatusSuccess:
+		downloadUrl := fmt.Sprintf("%s/api/dbs/query-jobs/%d/download", pkgconfig.Conf.Server.ContextPath, job.Id)
+		d.msgApp.CreateAndSend(la, msgdto.SuccessSysMsg("查询任务执行成功",
+			fmt.Sprintf("[%s]查询结果已导出%d行至[%s]，下载地址: %s，有效期至%s", dbConn.Info.GetLogDesc(), rowCount, job.FileName, downloadUrl, update.ExpireTime.Format(time.DateTime))))
+	case entity.DbQueryJobStatusFail:
+		d.msgApp.CreateAndSend(la, msgdto.ErrSysMsg("查询任务执行失败", fmt.Sprintf("[%s]查询任务[%d]执行失败: %s", dbConn.Info.GetLogDesc(), job.Id, update.ErrMsg)))
+	}
+}
+
+// 流式遍历查询结果并写入导出文件，返回导出行数
+func (d *dbQueryJobAppImpl) export(ctx context.Context, job *entity.DbQueryJob, dbConn *dbi.DbConn, stmt sqlparser.Statement) (int64, error) {
+	masker, err := d.dbDataMaskRuleApp.GetDataMasker(ctx, dbConn)
+	if err != nil {
+		return 0, err
+	}
+	// 无法解析查询语句时，存在脱敏规则则全部脱敏
+	selectColumns := unparsedSelectColumns
+	if masker != nil && stmt != nil {
+		var tables []string
+		tables, selectColumns = getSelectTablesAndColumns(stmt)
+		masker = masker.ForTables(tables...)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(job.FilePath), 0755); err != nil {
+		return 0, err
+	}
+	file, err := os.Create(job.FilePath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	bufWriter := bufio.NewWriter(file)
+	writer, err := newQueryResultWriter(job.Format, bufWriter)
+	if err != nil {
+		return 0, err
+	}
+
+	var rowCount int64
+	headerWritten := false
+	columns, err := dbConn.WalkQueryRows(ctx, job.Sql, func(row map[string]any, columns []*dbi.QueryColumn) error {
+		if !headerWritten {
+			if err := writer.WriteHeader(columns); err != nil {
+				return err
+			}
+			headerWritten = true
+		}
+		masker.MaskRow(row, selectColumns)
+		rowCount++
+		return writer.WriteRow(row)
+	})
+	if err != nil {
+		return rowCount, err
+	}
+	// 无查询结果时仍需写入列信息
+	if !headerWritten {
+		if err := writer.WriteHeader(columns); err != nil {
+			return rowCount, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return rowCount, err
+	}
+	return rowCount, bufWriter.Flush()
+}
+
+func removeJobFile(filePath string) {
+	if filePath == "" {
+		return
+	}
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		logx.Warnf("删除异步查询任务导出文件[%s]失败: %s", filePath, err.Error())
+	}
+}
@@ -0,0 +1,222 @@
+package application
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/anyx"
+	"strings"
+)
+
+// 查询结果文件写入器，按照列顺序流式写入行数据
+type queryResultWriter interface {
+	// WriteHeader 写入列信息，需在写入行数据前调用且仅调用一次
+	WriteHeader(columns []*dbi.QueryColumn) error
+
+	WriteRow(row map[string]any) error
+
+	Close() error
+}
+
+func newQueryResultWriter(format entity.DbQueryJobFormat, w io.Writer) (queryResultWriter, error) {
+	switch format {
+	case entity.DbQueryJobFormatCsv:
+		return &csvQueryResultWriter{w: w}, nil
+	case entity.DbQueryJobFormatJson:
+		return &jsonQueryResultWriter{w: w}, nil
+	case entity.DbQueryJobFormatXlsx:
+		return &xlsxQueryResultWriter{zw: zip.NewWriter(w)}, nil
+	}
+	return nil, errorx.NewBiz("不支持的导出格式: %s", format)
+}
+
+/******************* csv *******************/
+
+type csvQueryResultWriter struct {
+	w       io.Writer
+	writer  *csv.Writer
+	columns []*dbi.QueryColumn
+}
+
+func (c *csvQueryResultWriter) WriteHeader(columns []*dbi.QueryColumn) error {
+	c.columns = columns
+	// 写入utf8 bom，避免excel打开中文乱码
+	if _, err := c.w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+	c.writer = csv.NewWriter(c.w)
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	return c.writer.Write(header)
+}
+
+func (c *csvQueryResultWriter) WriteRow(row map[string]any) error {
+	record := make([]string, len(c.columns))
+	for i, col := range c.columns {
+		record[i] = anyx.ToString(row[col.Name])
+	}
+	return c.writer.Write(record)
+}
+
+func (c *csvQueryResultWriter) Close() error {
+	if c.writer == nil {
+		return nil
+	}
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+/******************* json *******************/
+
+// 以对象数组形式写入，对象属性按照查询列顺序排列
+type jsonQueryResultWriter struct {
+	w       io.Writer
+	columns []*dbi.QueryColumn
+	rows    int
+}
+
+func (j *jsonQueryResultWriter) WriteHeader(columns []*dbi.QueryColumn) error {
+	j.columns = columns
+	_, err := io.WriteString(j.w, "[")
+	return err
+}
+
+func (j *jsonQueryResultWriter) WriteRow(row map[string]any) error {
+	var sb strings.Builder
+	if j.rows > 0 {
+		sb.WriteString(",")
+	}
+	sb.WriteString("\n{")
+	for i, col := range j.columns {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		key, _ := json.Marshal(col.Name)
+		val, err := json.Marshal(row[col.Name])
+		if err != nil {
+			return err
+		}
+		sb.Write(key)
+		sb.WriteString(":")
+		sb.Write(val)
+	}
+	sb.WriteString("}")
+	j.rows++
+	_, err := io.WriteString(j.w, sb.String())
+	return err
+}
+
+func (j *jsonQueryResultWriter) Close() error {
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
+
+/******************* xlsx *******************/
+
+const (
+	xlsxMaxRows       = 1048576 // xlsx单个工作表最大行数（含表头）
+	xlsxMaxCellLength = 32767   // xlsx单元格最大字符数
+)
+
+var xlsxStaticParts = [][2]string{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+// 流式写入仅包含单个工作表的xlsx文件，单元格使用内联字符串，避免在内存中维护共享字符串表
+type xlsxQueryResultWriter struct {
+	zw      *zip.Writer
+	sheet   io.Writer
+	columns []*dbi.QueryColumn
+	rows    int
+}
+
+func (x *xlsxQueryResultWriter) WriteHeader(columns []*dbi.QueryColumn) error {
+	x.columns = columns
+	for _, part := range xlsxStaticParts {
+		w, err := x.zw.Create(part[0])
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, part[1]); err != nil {
+			return err
+		}
+	}
+
+	// 工作表需最后创建，zip创建新文件后之前的文件将无法继续写入
+	sheet, err := x.zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	x.sheet = sheet
+	if _, err := io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return err
+	}
+
+	header := make(map[string]any, len(columns))
+	for _, col := range columns {
+		header[col.Name] = col.Name
+	}
+	return x.WriteRow(header)
+}
+
+func (x *xlsxQueryResultWriter) WriteRow(row map[string]any) error {
+	if x.rows >= xlsxMaxRows {
+		return errorx.NewBiz("xlsx最多支持导出%d行数据，请使用csv或json格式导出", xlsxMaxRows-1)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<row>")
+	for _, col := range x.columns {
+		writeXlsxCell(&sb, row[col.Name])
+	}
+	sb.WriteString("</row>")
+	x.rows++
+	_, err := io.WriteString(x.sheet, sb.String())
+	return err
+}
+
+func (x *xlsxQueryResultWriter) Close() error {
+	if x.sheet != nil {
+		if _, err := io.WriteString(x.sheet, "</sheetData></worksheet>"); err != nil {
+			return errors.Join(err, x.zw.Close())
+		}
+	}
+	return x.zw.Close()
+}
+
+func writeXlsxCell(sb *strings.Builder, val any) {
+	switch val.(type) {
+	case nil:
+		sb.WriteString("<c/>")
+		return
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		sb.WriteString("<c><v>")
+		sb.WriteString(anyx.ToString(val))
+		sb.WriteString("</v></c>")
+		return
+	}
+
+	str := anyx.ToString(val)
+	if runes := []rune(str); len(runes) > xlsxMaxCellLength {
+		str = string(runes[:xlsxMaxCellLength])
+	}
+	sb.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+	xml.EscapeText(sb, []byte(str))
+	sb.WriteString("</t></is></c>")
+}
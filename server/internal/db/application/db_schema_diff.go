@@ -0,0 +1,292 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"mayfly-go/internal/db/application/dto"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/collx"
+	"sort"
+	"strings"
+)
+
+type DbSchemaDiff interface {
+	// Diff 对比源库与目标库的表结构差异，并生成将目标库结构变更为源库结构的sql
+	Diff(ctx context.Context, param *dto.DbSchemaDiff) (*dto.DbSchemaDiffRes, error)
+}
+
+type dbSchemaDiffAppImpl struct {
+}
+
+var _ (DbSchemaDiff) = (*dbSchemaDiffAppImpl)(nil)
+
+// 单表的结构信息
+type tableSchema struct {
+	table   dbi.Table
+	columns []dbi.Column
+	indexs  []dbi.Index
+}
+
+func (app *dbSchemaDiffAppImpl) Diff(ctx context.Context, param *dto.DbSchemaDiff) (*dto.DbSchemaDiffRes, error) {
+	srcConn, targetConn := param.SrcConn, param.TargetConn
+	// 不同类型数据库，需将两边的列都转为目标库方言的列后再进行比较
+	sameDbType := srcConn.Info.Type == targetConn.Info.Type
+
+	srcSchemas, err := app.getTableSchemas(srcConn, param.TableNames, targetConn, sameDbType)
+	if err != nil {
+		return nil, errorx.NewBiz("获取源库表结构失败: %s", err.Error())
+	}
+	targetSchemas, err := app.getTableSchemas(targetConn, param.TableNames, targetConn, sameDbType)
+	if err != nil {
+		return nil, errorx.NewBiz("获取目标库表结构失败: %s", err.Error())
+	}
+
+	targetMeta := targetConn.GetMetaData()
+	res := &dto.DbSchemaDiffRes{
+		AddTables:    make([]string, 0),
+		DropTables:   make([]string, 0),
+		ChangeTables: make([]*dto.DbTableDiffInfo, 0),
+	}
+
+	createTableSqls := make([]string, 0)
+	alterTableSqls := make([]string, 0)
+	addIndexSqls := make([]string, 0)
+	dropTableSqls := make([]string, 0)
+
+	for _, key := range sortedKeys(srcSchemas) {
+		srcSchema := srcSchemas[key]
+		targetSchema := targetSchemas[key]
+
+		// 目标库不存在该表，则新建表及索引
+		if targetSchema == nil {
+			res.AddTables = append(res.AddTables, srcSchema.table.TableName)
+			createTableSqls = append(createTableSqls, targetMeta.GenerateTableDDL(srcSchema.columns, srcSchema.table, false)...)
+			if indexs := filterIndexs(srcSchema.indexs); len(indexs) > 0 {
+				createTableSqls = append(createTableSqls, targetMeta.GenerateIndexDDL(indexs, srcSchema.table)...)
+			}
+			continue
+		}
+
+		// 变更语句使用目标库中实际的表名
+		tableInfo := targetSchema.table
+		tableDiff, alterTable, addIndexs := app.diffTable(srcSchema, targetSchema, sameDbType, param.AllowDrop)
+		if tableDiff == nil {
+			continue
+		}
+		res.ChangeTables = append(res.ChangeTables, tableDiff)
+		alterTableSqls = append(alterTableSqls, targetMeta.GenerateAlterTableDDL(*alterTable)...)
+		if len(addIndexs) > 0 {
+			addIndexSqls = append(addIndexSqls, targetMeta.GenerateIndexDDL(addIndexs, tableInfo)...)
+		}
+	}
+
+	for _, key := range sortedKeys(targetSchemas) {
+		if srcSchemas[key] != nil {
+			continue
+		}
+		tableName := targetSchemas[key].table.TableName
+		res.DropTables = append(res.DropTables, tableName)
+		if param.AllowDrop {
+			dropTableSqls = append(dropTableSqls, fmt.Sprintf("DROP TABLE %s", targetMeta.QuoteIdentifier(tableName)))
+		}
+	}
+
+	// 执行顺序：新建表 -> 修改表（删除索引、新增、修改、删除列） -> 新增索引 -> 删除表
+	sqls := make([]string, 0)
+	sqls = append(sqls, createTableSqls...)
+	sqls = append(sqls, alterTableSqls...)
+	sqls = append(sqls, addIndexSqls...)
+	sqls = append(sqls, dropTableSqls...)
+	res.Sqls = sqls
+	return res, nil
+}
+
+// 获取库中指定表的结构信息，key为小写表名
+func (app *dbSchemaDiffAppImpl) getTableSchemas(conn *dbi.DbConn, tableNames []string, targetConn *dbi.DbConn, sameDbType bool) (map[string]*tableSchema, error) {
+	meta := conn.GetMetaData()
+	tables, err := meta.GetTables()
+	if err != nil {
+		return nil, err
+	}
+
+	// 指定表名时，仅对比指定的表
+	if len(tableNames) > 0 {
+		lowerTableNames := collx.ArrayMap(tableNames, strings.ToLower)
+		tables = collx.ArrayFilter(tables, func(table dbi.Table) bool {
+			return collx.ArrayContains(lowerTableNames, strings.ToLower(table.TableName))
+		})
+	}
+
+	schemas := make(map[string]*tableSchema)
+	if len(tables) == 0 {
+		return schemas, nil
+	}
+
+	for _, table := range tables {
+		schemas[strings.ToLower(table.TableName)] = &tableSchema{table: table}
+	}
+
+	columns, err := meta.GetColumns(collx.ArrayMap(tables, func(table dbi.Table) string { return table.TableName })...)
+	if err != nil {
+		return nil, err
+	}
+
+	srcColumnHelper := meta.GetColumnHelper()
+	targetColumnHelper := targetConn.GetMetaData().GetColumnHelper()
+	for _, column := range columns {
+		schema := schemas[strings.ToLower(column.TableName)]
+		if schema == nil {
+			continue
+		}
+		if !sameDbType {
+			// 转为公共列后再转为目标库的列
+			srcColumnHelper.ToCommonColumn(&column)
+			targetColumnHelper.ToColumn(&column)
+		}
+		schema.columns = append(schema.columns, column)
+	}
+
+	for _, schema := range schemas {
+		indexs, err := meta.GetTableIndex(schema.table.TableName)
+		if err != nil {
+			return nil, err
+		}
+		schema.indexs = indexs
+	}
+	return schemas, nil
+}
+
+// 对比表结构差异，无差异则返回nil
+func (app *dbSchemaDiffAppImpl) diffTable(srcSchema, targetSchema *tableSchema, sameDbType bool, allowDrop bool) (*dto.DbTableDiffInfo, *dbi.AlterTable, []dbi.Index) {
+	tableDiff := &dto.DbTableDiffInfo{
+		TableName:     targetSchema.table.TableName,
+		AddColumns:    make([]string, 0),
+		DropColumns:   make([]string, 0),
+		ModifyColumns: make([]*dto.DbColumnDiffInfo, 0),
+		AddIndexs:     make([]string, 0),
+		DropIndexs:    make([]string, 0),
+		ModifyIndexs:  make([]string, 0),
+	}
+	alterTable := &dbi.AlterTable{Table: targetSchema.table, Columns: targetSchema.columns, Indexs: filterIndexs(targetSchema.indexs)}
+	addIndexs := make([]dbi.Index, 0)
+
+	// 列对比
+	targetColumns := collx.ArrayToMap(targetSchema.columns, func(column dbi.Column) string {
+		return strings.ToLower(column.ColumnName)
+	})
+	srcColumnNames := make(map[string]bool)
+	for _, srcColumn := range srcSchema.columns {
+		key := strings.ToLower(srcColumn.ColumnName)
+		srcColumnNames[key] = true
+
+		targetColumn, ok := targetColumns[key]
+		if !ok {
+			tableDiff.AddColumns = append(tableDiff.AddColumns, srcColumn.ColumnName)
+			srcColumn.TableName = targetSchema.table.TableName
+			alterTable.AddColumns = append(alterTable.AddColumns, srcColumn)
+			continue
+		}
+
+		if columnEqual(srcColumn, targetColumn, sameDbType) {
+			continue
+		}
+		tableDiff.ModifyColumns = append(tableDiff.ModifyColumns, &dto.DbColumnDiffInfo{
+			ColumnName: targetColumn.ColumnName,
+			Src:        columnDesc(srcColumn),
+			Target:     columnDesc(targetColumn),
+		})
+		newColumn := srcColumn
+		newColumn.TableName, newColumn.ColumnName = targetColumn.TableName, targetColumn.ColumnName
+		if !sameDbType {
+			// 跨库时默认值不具备可比性，保留目标库原有默认值
+			newColumn.ColumnDefault = targetColumn.ColumnDefault
+		}
+		alterTable.ModifyColumns = append(alterTable.ModifyColumns, dbi.ModifyColumn{OldColumn: targetColumn, NewColumn: newColumn})
+	}
+	for _, targetColumn := range targetSchema.columns {
+		if srcColumnNames[strings.ToLower(targetColumn.ColumnName)] {
+			continue
+		}
+		tableDiff.DropColumns = append(tableDiff.DropColumns, targetColumn.ColumnName)
+		if allowDrop {
+			alterTable.DropColumns = append(alterTable.DropColumns, targetColumn)
+		}
+	}
+
+	// 索引对比，以索引名进行匹配，主键索引不参与对比
+	srcIndexs := filterIndexs(srcSchema.indexs)
+	targetIndexList := filterIndexs(targetSchema.indexs)
+	targetIndexs := collx.ArrayToMap(targetIndexList, func(index dbi.Index) string {
+		return strings.ToLower(index.IndexName)
+	})
+	srcIndexNames := make(map[string]bool)
+	for _, srcIndex := range srcIndexs {
+		key := strings.ToLower(srcIndex.IndexName)
+		srcIndexNames[key] = true
+
+		targetIndex, ok := targetIndexs[key]
+		if !ok {
+			tableDiff.AddIndexs = append(tableDiff.AddIndexs, srcIndex.IndexName)
+			addIndexs = append(addIndexs, srcIndex)
+			continue
+		}
+		if strings.EqualFold(srcIndex.ColumnName, targetIndex.ColumnName) && srcIndex.IsUnique == targetIndex.IsUnique {
+			continue
+		}
+		// 索引变更则先删除后重建
+		tableDiff.ModifyIndexs = append(tableDiff.ModifyIndexs, targetIndex.IndexName)
+		alterTable.DropIndexs = append(alterTable.DropIndexs, targetIndex)
+		srcIndex.IndexName = targetIndex.IndexName
+		addIndexs = append(addIndexs, srcIndex)
+	}
+	for _, targetIndex := range targetIndexList {
+		if srcIndexNames[strings.ToLower(targetIndex.IndexName)] {
+			continue
+		}
+		tableDiff.DropIndexs = append(tableDiff.DropIndexs, targetIndex.IndexName)
+		alterTable.DropIndexs = append(alterTable.DropIndexs, targetIndex)
+	}
+
+	if len(tableDiff.AddColumns) == 0 && len(tableDiff.DropColumns) == 0 && len(tableDiff.ModifyColumns) == 0 &&
+		len(tableDiff.AddIndexs) == 0 && len(tableDiff.DropIndexs) == 0 && len(tableDiff.ModifyIndexs) == 0 {
+		return nil, nil, nil
+	}
+	return tableDiff, alterTable, addIndexs
+}
+
+// 判断两列定义是否一致，不同类型数据库间的默认值不做比较
+func columnEqual(src, target dbi.Column, sameDbType bool) bool {
+	if !strings.EqualFold(src.GetColumnType(), target.GetColumnType()) || src.Nullable != target.Nullable || src.ColumnComment != target.ColumnComment {
+		return false
+	}
+	return !sameDbType || src.ColumnDefault == target.ColumnDefault
+}
+
+// 列定义描述，如：varchar(32) NOT NULL DEFAULT 'a' COMMENT '名称'
+func columnDesc(column dbi.Column) string {
+	desc := column.GetColumnType()
+	if !column.Nullable {
+		desc += " NOT NULL"
+	}
+	if column.ColumnDefault != "" {
+		desc += fmt.Sprintf(" DEFAULT %s", column.ColumnDefault)
+	}
+	if column.ColumnComment != "" {
+		desc += fmt.Sprintf(" COMMENT '%s'", column.ColumnComment)
+	}
+	return desc
+}
+
+// 过滤主键索引
+func filterIndexs(indexs []dbi.Index) []dbi.Index {
+	return collx.ArrayFilter(indexs, func(index dbi.Index) bool {
+		return !index.IsPrimaryKey
+	})
+}
+
+func sortedKeys(schemas map[string]*tableSchema) []string {
+	keys := collx.MapKeys(schemas)
+	sort.Strings(keys)
+	return keys
+}
@@ -14,6 +14,7 @@ import (
 	"mayfly-go/pkg/errorx"
 	"mayfly-go/pkg/logx"
 	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/collx"
 	"mayfly-go/pkg/utils/jsonx"
 	"mayfly-go/pkg/utils/stringx"
 	"strconv"
@@ -28,15 +29,19 @@ type DbSqlExecReq struct {
 	Sql    string
 	Remark string
 	DbConn *dbi.DbConn
+
+	reviewReport *SqlReviewReport // sql审核报告
 }
 
 type DbSqlExecRes struct {
-	Columns []*dbi.QueryColumn
-	Res     []map[string]any
+	Columns  []*dbi.QueryColumn
+	Res      []map[string]any
+	Warnings []string // sql审核警告信息
 }
 
 // 合并执行结果，主要用于执行多条sql使用
 func (d *DbSqlExecRes) Merge(execRes *DbSqlExecRes) {
+	d.Warnings = append(d.Warnings, execRes.Warnings...)
 	canMerge := len(d.Columns) == len(execRes.Columns)
 	if !canMerge {
 		return
@@ -61,11 +66,24 @@ type DbSqlExec interface {
 
 	// 分页获取
 	GetPageList(condition *entity.DbSqlExecQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+
+	GetById(id uint64) (*entity.DbSqlExec, error)
+
+	// GenRollbackSql 根据执行记录保存的旧值或插入的主键值生成回滚sql，用于预览，旧值将按照脱敏规则进行脱敏
+	GenRollbackSql(ctx context.Context, dbSqlExec *entity.DbSqlExec, dbConn *dbi.DbConn) ([]string, error)
+
+	// ReviewSql 根据库关联的审核规则审核sql，存在错误级别的审核结果则返回错误
+	ReviewSql(ctx context.Context, dbConn *dbi.DbConn, sql string) error
+
+	// Rollback 在同一事务中回滚指定的sql执行记录，每条记录只能回滚一次。若库关联了审批流程，则所有回滚sql作为一个流程审批通过后才执行
+	Rollback(ctx context.Context, dbSqlExec *entity.DbSqlExec, dbConn *dbi.DbConn) (*DbSqlExecRes, error)
 }
 
 type dbSqlExecAppImpl struct {
-	dbApp         Db                   `inject:"DbApp"`
-	dbSqlExecRepo repository.DbSqlExec `inject:"DbSqlExecRepo"`
+	dbApp              Db                   `inject:"DbApp"`
+	dbSqlExecRepo      repository.DbSqlExec `inject:"DbSqlExecRepo"`
+	dbDataMaskRuleApp  DbDataMaskRule       `inject:"DbDataMaskRuleApp"`
+	dbSqlReviewRuleApp DbSqlReviewRule      `inject:"DbSqlReviewRuleApp"`
 
 	flowProcinstApp flowapp.Procinst `inject:"ProcinstApp"`
 	flowProcdefApp  flowapp.Procdef  `inject:"ProcdefApp"`
@@ -90,6 +108,10 @@ func (d *dbSqlExecAppImpl) Exec(ctx context.Context, execSqlReq *DbSqlExecReq) (
 	isSelect := false
 
 	stmt, err := sqlparser.Parse(sql)
+	// 解析失败时stmt为nil，审核规则将根据sql文本进行判断
+	if reviewErr := d.reviewSql(ctx, execSqlReq, stmt); reviewErr != nil {
+		return nil, reviewErr
+	}
 	if err != nil {
 		// 就算解析失败也执行sql，让数据库来判断错误。如果是查询sql则简单判断是否有limit分页参数信息（兼容pgsql）
 		// logx.Warnf("sqlparse解析sql[%s]失败: %s", sql, err.Error())
@@ -116,6 +138,10 @@ func (d *dbSqlExecAppImpl) Exec(ctx context.Context, execSqlReq *DbSqlExecReq) (
 		var execErr error
 		if isSelect || strings.HasPrefix(lowerSql, "show") {
 			execRes, execErr = d.doRead(ctx, execSqlReq)
+			if execErr == nil {
+				// 无法解析查询语句，存在脱敏规则时全部脱敏
+				execErr = d.maskQueryRes(ctx, execSqlReq, nil, execRes)
+			}
 		} else {
 			execRes, execErr = d.doExec(ctx, execSqlReq, dbSqlExecRecord)
 		}
@@ -124,7 +150,7 @@ func (d *dbSqlExecAppImpl) Exec(ctx context.Context, execSqlReq *DbSqlExecReq) (
 		if execErr != nil {
 			return nil, execErr
 		}
-		return execRes, nil
+		return execSqlReq.fillReviewWarnings(execRes), nil
 	}
 
 	switch stmt := stmt.(type) {
@@ -149,33 +175,44 @@ func (d *dbSqlExecAppImpl) Exec(ctx context.Context, execSqlReq *DbSqlExecReq) (
 	default:
 		execRes, err = d.doExec(ctx, execSqlReq, dbSqlExecRecord)
 	}
+	if isSelect && err == nil {
+		err = d.maskQueryRes(ctx, execSqlReq, stmt, execRes)
+	}
 
 	d.saveSqlExecLog(isSelect, dbSqlExecRecord)
 	if err != nil {
 		return nil, err
 	}
-	return execRes, nil
+	return execSqlReq.fillReviewWarnings(execRes), nil
 }
 
-func (d *dbSqlExecAppImpl) FlowBizHandle(ctx context.Context, bizHandleParam *flowapp.BizHandleParam) error {
+func (d *dbSqlExecAppImpl) FlowBizHandle(ctx context.Context, bizHandleParam *flowapp.BizHandleParam) (any, error) {
 	bizKey := bizHandleParam.BizKey
 	procinstStatus := bizHandleParam.ProcinstStatus
 
 	logx.Debugf("DbSqlExec FlowBizHandle -> bizKey: %s, procinstStatus: %s", bizKey, flowentity.ProcinstStatusEnum.GetDesc(procinstStatus))
 	// 流程挂起不处理
 	if procinstStatus == flowentity.ProcinstStatusSuspended {
-		return nil
+		return nil, nil
 	}
 	dbSqlExec := &entity.DbSqlExec{FlowBizKey: bizKey}
 	if err := d.dbSqlExecRepo.GetByCond(dbSqlExec); err != nil {
 		logx.Errorf("flow-[%s]关联的sql执行信息不存在", bizKey)
-		return nil
+		return nil, nil
 	}
 
 	if procinstStatus != flowentity.ProcinstStatusCompleted {
 		dbSqlExec.Status = entity.DbSqlExecStatusNo
 		dbSqlExec.Res = fmt.Sprintf("流程%s", flowentity.ProcinstStatusEnum.GetDesc(procinstStatus))
-		return d.dbSqlExecRepo.UpdateById(ctx, dbSqlExec)
+		if dbSqlExec.Type == entity.DbSqlExecTypeRollback {
+			d.finishRollback(ctx, dbSqlExec, errorx.NewBiz(dbSqlExec.Res))
+			return nil, nil
+		}
+		return nil, d.dbSqlExecRepo.UpdateById(ctx, dbSqlExec)
+	}
+
+	if dbSqlExec.Type == entity.DbSqlExecTypeRollback {
+		return d.flowRollback(ctx, dbSqlExec)
 	}
 
 	dbSqlExec.Status = entity.DbSqlExecStatusFail
@@ -183,19 +220,51 @@ func (d *dbSqlExecAppImpl) FlowBizHandle(ctx context.Context, bizHandleParam *fl
 	if err != nil {
 		dbSqlExec.Res = err.Error()
 		d.dbSqlExecRepo.UpdateById(ctx, dbSqlExec)
-		return err
+		return nil, err
+	}
+
+	// 审批期间变更窗口等条件可能已变化，执行前需重新审核
+	if err := d.ReviewSql(ctx, dbConn, dbSqlExec.Sql); err != nil {
+		dbSqlExec.Res = err.Error()
+		d.dbSqlExecRepo.UpdateById(ctx, dbSqlExec)
+		return nil, err
 	}
 
-	rowsAffected, err := dbConn.ExecContext(ctx, dbSqlExec.Sql)
+	res, err := dbConn.ExecResultContext(ctx, dbSqlExec.Sql)
+	var rowsAffected int64
+	if err == nil {
+		rowsAffected, err = res.RowsAffected()
+	}
 	if err != nil {
 		dbSqlExec.Res = err.Error()
 		d.dbSqlExecRepo.UpdateById(ctx, dbSqlExec)
-		return err
+		return nil, err
 	}
+	fillInsertKey(dbConn, dbSqlExec, res, rowsAffected)
 
 	dbSqlExec.Status = entity.DbSqlExecStatusSuccess
 	dbSqlExec.Res = fmt.Sprintf("执行成功,影响条数: %d", rowsAffected)
-	return d.dbSqlExecRepo.UpdateById(ctx, dbSqlExec)
+	return dbSqlExec.Res, d.dbSqlExecRepo.UpdateById(ctx, dbSqlExec)
+}
+
+// 审批通过后在事务中执行回滚记录中的所有回滚sql
+func (d *dbSqlExecAppImpl) flowRollback(ctx context.Context, rollbackRecord *entity.DbSqlExec) (any, error) {
+	dbConn, err := d.dbApp.GetDbConn(rollbackRecord.DbId, rollbackRecord.Db)
+	if err != nil {
+		d.finishRollback(ctx, rollbackRecord, err)
+		return nil, err
+	}
+	sqls, err := splitRollbackSqls(dbConn, rollbackRecord.Sql)
+	if err != nil {
+		d.finishRollback(ctx, rollbackRecord, err)
+		return nil, err
+	}
+	rowsAffected, err := execRollbackSqls(ctx, dbConn, sqls)
+	d.finishRollback(ctx, rollbackRecord, err, rowsAffected)
+	if err != nil {
+		return nil, err
+	}
+	return rollbackRecord.Res, nil
 }
 
 func (d *dbSqlExecAppImpl) DeleteBy(ctx context.Context, condition *entity.DbSqlExec) error {
@@ -206,6 +275,10 @@ func (d *dbSqlExecAppImpl) GetPageList(condition *entity.DbSqlExecQuery, pagePar
 	return d.dbSqlExecRepo.GetPageList(condition, pageParam, toEntity, orderBy...)
 }
 
+func (d *dbSqlExecAppImpl) GetById(id uint64) (*entity.DbSqlExec, error) {
+	return d.dbSqlExecRepo.GetById(id)
+}
+
 // 保存sql执行记录，如果是查询类则根据系统配置判断是否保存
 func (d *dbSqlExecAppImpl) saveSqlExecLog(isQuery bool, dbSqlExecRecord *entity.DbSqlExec) {
 	if !isQuery {
@@ -262,6 +335,53 @@ func (d *dbSqlExecAppImpl) doRead(ctx context.Context, execSqlReq *DbSqlExecReq)
 	}, nil
 }
 
+// 根据库关联的审核规则审核sql，存在错误级别的审核结果则不允许执行
+func (d *dbSqlExecAppImpl) reviewSql(ctx context.Context, execSqlReq *DbSqlExecReq, stmt sqlparser.Statement) error {
+	report, err := d.dbSqlReviewRuleApp.Review(ctx, execSqlReq.DbConn, execSqlReq.Sql, stmt)
+	if err != nil {
+		return err
+	}
+	execSqlReq.reviewReport = report
+	if report != nil && report.HasError() {
+		return errorx.NewBiz("SQL审核未通过: %s", strings.Join(report.Msgs(entity.SqlReviewLevelError), "; "))
+	}
+	return nil
+}
+
+func (d *dbSqlExecAppImpl) ReviewSql(ctx context.Context, dbConn *dbi.DbConn, sql string) error {
+	// 解析失败时stmt为nil，审核规则将根据sql文本进行判断
+	stmt, _ := sqlparser.Parse(sql)
+	return d.reviewSql(ctx, &DbSqlExecReq{DbConn: dbConn, Sql: sql}, stmt)
+}
+
+// 将sql审核警告信息填充至执行结果
+func (d *DbSqlExecReq) fillReviewWarnings(execRes *DbSqlExecRes) *DbSqlExecRes {
+	if execRes == nil || d.reviewReport == nil {
+		return execRes
+	}
+	execRes.Warnings = append(execRes.Warnings, d.reviewReport.Msgs(entity.SqlReviewLevelWarn)...)
+	return execRes
+}
+
+// 根据脱敏规则对查询结果进行脱敏，stmt为nil则表示无法解析查询语句
+func (d *dbSqlExecAppImpl) maskQueryRes(ctx context.Context, execSqlReq *DbSqlExecReq, stmt sqlparser.Statement, execRes *DbSqlExecRes) error {
+	if execRes == nil || len(execRes.Res) == 0 {
+		return nil
+	}
+	masker, err := d.dbDataMaskRuleApp.GetDataMasker(ctx, execSqlReq.DbConn)
+	if err != nil || masker == nil {
+		return err
+	}
+
+	var tables []string
+	selectColumns := unparsedSelectColumns
+	if stmt != nil {
+		tables, selectColumns = getSelectTablesAndColumns(stmt)
+	}
+	masker.ForTables(tables...).MaskRows(execRes.Res, selectColumns)
+	return nil
+}
+
 func (d *dbSqlExecAppImpl) doUpdate(ctx context.Context, update *sqlparser.Update, execSqlReq *DbSqlExecReq, dbSqlExec *entity.DbSqlExec) (*DbSqlExecRes, error) {
 	dbConn := execSqlReq.DbConn
 
@@ -293,7 +413,7 @@ func (d *dbSqlExecAppImpl) doUpdate(ctx context.Context, update *sqlparser.Updat
 	selectSql := fmt.Sprintf("SELECT %s FROM %s %s", updateColumnsAndPrimaryKey, tableStr, where)
 
 	// WalkQuery查出最多200条数据
-	maxRec := 200
+	maxRec := maxOldValueRows
 	nowRec := 0
 	res := make([]map[string]any, 0)
 	_, err = dbConn.WalkQueryRows(ctx, selectSql, func(row map[string]any, columns []*dbi.QueryColumn) error {
@@ -328,7 +448,7 @@ func (d *dbSqlExecAppImpl) doDelete(ctx context.Context, delete *sqlparser.Delet
 	}
 
 	// 查询删除数据
-	selectSql := fmt.Sprintf("SELECT * FROM %s %s LIMIT 200", tableStr, where)
+	selectSql := fmt.Sprintf("SELECT * FROM %s %s LIMIT %d", tableStr, where, maxOldValueRows)
 	_, res, _ := dbConn.QueryContext(ctx, selectSql)
 
 	dbSqlExec.OldValue = jsonx.ToStr(res)
@@ -345,6 +465,13 @@ func (d *dbSqlExecAppImpl) doInsert(ctx context.Context, insert *sqlparser.Inser
 	dbSqlExec.Table = table
 	dbSqlExec.Type = entity.DbSqlExecTypeInsert
 
+	// 记录显式指定的主键值，用于生成回滚sql
+	if primaryKey, err := execSqlReq.DbConn.GetMetaData().GetPrimaryKey(rollbackTableName(execSqlReq.DbConn, table)); err == nil {
+		if keys := getInsertKeyValues(insert, primaryKey); len(keys) > 0 {
+			dbSqlExec.OldValue = jsonx.ToStr(keys)
+		}
+	}
+
 	return d.doExec(ctx, execSqlReq, dbSqlExec)
 }
 
@@ -357,7 +484,16 @@ func (d *dbSqlExecAppImpl) doExec(ctx context.Context, execSqlReq *DbSqlExecReq,
 		_, err := d.flowProcinstApp.StartProc(ctx, flowProcdefId, &flowdto.StarProc{
 			BizType: DbSqlExecFlowBizType,
 			BizKey:  bizKey,
-			Remark:  dbSqlExecRecord.Remark,
+			BizForm: jsonx.ToStr(collx.M{
+				"dbId":      dbSqlExecRecord.DbId,
+				"db":        dbSqlExecRecord.Db,
+				"table":     dbSqlExecRecord.Table,
+				"type":      dbSqlExecRecord.Type,
+				"sql":       dbSqlExecRecord.Sql,
+				"codePaths": dbConn.Info.CodePath,
+				"sqlReview": execSqlReq.reviewReport,
+			}),
+			Remark: dbSqlExecRecord.Remark,
 		})
 		if err != nil {
 			return nil, err
@@ -368,7 +504,11 @@ func (d *dbSqlExecAppImpl) doExec(ctx context.Context, execSqlReq *DbSqlExecReq,
 	}
 
 	sql := execSqlReq.Sql
-	rowsAffected, err := dbConn.ExecContext(ctx, sql)
+	result, err := dbConn.ExecResultContext(ctx, sql)
+	var rowsAffected int64
+	if err == nil {
+		rowsAffected, err = result.RowsAffected()
+	}
 	execRes := "success"
 	if err != nil {
 		execRes = err.Error()
@@ -376,6 +516,7 @@ func (d *dbSqlExecAppImpl) doExec(ctx context.Context, execSqlReq *DbSqlExecReq,
 		dbSqlExecRecord.Res = execRes
 	} else {
 		dbSqlExecRecord.Res = fmt.Sprintf("执行成功,影响条数: %d", rowsAffected)
+		fillInsertKey(dbConn, dbSqlExecRecord, result, rowsAffected)
 	}
 	res := make([]map[string]any, 0)
 	resData := make(map[string]any)
@@ -0,0 +1,420 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/domain/entity"
+	flowdto "mayfly-go/internal/flow/application/dto"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/utils/jsonx"
+	"mayfly-go/pkg/utils/stringx"
+	"strings"
+
+	"github.com/kanzihuang/vitess/go/vt/sqlparser"
+)
+
+// update、delete执行前最多记录的旧数据条数
+const maxOldValueRows = 200
+
+func (d *dbSqlExecAppImpl) GenRollbackSql(ctx context.Context, dbSqlExec *entity.DbSqlExec, dbConn *dbi.DbConn) ([]string, error) {
+	rows, err := checkRollbackable(dbSqlExec)
+	if err != nil {
+		return nil, err
+	}
+
+	// 回滚sql中包含旧数据，需按照脱敏规则脱敏后展示
+	masker, err := d.dbDataMaskRuleApp.GetDataMasker(ctx, dbConn)
+	if err != nil {
+		return nil, err
+	}
+	masker.ForTables(rollbackTableName(dbConn, dbSqlExec.Table)).MaskRows(rows, nil)
+
+	return genRollbackSql(dbConn, dbSqlExec, rows)
+}
+
+func (d *dbSqlExecAppImpl) Rollback(ctx context.Context, dbSqlExec *entity.DbSqlExec, dbConn *dbi.DbConn) (*DbSqlExecRes, error) {
+	rows, err := checkRollbackable(dbSqlExec)
+	if err != nil {
+		return nil, err
+	}
+	sqls, err := genRollbackSql(dbConn, dbSqlExec, rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(sqls) == 0 {
+		return nil, errorx.NewBiz("该执行记录无需要回滚的数据")
+	}
+
+	execSqlReq := &DbSqlExecReq{
+		DbId:   dbSqlExec.DbId,
+		Db:     dbSqlExec.Db,
+		Sql:    strings.Join(sqls, ";\n"),
+		Remark: fmt.Sprintf("回滚sql执行记录[%d]", dbSqlExec.Id),
+		DbConn: dbConn,
+	}
+	for _, rollbackSql := range sqls {
+		stmt, _ := sqlparser.Parse(rollbackSql)
+		if err := d.reviewSql(ctx, &DbSqlExecReq{DbId: execSqlReq.DbId, Db: execSqlReq.Db, Sql: rollbackSql, DbConn: dbConn}, stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	rollbackRecord := createSqlExecRecord(ctx, execSqlReq)
+	rollbackRecord.Table = dbSqlExec.Table
+	rollbackRecord.Type = entity.DbSqlExecTypeRollback
+	rollbackRecord.Status = entity.DbSqlExecStatusWait
+	if err := d.dbSqlExecRepo.Insert(ctx, rollbackRecord); err != nil {
+		return nil, err
+	}
+	// 标记失败则说明已被其他请求回滚
+	marked, err := d.dbSqlExecRepo.UpdateRollbackId(dbSqlExec.Id, rollbackRecord.Id)
+	if err == nil && !marked {
+		err = errorx.NewBiz("该执行记录已回滚或正在回滚中")
+	}
+	if err != nil {
+		rollbackRecord.Status = entity.DbSqlExecStatusNo
+		rollbackRecord.Res = err.Error()
+		d.dbSqlExecRepo.UpdateById(ctx, rollbackRecord)
+		return nil, err
+	}
+
+	// 关联审批流程时，所有回滚sql作为一个流程实例审批，审批通过后在事务中执行
+	if flowProcdefId := d.flowProcdefApp.GetProcdefIdByCodePath(ctx, dbConn.Info.CodePath...); flowProcdefId != 0 {
+		bizKey := stringx.Rand(24)
+		_, err := d.flowProcinstApp.StartProc(ctx, flowProcdefId, &flowdto.StarProc{
+			BizType: DbSqlExecFlowBizType,
+			BizKey:  bizKey,
+			BizForm: jsonx.ToStr(collx.M{
+				"dbId":      rollbackRecord.DbId,
+				"db":        rollbackRecord.Db,
+				"table":     rollbackRecord.Table,
+				"type":      rollbackRecord.Type,
+				"sql":       rollbackRecord.Sql,
+				"codePaths": dbConn.Info.CodePath,
+			}),
+			Remark: rollbackRecord.Remark,
+		})
+		if err != nil {
+			d.finishRollback(ctx, rollbackRecord, err)
+			return nil, err
+		}
+		rollbackRecord.FlowBizKey = bizKey
+		return nil, d.dbSqlExecRepo.UpdateById(ctx, rollbackRecord)
+	}
+
+	rowsAffected, err := execRollbackSqls(ctx, dbConn, sqls)
+	d.finishRollback(ctx, rollbackRecord, err, rowsAffected)
+	if err != nil {
+		return nil, err
+	}
+	return &DbSqlExecRes{
+		Columns: []*dbi.QueryColumn{
+			{Name: "sql", Type: "string"},
+			{Name: "rowsAffected", Type: "number"},
+			{Name: "result", Type: "string"},
+		},
+		Res: []map[string]any{{"sql": rollbackRecord.Sql, "rowsAffected": rowsAffected, "result": "success"}},
+	}, nil
+}
+
+// 更新回滚记录的执行结果，执行失败则取消原记录的回滚标记，允许再次回滚
+func (d *dbSqlExecAppImpl) finishRollback(ctx context.Context, rollbackRecord *entity.DbSqlExec, err error, rowsAffected ...int64) {
+	if err != nil {
+		if rollbackRecord.Status == entity.DbSqlExecStatusWait {
+			rollbackRecord.Status = entity.DbSqlExecStatusFail
+		}
+		if rollbackRecord.Res == "" {
+			rollbackRecord.Res = err.Error()
+		}
+		if err := d.dbSqlExecRepo.UpdateByCond(ctx, collx.M{"rollback_id": 0}, &entity.DbSqlExec{RollbackId: rollbackRecord.Id}); err != nil {
+			logx.Errorf("取消sql执行记录回滚标记失败: %s", err.Error())
+		}
+	} else {
+		rollbackRecord.Status = entity.DbSqlExecStatusSuccess
+		if len(rowsAffected) > 0 {
+			rollbackRecord.Res = fmt.Sprintf("执行成功,影响条数: %d", rowsAffected[0])
+		}
+	}
+	if err := d.dbSqlExecRepo.UpdateById(ctx, rollbackRecord); err != nil {
+		logx.Errorf("更新回滚sql执行记录失败: %s", err.Error())
+	}
+}
+
+// 校验执行记录是否可回滚，并返回记录的旧数据或插入的主键值
+func checkRollbackable(dbSqlExec *entity.DbSqlExec) ([]map[string]any, error) {
+	if dbSqlExec.Status != entity.DbSqlExecStatusSuccess {
+		return nil, errorx.NewBiz("仅支持回滚执行成功的sql")
+	}
+	if dbSqlExec.Type != entity.DbSqlExecTypeUpdate && dbSqlExec.Type != entity.DbSqlExecTypeDelete && dbSqlExec.Type != entity.DbSqlExecTypeInsert {
+		return nil, errorx.NewBiz("仅支持回滚update、delete、insert类型的sql")
+	}
+	if dbSqlExec.RollbackId != 0 {
+		return nil, errorx.NewBiz("该执行记录已回滚或正在回滚中")
+	}
+	if dbSqlExec.OldValue == "" || dbSqlExec.OldValue == "-" {
+		return nil, errorx.NewBiz("该执行记录未保存旧数据或插入的主键值，无法生成回滚sql")
+	}
+
+	rows, err := parseOldValue(dbSqlExec.OldValue)
+	if err != nil {
+		return nil, errorx.NewBiz("解析执行记录旧数据失败: %s", err.Error())
+	}
+	if len(rows) == 0 {
+		return nil, errorx.NewBiz("该执行记录无需要回滚的数据")
+	}
+	// 旧数据最多只记录200条，超出则无法完整回滚
+	if dbSqlExec.Type != entity.DbSqlExecTypeInsert && len(rows) >= maxOldValueRows {
+		return nil, errorx.NewBiz("影响数据达到旧数据记录上限%d条，旧数据可能不完整，无法生成回滚sql", maxOldValueRows)
+	}
+	return rows, nil
+}
+
+func genRollbackSql(dbConn *dbi.DbConn, dbSqlExec *entity.DbSqlExec, rows []map[string]any) ([]string, error) {
+	gen, err := newRollbackSqlGenerator(dbConn, dbSqlExec.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	switch dbSqlExec.Type {
+	case entity.DbSqlExecTypeUpdate:
+		return gen.updateSqls(rows)
+	case entity.DbSqlExecTypeDelete:
+		return gen.insertSqls(rows)
+	default:
+		return gen.deleteSqls(rows)
+	}
+}
+
+// 在同一事务中执行所有回滚sql，任意一条失败则全部回滚
+func execRollbackSqls(ctx context.Context, dbConn *dbi.DbConn, sqls []string) (int64, error) {
+	tx, err := dbConn.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	var rowsAffected int64
+	for _, rollbackSql := range sqls {
+		affected, err := dbConn.TxExecContext(ctx, tx, rollbackSql)
+		if err != nil {
+			_ = tx.Rollback()
+			return 0, errorx.NewBiz("执行回滚sql[%s]失败: %s", rollbackSql, err.Error())
+		}
+		rowsAffected += affected
+	}
+	return rowsAffected, tx.Commit()
+}
+
+// 拆分回滚记录中的多条回滚sql
+func splitRollbackSqls(dbConn *dbi.DbConn, sql string) ([]string, error) {
+	return sqlparser.SplitStatementToPieces(sql, sqlparser.WithDialect(dbConn.GetMetaData().GetSqlParserDialect()))
+}
+
+// 记录单条插入数据的自增主键值（需数据库驱动支持LastInsertId，如mysql），用于生成回滚sql
+func fillInsertKey(dbConn *dbi.DbConn, dbSqlExec *entity.DbSqlExec, res sql.Result, rowsAffected int64) {
+	// 多条插入时自增主键不一定连续，故只记录单条插入的主键值
+	if dbSqlExec.Type != entity.DbSqlExecTypeInsert || dbSqlExec.OldValue != "" || rowsAffected != 1 {
+		return
+	}
+	id, err := res.LastInsertId()
+	if err != nil || id <= 0 {
+		return
+	}
+	primaryKey, err := dbConn.GetMetaData().GetPrimaryKey(rollbackTableName(dbConn, dbSqlExec.Table))
+	if err != nil {
+		return
+	}
+	dbSqlExec.OldValue = jsonx.ToStr([]map[string]any{{primaryKey: id}})
+}
+
+// 获取insert语句中显式指定的主键值，未指定主键列或主键值非字面量则返回nil
+func getInsertKeyValues(insert *sqlparser.Insert, primaryKey string) []map[string]any {
+	values, ok := insert.Rows.(sqlparser.Values)
+	if !ok {
+		return nil
+	}
+
+	keyIndex := -1
+	for i, col := range insert.Columns {
+		if col.EqualString(primaryKey) {
+			keyIndex = i
+			break
+		}
+	}
+	if keyIndex < 0 {
+		return nil
+	}
+
+	keys := make([]map[string]any, 0, len(values))
+	for _, row := range values {
+		if keyIndex >= len(row) {
+			return nil
+		}
+		literal, ok := row[keyIndex].(*sqlparser.Literal)
+		if !ok {
+			return nil
+		}
+		var val any = literal.Val
+		if literal.Type == sqlparser.IntVal {
+			val = json.Number(literal.Val)
+		}
+		keys = append(keys, map[string]any{primaryKey: val})
+	}
+	return keys
+}
+
+// 获取执行记录中不带库名及引号的表名
+func rollbackTableName(dbConn *dbi.DbConn, table string) string {
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		table = table[i+1:]
+	}
+	return dbConn.GetMetaData().RemoveQuote(strings.Trim(table, "`"))
+}
+
+// 解析执行记录中保存的旧数据，数字使用json.Number避免精度丢失
+func parseOldValue(oldValue string) ([]map[string]any, error) {
+	decoder := json.NewDecoder(strings.NewReader(oldValue))
+	decoder.UseNumber()
+	var rows []map[string]any
+	if err := decoder.Decode(&rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// 回滚sql生成器，按照各数据库方言转义标识符及包装列值
+type rollbackSqlGenerator struct {
+	metadata   *dbi.MetaDataX
+	dataHelper dbi.DataHelper
+	table      string // 已转义的表名
+	primaryKey string
+	columns    []dbi.Column
+}
+
+func newRollbackSqlGenerator(dbConn *dbi.DbConn, table string) (*rollbackSqlGenerator, error) {
+	metadata := dbConn.GetMetaData()
+	tableName := rollbackTableName(dbConn, table)
+
+	columns, err := metadata.GetColumns(tableName)
+	if err != nil {
+		return nil, errorx.NewBiz("获取表列信息失败: %s", err.Error())
+	}
+	if len(columns) == 0 {
+		return nil, errorx.NewBiz("表[%s]不存在", tableName)
+	}
+	// 无主键或联合主键时，无法通过单列条件准确定位数据
+	primaryKeys := collx.ArrayFilter(columns, func(col dbi.Column) bool { return col.IsPrimaryKey })
+	if len(primaryKeys) != 1 {
+		return nil, errorx.NewBiz("表[%s]无主键或为联合主键，无法生成回滚sql", tableName)
+	}
+	primaryKey := primaryKeys[0].ColumnName
+
+	return &rollbackSqlGenerator{
+		metadata:   metadata,
+		dataHelper: metadata.GetDataHelper(),
+		table:      metadata.QuoteIdentifier(tableName),
+		primaryKey: primaryKey,
+		columns:    columns,
+	}, nil
+}
+
+// 将更新的数据还原为旧值
+func (g *rollbackSqlGenerator) updateSqls(rows []map[string]any) ([]string, error) {
+	sqls := make([]string, 0, len(rows))
+	for _, row := range rows {
+		where, err := g.keyCondition(row)
+		if err != nil {
+			return nil, err
+		}
+
+		sets := make([]string, 0)
+		for _, col := range g.columns {
+			val, ok := getRowValue(row, col.ColumnName)
+			if !ok || strings.EqualFold(col.ColumnName, g.primaryKey) {
+				continue
+			}
+			sets = append(sets, fmt.Sprintf("%s = %s", g.metadata.QuoteIdentifier(col.ColumnName), g.wrapValue(col, val)))
+		}
+		if len(sets) == 0 {
+			continue
+		}
+		sqls = append(sqls, fmt.Sprintf("UPDATE %s SET %s WHERE %s", g.table, strings.Join(sets, ", "), where))
+	}
+	return sqls, nil
+}
+
+// 重新插入被删除的数据
+func (g *rollbackSqlGenerator) insertSqls(rows []map[string]any) ([]string, error) {
+	sqls := make([]string, 0, len(rows))
+	for _, row := range rows {
+		cols := make([]string, 0)
+		values := make([]string, 0)
+		for _, col := range g.columns {
+			val, ok := getRowValue(row, col.ColumnName)
+			if !ok {
+				continue
+			}
+			cols = append(cols, g.metadata.QuoteIdentifier(col.ColumnName))
+			values = append(values, g.wrapValue(col, val))
+		}
+		if len(cols) == 0 {
+			return nil, errorx.NewBiz("旧数据与表[%s]列信息不匹配", g.table)
+		}
+		sqls = append(sqls, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", g.table, strings.Join(cols, ", "), strings.Join(values, ", ")))
+	}
+	return sqls, nil
+}
+
+// 根据插入数据的主键值删除数据
+func (g *rollbackSqlGenerator) deleteSqls(rows []map[string]any) ([]string, error) {
+	keyCol := g.primaryKeyColumn()
+	values := make([]string, 0, len(rows))
+	for _, row := range rows {
+		val, ok := getRowValue(row, g.primaryKey)
+		if !ok || val == nil {
+			return nil, errorx.NewBiz("执行记录缺少主键[%s]值", g.primaryKey)
+		}
+		values = append(values, g.wrapValue(keyCol, val))
+	}
+	return []string{fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)", g.table, g.metadata.QuoteIdentifier(keyCol.ColumnName), strings.Join(values, ", "))}, nil
+}
+
+// 生成主键等值条件
+func (g *rollbackSqlGenerator) keyCondition(row map[string]any) (string, error) {
+	val, ok := getRowValue(row, g.primaryKey)
+	if !ok || val == nil {
+		return "", errorx.NewBiz("执行记录缺少主键[%s]值", g.primaryKey)
+	}
+	keyCol := g.primaryKeyColumn()
+	return fmt.Sprintf("%s = %s", g.metadata.QuoteIdentifier(keyCol.ColumnName), g.wrapValue(keyCol, val)), nil
+}
+
+func (g *rollbackSqlGenerator) primaryKeyColumn() dbi.Column {
+	for _, col := range g.columns {
+		if strings.EqualFold(col.ColumnName, g.primaryKey) {
+			return col
+		}
+	}
+	return dbi.Column{ColumnName: g.primaryKey}
+}
+
+func (g *rollbackSqlGenerator) wrapValue(col dbi.Column, val any) string {
+	return g.dataHelper.WrapValue(val, g.dataHelper.GetDataType(string(col.DataType)))
+}
+
+// 忽略大小写获取行数据中指定列的值
+func getRowValue(row map[string]any, column string) (any, bool) {
+	if val, ok := row[column]; ok {
+		return val, true
+	}
+	for k, v := range row {
+		if strings.EqualFold(k, column) {
+			return v, true
+		}
+	}
+	return nil, false
+}
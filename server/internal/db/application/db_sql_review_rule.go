@@ -0,0 +1,397 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"mayfly-go/internal/db/application/dto"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/internal/db/domain/repository"
+	tagapp "mayfly-go/internal/tag/application"
+	tagentity "mayfly-go/internal/tag/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/errorx"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kanzihuang/vitess/go/vt/sqlparser"
+	"github.com/may-fly/cast"
+)
+
+// SqlReviewResult 违反的sql审核规则信息
+type SqlReviewResult struct {
+	RuleName string                   `json:"ruleName"`
+	RuleType entity.SqlReviewRuleType `json:"ruleType"`
+	Level    entity.SqlReviewLevel    `json:"level"`
+	Msg      string                   `json:"msg"`
+}
+
+// SqlReviewReport sql审核报告
+type SqlReviewReport struct {
+	Sql     string             `json:"sql"`
+	Results []*SqlReviewResult `json:"results"`
+}
+
+// 是否存在错误级别的审核结果
+func (r *SqlReviewReport) HasError() bool {
+	return len(r.Msgs(entity.SqlReviewLevelError)) > 0
+}
+
+// 获取指定级别的审核结果信息
+func (r *SqlReviewReport) Msgs(level entity.SqlReviewLevel) []string {
+	msgs := make([]string, 0)
+	for _, res := range r.Results {
+		if res.Level == level {
+			msgs = append(msgs, fmt.Sprintf("[%s] %s", res.RuleName, res.Msg))
+		}
+	}
+	return msgs
+}
+
+type DbSqlReviewRule interface {
+	base.App[*entity.DbSqlReviewRule]
+
+	SaveRule(ctx context.Context, param *dto.SaveDbSqlReviewRule) error
+
+	DeleteRule(ctx context.Context, id uint64) error
+
+	// Review 根据库关联的审核规则审核sql，stmt为nil表示sql无法解析，未关联审核规则则返回nil
+	Review(ctx context.Context, dbConn *dbi.DbConn, sql string, stmt sqlparser.Statement) (*SqlReviewReport, error)
+}
+
+type dbSqlReviewRuleAppImpl struct {
+	base.AppImpl[*entity.DbSqlReviewRule, repository.DbSqlReviewRule]
+
+	tagTreeRelateApp tagapp.TagTreeRelate `inject:"TagTreeRelateApp"`
+}
+
+var _ (DbSqlReviewRule) = (*dbSqlReviewRuleAppImpl)(nil)
+
+// 注入DbSqlReviewRuleRepo
+func (d *dbSqlReviewRuleAppImpl) InjectDbSqlReviewRuleRepo(repo repository.DbSqlReviewRule) {
+	d.Repo = repo
+}
+
+func (d *dbSqlReviewRuleAppImpl) SaveRule(ctx context.Context, param *dto.SaveDbSqlReviewRule) error {
+	rule := param.Rule
+	if rule.Level != entity.SqlReviewLevelError && rule.Level != entity.SqlReviewLevelWarn {
+		return errorx.NewBiz("不支持的审核级别: %s", rule.Level)
+	}
+
+	switch rule.RuleType {
+	case entity.SqlReviewRuleNoSelectStar, entity.SqlReviewRuleDdlRequireComment, entity.SqlReviewRuleNoImplicitConversion:
+	case entity.SqlReviewRuleMaxAffectedRows:
+		if maxRows, err := strconv.Atoi(rule.Param); err != nil || maxRows <= 0 {
+			return errorx.NewBiz("最大影响行数需为正整数")
+		}
+	case entity.SqlReviewRuleChangeWindow:
+		if _, _, err := parseChangeWindow(rule.Param); err != nil {
+			return err
+		}
+	default:
+		return errorx.NewBiz("不支持的审核规则: %s", rule.RuleType)
+	}
+
+	return d.Tx(ctx, func(ctx context.Context) error {
+		return d.Save(ctx, rule)
+	}, func(ctx context.Context) error {
+		return d.tagTreeRelateApp.RelateTag(ctx, tagentity.TagRelateTypeDbSqlReview, rule.Id, param.CodePaths...)
+	})
+}
+
+func (d *dbSqlReviewRuleAppImpl) DeleteRule(ctx context.Context, id uint64) error {
+	_, err := d.GetById(id)
+	if err != nil {
+		return errorx.NewBiz("该审核规则不存在")
+	}
+
+	return d.Tx(ctx, func(ctx context.Context) error {
+		return d.DeleteById(ctx, id)
+	}, func(ctx context.Context) error {
+		return d.tagTreeRelateApp.DeleteByCond(ctx, &tagentity.TagTreeRelate{
+			RelateType: tagentity.TagRelateTypeDbSqlReview,
+			RelateId:   id,
+		})
+	})
+}
+
+func (d *dbSqlReviewRuleAppImpl) Review(ctx context.Context, dbConn *dbi.DbConn, sql string, stmt sqlparser.Statement) (*SqlReviewReport, error) {
+	ruleIds, err := d.tagTreeRelateApp.GetRelateIds(ctx, tagentity.TagRelateTypeDbSqlReview, dbConn.Info.CodePath...)
+	if err != nil {
+		return nil, errorx.NewBiz("获取sql审核规则失败: %s", err.Error())
+	}
+	if len(ruleIds) == 0 {
+		return nil, nil
+	}
+	rules, err := d.GetByIds(ruleIds)
+	if err != nil {
+		return nil, errorx.NewBiz("获取sql审核规则失败: %s", err.Error())
+	}
+
+	report := &SqlReviewReport{Sql: sql, Results: make([]*SqlReviewResult, 0)}
+	for _, rule := range rules {
+		if rule.Status != entity.DbSqlReviewRuleStatusEnable {
+			continue
+		}
+		msg, err := reviewSql(ctx, rule, dbConn, sql, stmt)
+		// 规则执行失败则无法确定sql是否合规，直接阻断执行
+		if err != nil {
+			return nil, errorx.NewBiz("sql审核规则[%s]执行失败: %s", rule.Name, err.Error())
+		}
+		if msg != "" {
+			report.Results = append(report.Results, &SqlReviewResult{RuleName: rule.Name, RuleType: rule.RuleType, Level: rule.Level, Msg: msg})
+		}
+	}
+	return report, nil
+}
+
+// 使用指定规则审核sql，返回违反规则的提示信息，未违反则返回空字符串
+func reviewSql(ctx context.Context, rule *entity.DbSqlReviewRule, dbConn *dbi.DbConn, sql string, stmt sqlparser.Statement) (string, error) {
+	switch rule.RuleType {
+	case entity.SqlReviewRuleNoSelectStar:
+		return reviewNoSelectStar(stmt), nil
+	case entity.SqlReviewRuleDdlRequireComment:
+		return reviewDdlComment(stmt), nil
+	case entity.SqlReviewRuleNoImplicitConversion:
+		return reviewImplicitConversion(dbConn, stmt)
+	case entity.SqlReviewRuleMaxAffectedRows:
+		return reviewMaxAffectedRows(ctx, dbConn, sql, stmt, rule.Param)
+	case entity.SqlReviewRuleChangeWindow:
+		return reviewChangeWindow(sql, stmt, rule.Param, time.Now())
+	}
+	return "", nil
+}
+
+func reviewNoSelectStar(stmt sqlparser.Statement) string {
+	if stmt == nil {
+		return ""
+	}
+	hasStar := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if _, ok := node.(*sqlparser.StarExpr); ok {
+			hasStar = true
+			return false, nil
+		}
+		return true, nil
+	}, stmt)
+	if hasStar {
+		return "禁止使用SELECT *，请明确指定查询列"
+	}
+	return ""
+}
+
+func reviewDdlComment(stmt sqlparser.Statement) string {
+	var columns []*sqlparser.ColumnDefinition
+	switch s := stmt.(type) {
+	case *sqlparser.CreateTable:
+		if s.TableSpec == nil {
+			return ""
+		}
+		hasComment := false
+		for _, opt := range s.TableSpec.Options {
+			if strings.EqualFold(opt.Name, "comment") && opt.Value != nil && opt.Value.Val != "" {
+				hasComment = true
+			}
+		}
+		if !hasComment {
+			return fmt.Sprintf("表[%s]需添加表注释", s.Table.Name.String())
+		}
+		columns = s.TableSpec.Columns
+	case *sqlparser.AlterTable:
+		for _, opt := range s.AlterOptions {
+			switch o := opt.(type) {
+			case *sqlparser.AddColumns:
+				columns = append(columns, o.Columns...)
+			case *sqlparser.ModifyColumn:
+				columns = append(columns, o.NewColDefinition)
+			case *sqlparser.ChangeColumn:
+				columns = append(columns, o.NewColDefinition)
+			}
+		}
+	default:
+		return ""
+	}
+
+	noCommentCols := make([]string, 0)
+	for _, col := range columns {
+		if col.Type == nil || col.Type.Options == nil || col.Type.Options.Comment == nil || col.Type.Options.Comment.Val == "" {
+			noCommentCols = append(noCommentCols, col.Name.String())
+		}
+	}
+	if len(noCommentCols) > 0 {
+		return fmt.Sprintf("列[%s]需添加注释", strings.Join(noCommentCols, ", "))
+	}
+	return ""
+}
+
+// 判断where条件中字符串类型的索引列是否与数字进行比较，该情况会发生隐式类型转换导致索引失效
+func reviewImplicitConversion(dbConn *dbi.DbConn, stmt sqlparser.Statement) (string, error) {
+	var where *sqlparser.Where
+	var tableExprs sqlparser.TableExprs
+	switch s := stmt.(type) {
+	case *sqlparser.Select:
+		where, tableExprs = s.Where, s.From
+	case *sqlparser.Update:
+		where, tableExprs = s.Where, s.TableExprs
+	case *sqlparser.Delete:
+		where, tableExprs = s.Where, s.TableExprs
+	default:
+		return "", nil
+	}
+	if where == nil {
+		return "", nil
+	}
+
+	// 收集where条件中列与数字字面量的比较
+	compareCols := make([]*sqlparser.ColName, 0)
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		cmp, ok := node.(*sqlparser.ComparisonExpr)
+		if !ok {
+			return true, nil
+		}
+		if col, ok := cmp.Left.(*sqlparser.ColName); ok && isNumberLiteral(cmp.Right) {
+			compareCols = append(compareCols, col)
+		} else if col, ok := cmp.Right.(*sqlparser.ColName); ok && isNumberLiteral(cmp.Left) {
+			compareCols = append(compareCols, col)
+		}
+		return true, nil
+	}, where)
+	if len(compareCols) == 0 {
+		return "", nil
+	}
+
+	metadata := dbConn.GetMetaData()
+	dataHelper := metadata.GetDataHelper()
+	convertCols := make([]string, 0)
+	for _, table := range getTableNames(tableExprs) {
+		columns, err := metadata.GetColumns(table)
+		if err != nil {
+			return "", err
+		}
+		indexs, err := metadata.GetTableIndex(table)
+		if err != nil {
+			return "", err
+		}
+
+		indexCols := make(map[string]bool)
+		for _, index := range indexs {
+			for _, col := range strings.Split(index.ColumnName, ",") {
+				indexCols[strings.ToLower(strings.TrimSpace(col))] = true
+			}
+		}
+		stringCols := make(map[string]bool)
+		for _, col := range columns {
+			if dataHelper.GetDataType(string(col.DataType)) == dbi.DataTypeString {
+				stringCols[strings.ToLower(col.ColumnName)] = true
+			}
+		}
+
+		for _, col := range compareCols {
+			colName := strings.ToLower(col.Name.String())
+			if indexCols[colName] && stringCols[colName] {
+				convertCols = append(convertCols, col.Name.String())
+			}
+		}
+	}
+	if len(convertCols) > 0 {
+		return fmt.Sprintf("字符串类型的索引列[%s]与数字比较会发生隐式类型转换导致索引失效", strings.Join(convertCols, ", ")), nil
+	}
+	return "", nil
+}
+
+func isNumberLiteral(expr sqlparser.Expr) bool {
+	literal, ok := expr.(*sqlparser.Literal)
+	return ok && (literal.Type == sqlparser.IntVal || literal.Type == sqlparser.DecimalVal || literal.Type == sqlparser.FloatVal)
+}
+
+// 获取语法节点中的所有表名
+func getTableNames(node sqlparser.SQLNode) []string {
+	tables := make([]string, 0)
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if tn, ok := node.(sqlparser.TableName); ok && !tn.Name.IsEmpty() {
+			tables = append(tables, tn.Name.String())
+		}
+		return true, nil
+	}, node)
+	return tables
+}
+
+// 通过EXPLAIN预估update、delete的影响行数，目前仅支持mysql、mariadb
+func reviewMaxAffectedRows(ctx context.Context, dbConn *dbi.DbConn, sql string, stmt sqlparser.Statement, param string) (string, error) {
+	switch stmt.(type) {
+	case *sqlparser.Update, *sqlparser.Delete:
+	default:
+		return "", nil
+	}
+	if dbConn.Info.Type != dbi.DbTypeMysql && dbConn.Info.Type != dbi.DbTypeMariadb {
+		return "", nil
+	}
+	maxRows, err := strconv.Atoi(param)
+	if err != nil {
+		return "", err
+	}
+
+	_, res, err := dbConn.QueryContext(ctx, "EXPLAIN "+sql)
+	if err != nil {
+		return "", err
+	}
+	var rows int64
+	for _, row := range res {
+		val, _ := getRowValue(row, "rows")
+		if r := cast.ToInt64(val); r > rows {
+			rows = r
+		}
+	}
+	if rows > int64(maxRows) {
+		return fmt.Sprintf("预估影响行数[%d]超过限制[%d]", rows, maxRows), nil
+	}
+	return "", nil
+}
+
+// DROP、TRUNCATE仅允许在变更窗口内执行
+func reviewChangeWindow(sql string, stmt sqlparser.Statement, param string, now time.Time) (string, error) {
+	isDrop := false
+	switch stmt.(type) {
+	case *sqlparser.DropTable, *sqlparser.TruncateTable, *sqlparser.DropDatabase:
+		isDrop = true
+	case nil:
+		lowerSql := strings.ToLower(sql)
+		isDrop = strings.HasPrefix(lowerSql, "drop ") || strings.HasPrefix(lowerSql, "truncate ")
+	}
+	if !isDrop {
+		return "", nil
+	}
+
+	start, end, err := parseChangeWindow(param)
+	if err != nil {
+		return "", err
+	}
+	minute := now.Hour()*60 + now.Minute()
+	inWindow := minute >= start && minute < end
+	// 跨天的变更窗口，如22:00-06:00
+	if start > end {
+		inWindow = minute >= start || minute < end
+	}
+	if !inWindow {
+		return fmt.Sprintf("DROP、TRUNCATE仅允许在变更窗口[%s]内执行", param), nil
+	}
+	return "", nil
+}
+
+// 解析变更窗口，如22:00-06:00，返回开始与结束时间距离零点的分钟数
+func parseChangeWindow(window string) (int, int, error) {
+	parts := strings.Split(window, "-")
+	if len(parts) != 2 {
+		return 0, 0, errorx.NewBiz("变更窗口格式有误，如: 22:00-06:00")
+	}
+	minutes := make([]int, 2)
+	for i, part := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return 0, 0, errorx.NewBiz("变更窗口格式有误，如: 22:00-06:00")
+		}
+		minutes[i] = t.Hour()*60 + t.Minute()
+	}
+	return minutes[0], minutes[1], nil
+}
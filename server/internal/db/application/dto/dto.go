@@ -2,6 +2,7 @@ package dto
 
 import (
 	"io"
+	"mayfly-go/internal/db/dbm/dbi"
 	"mayfly-go/internal/db/domain/entity"
 	tagentity "mayfly-go/internal/tag/domain/entity"
 )
@@ -21,3 +22,57 @@ type DumpDb struct {
 
 	Writer io.Writer
 }
+
+// 表结构对比参数，对比源库与目标库表结构差异，并生成将目标库变更为源库结构的sql
+type DbSchemaDiff struct {
+	SrcConn    *dbi.DbConn
+	TargetConn *dbi.DbConn
+	TableNames []string // 需要对比的表，为空则对比全部表
+	AllowDrop  bool     // 是否生成删除表、删除列的sql
+}
+
+// 表结构对比结果
+type DbSchemaDiffRes struct {
+	AddTables    []string           `json:"addTables"`    // 目标库需新增的表
+	DropTables   []string           `json:"dropTables"`   // 目标库多出的表
+	ChangeTables []*DbTableDiffInfo `json:"changeTables"` // 结构存在差异的表
+	Sqls         []string           `json:"sqls"`         // 按执行顺序排列的变更sql
+}
+
+// 表结构差异信息
+type DbTableDiffInfo struct {
+	TableName     string              `json:"tableName"`
+	AddColumns    []string            `json:"addColumns"`
+	DropColumns   []string            `json:"dropColumns"`
+	ModifyColumns []*DbColumnDiffInfo `json:"modifyColumns"`
+	AddIndexs     []string            `json:"addIndexs"`
+	DropIndexs    []string            `json:"dropIndexs"`
+	ModifyIndexs  []string            `json:"modifyIndexs"`
+}
+
+// 列差异信息
+type DbColumnDiffInfo struct {
+	ColumnName string `json:"columnName"`
+	Src        string `json:"src"`    // 源库列定义描述
+	Target     string `json:"target"` // 目标库列定义描述
+}
+
+type SaveDbDataMaskRule struct {
+	Rule      *entity.DbDataMaskRule
+	CodePaths []string
+}
+
+type SaveDbSqlReviewRule struct {
+	Rule      *entity.DbSqlReviewRule
+	CodePaths []string
+}
+
+// 提交异步查询任务参数
+type SubmitDbQueryJob struct {
+	DbId   uint64
+	Db     string
+	Sql    string
+	Format entity.DbQueryJobFormat
+	Remark string
+	DbConn *dbi.DbConn
+}
@@ -19,6 +19,9 @@ type Dbms struct {
 	QuerySqlSave bool // 是否记录查询类sql
 	MaxResultSet int  // 允许sql查询的最大结果集数。注: 0=不限制
 	SqlExecTl    int  // sql执行时间限制，超过该时间（单位：秒），执行将被取消
+
+	QueryJobPath        string // 异步查询任务导出文件存储路径
+	QueryJobExpireHours int    // 异步查询任务导出文件有效时间（单位：小时），过期后将被删除
 }
 
 func GetDbms() *Dbms {
@@ -29,6 +32,13 @@ func GetDbms() *Dbms {
 	dbmsConf.QuerySqlSave = c.ConvBool(jm["querySqlSave"], false)
 	dbmsConf.MaxResultSet = cast.ToInt(jm["maxResultSet"])
 	dbmsConf.SqlExecTl = cast.ToIntD(jm["sqlExecTl"], 60)
+
+	queryJobPath := jm["queryJobPath"]
+	if queryJobPath == "" {
+		queryJobPath = "./db/query-job"
+	}
+	dbmsConf.QueryJobPath = filepath.Join(queryJobPath)
+	dbmsConf.QueryJobExpireHours = cast.ToIntD(jm["queryJobExpireHours"], 24)
 	return dbmsConf
 }
 
@@ -0,0 +1,82 @@
+package clickhouse
+
+import (
+	"database/sql"
+	"fmt"
+	"mayfly-go/internal/db/dbm/dbi"
+	"strings"
+	"time"
+)
+
+type ClickhouseDialect struct {
+	dbi.DefaultDialect
+
+	dc *dbi.DbConn
+}
+
+func (cd *ClickhouseDialect) BatchInsert(tx *sql.Tx, tableName string, columns []string, values [][]any, duplicateStrategy int) (int64, error) {
+	// clickhouse不支持insert ignore或replace语法，重复数据由表引擎（如ReplacingMergeTree）自行处理，故忽略duplicateStrategy
+	// 生成占位符字符串：如：(?,?)
+	repeated := strings.Repeat("?,", len(columns))
+	placeholder := fmt.Sprintf("(%s)", strings.TrimSuffix(repeated, ","))
+
+	// 重复占位符字符串n遍，并去除最后一个逗号
+	repeated = strings.Repeat(placeholder+",", len(values))
+	placeholder = strings.TrimSuffix(repeated, ",")
+
+	sqlStr := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", cd.dc.GetMetaData().QuoteIdentifier(tableName), strings.Join(columns, ","), placeholder)
+	// 把二维数组转为一维数组
+	var args []any
+	for _, v := range values {
+		args = append(args, v...)
+	}
+	// clickhouse驱动执行insert不返回影响行数，故执行成功则返回插入数据条数
+	if _, err := cd.dc.TxExec(tx, sqlStr, args...); err != nil {
+		return 0, err
+	}
+	return int64(len(values)), nil
+}
+
+func (cd *ClickhouseDialect) CopyTable(copy *dbi.DbCopyTable) error {
+	meta := cd.dc.GetMetaData()
+	tableName := meta.QuoteIdentifier(copy.TableName)
+
+	// 生成新表名,为老表明+_copy_时间戳
+	newTableName := meta.QuoteIdentifier(copy.TableName + "_copy_" + time.Now().Format("20060102150405"))
+
+	// 复制表结构（包括表引擎、排序键等）创建表
+	_, err := cd.dc.Exec(fmt.Sprintf("CREATE TABLE %s AS %s", newTableName, tableName))
+	if err != nil {
+		return err
+	}
+
+	// 复制数据
+	if copy.CopyData {
+		go func() {
+			_, _ = cd.dc.Exec(fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", newTableName, tableName))
+		}()
+	}
+	return err
+}
+
+func (cd *ClickhouseDialect) CreateTable(columns []dbi.Column, tableInfo dbi.Table, dropOldTable bool) (int, error) {
+	sqlArr := cd.dc.GetMetaData().GenerateTableDDL(columns, tableInfo, dropOldTable)
+	for _, sqlStr := range sqlArr {
+		_, err := cd.dc.Exec(sqlStr)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(sqlArr), nil
+}
+
+func (cd *ClickhouseDialect) CreateIndex(tableInfo dbi.Table, indexs []dbi.Index) error {
+	sqlArr := cd.dc.GetMetaData().GenerateIndexDDL(indexs, tableInfo)
+	for _, sqlStr := range sqlArr {
+		_, err := cd.dc.Exec(sqlStr)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
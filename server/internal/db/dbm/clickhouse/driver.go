@@ -0,0 +1,101 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// clickhouse-go std驱动实现的连接接口集合
+type stdConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.NamedValueChecker
+}
+
+// clickhouse-go std驱动实现的结果集接口集合
+type stdRows interface {
+	driver.Rows
+	driver.RowsColumnTypeScanType
+	driver.RowsColumnTypeDatabaseTypeName
+	driver.RowsColumnTypeNullable
+	driver.RowsColumnTypePrecisionScale
+}
+
+// 包装clickhouse驱动的连接器。
+// clickhouse的Array、Map、Tuple、Int128等类型返回的是go切片、map、big.Int等值，
+// 无法被dbi以[]byte方式Scan，故在此统一转为字符串
+type connector struct {
+	driver.Connector
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sc, ok := conn.(stdConn)
+	if !ok {
+		conn.Close()
+		return nil, errors.New("不支持的clickhouse驱动连接")
+	}
+	return &chConn{sc}, nil
+}
+
+type chConn struct {
+	stdConn
+}
+
+func (c *chConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.stdConn.QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if sr, ok := rows.(stdRows); ok {
+		return &chRows{sr}, nil
+	}
+	return rows, nil
+}
+
+type chRows struct {
+	stdRows
+}
+
+func (r *chRows) Next(dest []driver.Value) error {
+	if err := r.stdRows.Next(dest); err != nil {
+		return err
+	}
+	for i, v := range dest {
+		dest[i] = toScanValue(v)
+	}
+	return nil
+}
+
+// 将database/sql无法直接Scan为[]byte的值转为字符串
+func toScanValue(val any) any {
+	switch v := val.(type) {
+	case nil, []byte, string, time.Time, bool:
+		return val
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	switch reflect.ValueOf(val).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct, reflect.Pointer:
+		bytes, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprintf("%v", val)
+		}
+		return string(bytes)
+	}
+	return val
+}
@@ -0,0 +1,272 @@
+package clickhouse
+
+import (
+	"fmt"
+	"io"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/utils/anyx"
+	"strings"
+	"time"
+
+	"github.com/may-fly/cast"
+)
+
+var (
+	//  clickhouse数据类型 映射 公共数据类型
+	commonColumnTypeMap = map[string]dbi.ColumnDataType{
+		"Int8":        dbi.CommonTypeTinyint,
+		"UInt8":       dbi.CommonTypeSmallint,
+		"Int16":       dbi.CommonTypeSmallint,
+		"UInt16":      dbi.CommonTypeInt,
+		"Int32":       dbi.CommonTypeInt,
+		"UInt32":      dbi.CommonTypeBigint,
+		"Int64":       dbi.CommonTypeBigint,
+		"UInt64":      dbi.CommonTypeNumber,
+		"Int128":      dbi.CommonTypeNumber,
+		"UInt128":     dbi.CommonTypeNumber,
+		"Int256":      dbi.CommonTypeNumber,
+		"UInt256":     dbi.CommonTypeNumber,
+		"Float32":     dbi.CommonTypeNumber,
+		"Float64":     dbi.CommonTypeNumber,
+		"Decimal":     dbi.CommonTypeNumber,
+		"Decimal32":   dbi.CommonTypeNumber,
+		"Decimal64":   dbi.CommonTypeNumber,
+		"Decimal128":  dbi.CommonTypeNumber,
+		"Decimal256":  dbi.CommonTypeNumber,
+		"Bool":        dbi.CommonTypeTinyint,
+		"String":      dbi.CommonTypeText,
+		"FixedString": dbi.CommonTypeChar,
+		"UUID":        dbi.CommonTypeVarchar,
+		"Enum8":       dbi.CommonTypeVarchar,
+		"Enum16":      dbi.CommonTypeVarchar,
+		"IPv4":        dbi.CommonTypeVarchar,
+		"IPv6":        dbi.CommonTypeVarchar,
+		"Date":        dbi.CommonTypeDate,
+		"Date32":      dbi.CommonTypeDate,
+		"DateTime":    dbi.CommonTypeDatetime,
+		"DateTime64":  dbi.CommonTypeTimestamp,
+		"JSON":        dbi.CommonTypeJSON,
+		"Object":      dbi.CommonTypeJSON,
+		"Array":       dbi.CommonTypeJSON,
+		"Map":         dbi.CommonTypeJSON,
+		"Tuple":       dbi.CommonTypeJSON,
+		"Nested":      dbi.CommonTypeJSON,
+	}
+
+	// 公共数据类型 映射 clickhouse数据类型
+	clickhouseColumnTypeMap = map[dbi.ColumnDataType]string{
+		dbi.CommonTypeVarchar:    "String",
+		dbi.CommonTypeChar:       "String",
+		dbi.CommonTypeText:       "String",
+		dbi.CommonTypeBlob:       "String",
+		dbi.CommonTypeLongblob:   "String",
+		dbi.CommonTypeLongtext:   "String",
+		dbi.CommonTypeBinary:     "String",
+		dbi.CommonTypeMediumblob: "String",
+		dbi.CommonTypeMediumtext: "String",
+		dbi.CommonTypeVarbinary:  "String",
+		dbi.CommonTypeInt:        "Int32",
+		dbi.CommonTypeBit:        "UInt8",
+		dbi.CommonTypeSmallint:   "Int16",
+		dbi.CommonTypeTinyint:    "Int8",
+		dbi.CommonTypeNumber:     "Decimal",
+		dbi.CommonTypeBigint:     "Int64",
+		dbi.CommonTypeDatetime:   "DateTime",
+		dbi.CommonTypeDate:       "Date32",
+		dbi.CommonTypeTime:       "String",
+		dbi.CommonTypeTimestamp:  "DateTime64(6)",
+		dbi.CommonTypeEnum:       "String",
+		dbi.CommonTypeJSON:       "String",
+	}
+)
+
+// 去除Nullable、LowCardinality等包装类型，如：LowCardinality(Nullable(String)) -> String
+func unwrapType(dbColumnType string) string {
+	for {
+		unwrapped := false
+		for _, wrapper := range []string{"Nullable(", "LowCardinality("} {
+			if strings.HasPrefix(dbColumnType, wrapper) && strings.HasSuffix(dbColumnType, ")") {
+				dbColumnType = dbColumnType[len(wrapper) : len(dbColumnType)-1]
+				unwrapped = true
+			}
+		}
+		if !unwrapped {
+			return dbColumnType
+		}
+	}
+}
+
+// 获取基础类型名，如：Nullable(Decimal(10, 2)) -> Decimal, DateTime64(3) -> DateTime64
+func baseTypeName(dbColumnType string) string {
+	typ := unwrapType(dbColumnType)
+	if i := strings.Index(typ, "("); i > 0 {
+		return typ[:i]
+	}
+	return typ
+}
+
+// 获取类型的参数，如：Decimal(10, 2) -> [10, 2]
+func typeParams(dbColumnType string) []string {
+	typ := unwrapType(dbColumnType)
+	start := strings.Index(typ, "(")
+	if start < 0 || !strings.HasSuffix(typ, ")") {
+		return nil
+	}
+	params := strings.Split(typ[start+1:len(typ)-1], ",")
+	for i, param := range params {
+		params[i] = strings.TrimSpace(param)
+	}
+	return params
+}
+
+type DataHelper struct {
+}
+
+func (dc *DataHelper) GetDataType(dbColumnType string) dbi.DataType {
+	baseType := baseTypeName(dbColumnType)
+	switch {
+	case strings.HasPrefix(baseType, "Int"), strings.HasPrefix(baseType, "UInt"),
+		strings.HasPrefix(baseType, "Float"), strings.HasPrefix(baseType, "Decimal"), baseType == "Bool":
+		return dbi.DataTypeNumber
+	case strings.HasPrefix(baseType, "DateTime"):
+		return dbi.DataTypeDateTime
+	case strings.HasPrefix(baseType, "Date"):
+		return dbi.DataTypeDate
+	}
+	return dbi.DataTypeString
+}
+
+func (dc *DataHelper) FormatData(dbColumnValue any, dataType dbi.DataType) string {
+	str, ok := dbColumnValue.(string)
+	if !ok {
+		return anyx.ToString(dbColumnValue)
+	}
+	// 驱动返回的时间类型为RFC3339格式字符串，需要根据类型格式化
+	if dataType == dbi.DataTypeDateTime {
+		if _, err := time.Parse(time.DateTime, str); err == nil {
+			return str
+		}
+		res, _ := time.Parse(time.RFC3339Nano, str)
+		return res.Format(time.DateTime)
+	}
+	if dataType == dbi.DataTypeDate {
+		if _, err := time.Parse(time.DateOnly, str); err == nil {
+			return str
+		}
+		res, _ := time.Parse(time.RFC3339Nano, str)
+		return res.Format(time.DateOnly)
+	}
+	return str
+}
+
+func (dc *DataHelper) ParseData(dbColumnValue any, dataType dbi.DataType) any {
+	str, ok := dbColumnValue.(string)
+	if !ok || (dataType != dbi.DataTypeDateTime && dataType != dbi.DataTypeDate) {
+		return dbColumnValue
+	}
+	for _, layout := range []string{time.RFC3339Nano, time.DateTime, time.DateOnly} {
+		if res, err := time.Parse(layout, str); err == nil {
+			return res
+		}
+	}
+	return dbColumnValue
+}
+
+func (dc *DataHelper) WrapValue(dbColumnValue any, dataType dbi.DataType) string {
+	if dbColumnValue == nil {
+		return "NULL"
+	}
+	switch dataType {
+	case dbi.DataTypeNumber:
+		return fmt.Sprintf("%v", dbColumnValue)
+	case dbi.DataTypeDate, dbi.DataTypeDateTime:
+		return fmt.Sprintf("'%s'", dc.FormatData(dbColumnValue, dataType))
+	}
+	// clickhouse字符串中的反斜杠与单引号均需要转义
+	val := fmt.Sprintf("%v", dbColumnValue)
+	val = strings.ReplaceAll(val, `\`, `\\`)
+	val = strings.ReplaceAll(val, `'`, `\'`)
+	return fmt.Sprintf("'%s'", val)
+}
+
+type ColumnHelper struct {
+}
+
+func (ch *ColumnHelper) ToCommonColumn(dialectColumn *dbi.Column) {
+	dataType := string(dialectColumn.DataType)
+	baseType := baseTypeName(dataType)
+
+	commonColumnType := commonColumnTypeMap[baseType]
+	if commonColumnType == "" {
+		commonColumnType = dbi.CommonTypeText
+	}
+
+	switch {
+	case commonColumnType == dbi.CommonTypeChar:
+		// FixedString(N)
+		if params := typeParams(dataType); len(params) == 1 {
+			dialectColumn.CharMaxLength = cast.ToInt(params[0])
+		}
+	case commonColumnType == dbi.CommonTypeVarchar:
+		dialectColumn.CharMaxLength = 255
+	case baseType == "Decimal":
+		// Decimal(P, S)
+		if params := typeParams(dataType); len(params) == 2 {
+			dialectColumn.NumPrecision, dialectColumn.NumScale = cast.ToInt(params[0]), cast.ToInt(params[1])
+		}
+	case strings.HasPrefix(baseType, "Decimal"):
+		// Decimal32(S)、Decimal64(S)等，精度由类型决定
+		precisions := map[string]int{"Decimal32": 9, "Decimal64": 18, "Decimal128": 38, "Decimal256": 76}
+		dialectColumn.NumPrecision = precisions[baseType]
+		if params := typeParams(dataType); len(params) == 1 {
+			dialectColumn.NumScale = cast.ToInt(params[0])
+		}
+	case baseType == "UInt64":
+		dialectColumn.NumPrecision, dialectColumn.NumScale = 20, 0
+	case strings.HasPrefix(baseType, "Float"):
+		dialectColumn.NumPrecision, dialectColumn.NumScale = 38, 10
+	case commonColumnType == dbi.CommonTypeNumber:
+		// Int128、Int256等超出常规数据库decimal范围，统一使用最大精度
+		dialectColumn.NumPrecision, dialectColumn.NumScale = 38, 0
+	}
+
+	dialectColumn.DataType = commonColumnType
+}
+
+func (ch *ColumnHelper) ToColumn(column *dbi.Column) {
+	ctype := clickhouseColumnTypeMap[column.DataType]
+	if ctype == "" {
+		ctype = "String"
+	}
+	if ctype == "Decimal" {
+		// 未指定精度的数值类型（如oracle number）使用Float64
+		if column.NumPrecision <= 0 {
+			ctype = "Float64"
+		} else {
+			ctype = fmt.Sprintf("Decimal(%d, %d)", min(column.NumPrecision, 76), min(column.NumScale, column.NumPrecision))
+		}
+	}
+
+	column.DataType = dbi.ColumnDataType(ctype)
+	// clickhouse不支持自增列
+	column.IsIdentity = false
+	ch.FixColumn(column)
+}
+
+func (ch *ColumnHelper) FixColumn(column *dbi.Column) {
+	// clickhouse的字段长度、精度等已包含在类型中，如：Decimal(10, 2)、FixedString(16)
+	column.CharMaxLength = 0
+	column.NumPrecision = 0
+	column.NumScale = 0
+}
+
+type DumpHelper struct {
+	dbi.DefaultDumpHelper
+}
+
+// clickhouse不支持常规事务，导出时无需BEGIN、COMMIT
+func (dh *DumpHelper) BeforeInsert(writer io.Writer, tableName string) {
+}
+
+func (dh *DumpHelper) AfterInsert(writer io.Writer, tableName string, columns []dbi.Column) {
+}
@@ -0,0 +1,59 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"mayfly-go/internal/db/dbm/dbi"
+	"net"
+	"net/url"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+func init() {
+	dbi.Register(dbi.DbTypeClickhouse, new(ClickhouseMeta))
+}
+
+type ClickhouseMeta struct {
+}
+
+func (cm *ClickhouseMeta) GetSqlDb(d *dbi.DbInfo) (*sql.DB, error) {
+	// 设置dsn  -> 更多参数参考：https://github.com/ClickHouse/clickhouse-go#dsn
+	dsnUrl := &url.URL{
+		Scheme:   "clickhouse",
+		User:     url.UserPassword(d.Username, d.Password),
+		Host:     fmt.Sprintf("%s:%d", d.Host, d.Port),
+		Path:     d.GetDatabase(),
+		RawQuery: "dial_timeout=8s",
+	}
+	if d.Params != "" {
+		dsnUrl.RawQuery = fmt.Sprintf("%s&%s", dsnUrl.RawQuery, d.Params)
+	}
+
+	opts, err := clickhouse.ParseDSN(dsnUrl.String())
+	if err != nil {
+		return nil, err
+	}
+
+	// SSH Conect
+	if d.SshTunnelMachineId > 0 {
+		sshTunnelMachine, err := dbi.GetSshTunnel(d.SshTunnelMachineId)
+		if err != nil {
+			return nil, err
+		}
+		opts.DialContext = func(ctx context.Context, addr string) (net.Conn, error) {
+			return sshTunnelMachine.GetDialConn("tcp", addr)
+		}
+	}
+
+	return sql.OpenDB(&connector{clickhouse.Connector(opts)}), nil
+}
+
+func (cm *ClickhouseMeta) GetDialect(conn *dbi.DbConn) dbi.Dialect {
+	return &ClickhouseDialect{dc: conn}
+}
+
+func (cm *ClickhouseMeta) GetMetaData(conn *dbi.DbConn) *dbi.MetaDataX {
+	return dbi.NewMetaDataX(&ClickhouseMetaData{dc: conn})
+}
@@ -0,0 +1,388 @@
+package clickhouse
+
+import (
+	"errors"
+	"fmt"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/utils/stringx"
+	"regexp"
+	"strings"
+
+	"github.com/kanzihuang/vitess/go/vt/sqlparser"
+	"github.com/may-fly/cast"
+)
+
+const (
+	CLICKHOUSE_META_FILE      = "metasql/clickhouse_meta.sql"
+	CLICKHOUSE_DBS            = "CLICKHOUSE_DBS"
+	CLICKHOUSE_TABLE_INFO_KEY = "CLICKHOUSE_TABLE_INFO"
+	CLICKHOUSE_INDEX_INFO_KEY = "CLICKHOUSE_INDEX_INFO"
+	CLICKHOUSE_COLUMN_MA_KEY  = "CLICKHOUSE_COLUMN_MA"
+	CLICKHOUSE_SESSIONS_KEY   = "CLICKHOUSE_SESSIONS"
+)
+
+// clickhouse数据跳数索引类型
+var skipIndexTypes = []string{"minmax", "set", "bloom_filter", "ngrambf_v1", "tokenbf_v1", "inverted", "full_text"}
+
+type ClickhouseMetaData struct {
+	dbi.DefaultMetaData
+
+	dc *dbi.DbConn
+}
+
+func (cd *ClickhouseMetaData) DefaultDb() string {
+	return "default"
+}
+
+func (cd *ClickhouseMetaData) GetDbServer() (*dbi.DbServer, error) {
+	_, res, err := cd.dc.Query("SELECT version() version")
+	if err != nil {
+		return nil, err
+	}
+	ds := &dbi.DbServer{
+		Version: cast.ToString(res[0]["version"]),
+	}
+	return ds, nil
+}
+
+func (cd *ClickhouseMetaData) GetDbNames() ([]string, error) {
+	_, res, err := cd.dc.Query(dbi.GetLocalSql(CLICKHOUSE_META_FILE, CLICKHOUSE_DBS))
+	if err != nil {
+		return nil, err
+	}
+
+	databases := make([]string, 0)
+	for _, re := range res {
+		databases = append(databases, cast.ToString(re["dbname"]))
+	}
+	return databases, nil
+}
+
+func (cd *ClickhouseMetaData) GetTables(tableNames ...string) ([]dbi.Table, error) {
+	meta := cd.dc.GetMetaData()
+	names := strings.Join(collx.ArrayMap[string, string](tableNames, func(val string) string {
+		return meta.QuoteLiteral(meta.RemoveQuote(val))
+	}), ",")
+
+	sql, err := stringx.TemplateParse(dbi.GetLocalSql(CLICKHOUSE_META_FILE, CLICKHOUSE_TABLE_INFO_KEY), collx.M{"tableNames": names})
+	if err != nil {
+		return nil, err
+	}
+
+	_, res, err := cd.dc.Query(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]dbi.Table, 0)
+	for _, re := range res {
+		tables = append(tables, dbi.Table{
+			TableName:    cast.ToString(re["tableName"]),
+			TableComment: cast.ToString(re["tableComment"]),
+			CreateTime:   cast.ToString(re["createTime"]),
+			TableRows:    cast.ToInt(re["tableRows"]),
+			DataLength:   cast.ToInt64(re["dataLength"]),
+			IndexLength:  cast.ToInt64(re["indexLength"]),
+		})
+	}
+	return tables, nil
+}
+
+// 获取列元信息, 如列名等
+func (cd *ClickhouseMetaData) GetColumns(tableNames ...string) ([]dbi.Column, error) {
+	meta := cd.dc.GetMetaData()
+	columnHelper := meta.GetColumnHelper()
+	tableName := strings.Join(collx.ArrayMap[string, string](tableNames, func(val string) string {
+		return meta.QuoteLiteral(meta.RemoveQuote(val))
+	}), ",")
+
+	_, res, err := cd.dc.Query(fmt.Sprintf(dbi.GetLocalSql(CLICKHOUSE_META_FILE, CLICKHOUSE_COLUMN_MA_KEY), tableName))
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]dbi.Column, 0)
+	for _, re := range res {
+		dataType, nullable := stripNullable(cast.ToString(re["columnType"]))
+		column := dbi.Column{
+			TableName:     cast.ToString(re["tableName"]),
+			ColumnName:    cast.ToString(re["columnName"]),
+			DataType:      dbi.ColumnDataType(dataType),
+			ColumnComment: cast.ToString(re["columnComment"]),
+			Nullable:      nullable,
+			IsPrimaryKey:  cast.ToInt(re["isPrimaryKey"]) == 1,
+			CharMaxLength: cast.ToInt(re["charMaxLength"]),
+			NumPrecision:  cast.ToInt(re["numPrecision"]),
+			NumScale:      cast.ToInt(re["numScale"]),
+		}
+		// 只保留普通默认值，MATERIALIZED、ALIAS等为计算列表达式
+		if cast.ToString(re["defaultKind"]) == "DEFAULT" {
+			column.ColumnDefault = cast.ToString(re["columnDefault"])
+		}
+
+		columnHelper.FixColumn(&column)
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// 获取表主键字段名，不存在主键标识则默认第一个字段
+func (cd *ClickhouseMetaData) GetPrimaryKey(tablename string) (string, error) {
+	columns, err := cd.GetColumns(tablename)
+	if err != nil {
+		return "", err
+	}
+	if len(columns) == 0 {
+		return "", errorx.NewBiz("[%s] 表不存在", tablename)
+	}
+
+	for _, v := range columns {
+		if v.IsPrimaryKey {
+			return v.ColumnName, nil
+		}
+	}
+
+	return columns[0].ColumnName, nil
+}
+
+// 获取表索引信息，clickhouse只有数据跳数索引
+func (cd *ClickhouseMetaData) GetTableIndex(tableName string) ([]dbi.Index, error) {
+	_, res, err := cd.dc.Query(dbi.GetLocalSql(CLICKHOUSE_META_FILE, CLICKHOUSE_INDEX_INFO_KEY), tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	indexs := make([]dbi.Index, 0)
+	for _, re := range res {
+		indexs = append(indexs, dbi.Index{
+			IndexName:    cast.ToString(re["indexName"]),
+			ColumnName:   cast.ToString(re["columnName"]),
+			IndexType:    cast.ToString(re["indexType"]),
+			IndexComment: cast.ToString(re["indexComment"]),
+			SeqInIndex:   1,
+		})
+	}
+	return indexs, nil
+}
+
+// 获取建索引ddl，其他数据库的普通索引、唯一索引等统一转为minmax跳数索引
+func (cd *ClickhouseMetaData) GenerateIndexDDL(indexs []dbi.Index, tableInfo dbi.Table) []string {
+	meta := cd.dc.GetMetaData()
+	sqlArr := make([]string, 0)
+	for _, index := range indexs {
+		if index.IsPrimaryKey {
+			continue
+		}
+
+		indexType := index.IndexType
+		if !collx.ArrayContains(skipIndexTypes, baseTypeName(indexType)) {
+			indexType = "minmax"
+		}
+
+		// 索引表达式可能为函数等，如：lower(name)，此时不添加引号
+		expr := index.ColumnName
+		if !strings.Contains(expr, "(") {
+			cols := strings.Split(expr, ",")
+			colNames := make([]string, len(cols))
+			for i, name := range cols {
+				colNames[i] = meta.QuoteIdentifier(strings.TrimSpace(name))
+			}
+			expr = strings.Join(colNames, ",")
+		}
+
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s ADD INDEX %s (%s) TYPE %s GRANULARITY 1",
+			meta.QuoteIdentifier(tableInfo.TableName), meta.QuoteIdentifier(index.IndexName), expr, indexType))
+	}
+	return sqlArr
+}
+
+func (cd *ClickhouseMetaData) genColumnBasicSql(column dbi.Column) string {
+	meta := cd.dc.GetMetaData()
+
+	defVal := "" // 为了防止跨源函数不支持 当默认值是函数时，不需要设置默认值
+	if column.ColumnDefault != "" && !strings.Contains(column.ColumnDefault, "(") {
+		defVal = column.ColumnDefault
+		// 非数值类型且未被引号包裹的默认值需要加引号
+		if new(DataHelper).GetDataType(string(column.DataType)) != dbi.DataTypeNumber && !strings.HasPrefix(defVal, "'") {
+			defVal = meta.QuoteLiteral(defVal)
+		}
+		defVal = " DEFAULT " + defVal
+	}
+
+	comment := ""
+	if column.ColumnComment != "" {
+		// 防止注释内含有特殊字符串导致sql出错
+		comment = fmt.Sprintf(" COMMENT '%s'", meta.QuoteEscape(column.ColumnComment))
+	}
+
+	return fmt.Sprintf(" %s %s%s%s", meta.QuoteIdentifier(column.ColumnName), genColumnType(column), defVal, comment)
+}
+
+// 获取建表ddl，默认使用MergeTree引擎，并以主键列作为排序键
+func (cd *ClickhouseMetaData) GenerateTableDDL(columns []dbi.Column, tableInfo dbi.Table, dropBeforeCreate bool) []string {
+	meta := cd.dc.GetMetaData()
+	sqlArr := make([]string, 0)
+
+	if dropBeforeCreate {
+		sqlArr = append(sqlArr, fmt.Sprintf("DROP TABLE IF EXISTS %s", meta.QuoteIdentifier(tableInfo.TableName)))
+	}
+
+	// 组装建表语句
+	createSql := fmt.Sprintf("CREATE TABLE %s (\n", meta.QuoteIdentifier(tableInfo.TableName))
+	fields := make([]string, 0)
+	pks := make([]string, 0)
+
+	for _, column := range columns {
+		if column.IsPrimaryKey {
+			// 排序键不允许为Nullable
+			column.Nullable = false
+			pks = append(pks, meta.QuoteIdentifier(column.ColumnName))
+		}
+		fields = append(fields, cd.genColumnBasicSql(column))
+	}
+
+	createSql += strings.Join(fields, ",\n")
+	createSql += "\n) ENGINE = MergeTree"
+	if len(pks) > 0 {
+		createSql += fmt.Sprintf("\nORDER BY (%s)", strings.Join(pks, ","))
+	} else {
+		createSql += "\nORDER BY tuple()"
+	}
+
+	// 表注释
+	if tableInfo.TableComment != "" {
+		createSql += fmt.Sprintf("\nCOMMENT '%s'", meta.QuoteEscape(tableInfo.TableComment))
+	}
+
+	sqlArr = append(sqlArr, createSql)
+
+	return sqlArr
+}
+
+// 获取修改表结构ddl，排序键列不支持修改与删除
+func (cd *ClickhouseMetaData) GenerateAlterTableDDL(alterTable dbi.AlterTable) []string {
+	meta := cd.dc.GetMetaData()
+	tbName := meta.QuoteIdentifier(alterTable.Table.TableName)
+	sqlArr := make([]string, 0)
+
+	for _, index := range alterTable.DropIndexs {
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s DROP INDEX %s", tbName, meta.QuoteIdentifier(index.IndexName)))
+	}
+	for _, column := range alterTable.AddColumns {
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s ADD COLUMN%s", tbName, cd.genColumnBasicSql(column)))
+	}
+	for _, modifyColumn := range alterTable.ModifyColumns {
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN%s", tbName, cd.genColumnBasicSql(modifyColumn.NewColumn)))
+	}
+	for _, column := range alterTable.DropColumns {
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", tbName, meta.QuoteIdentifier(column.ColumnName)))
+	}
+
+	return sqlArr
+}
+
+// 获取建表ddl，clickhouse建表语句包含表引擎、分区、排序键等信息，故直接使用SHOW CREATE TABLE
+func (cd *ClickhouseMetaData) GetTableDDL(tableName string, dropBeforeCreate bool) (string, error) {
+	meta := cd.dc.GetMetaData()
+	quoteTableName := meta.QuoteIdentifier(meta.RemoveQuote(tableName))
+	_, res, err := cd.dc.Query(fmt.Sprintf("SHOW CREATE TABLE %s", quoteTableName))
+	if err != nil {
+		return "", err
+	}
+	if len(res) == 0 {
+		return "", errorx.NewBiz("[%s] 表不存在", tableName)
+	}
+
+	ddl := cast.ToString(res[0]["statement"])
+	if dropBeforeCreate {
+		ddl = fmt.Sprintf("DROP TABLE IF EXISTS %s;\n%s", quoteTableName, ddl)
+	}
+	return ddl, nil
+}
+
+func (cd *ClickhouseMetaData) GetSchemas() ([]string, error) {
+	return nil, errors.New("不支持schema")
+}
+
+func (cd *ClickhouseMetaData) GetIdentifierQuoteString() string {
+	return "`"
+}
+
+func (cd *ClickhouseMetaData) QuoteEscape(str string) string {
+	str = strings.ReplaceAll(str, `\`, `\\`)
+	return strings.ReplaceAll(str, `'`, `''`)
+}
+
+func (cd *ClickhouseMetaData) QuoteLiteral(literal string) string {
+	return "'" + cd.QuoteEscape(literal) + "'"
+}
+
+func (cd *ClickhouseMetaData) GetSqlParserDialect() sqlparser.Dialect {
+	return sqlparser.MysqlDialect{}
+}
+
+func (cd *ClickhouseMetaData) GetDataHelper() dbi.DataHelper {
+	return new(DataHelper)
+}
+
+func (cd *ClickhouseMetaData) GetColumnHelper() dbi.ColumnHelper {
+	return new(ColumnHelper)
+}
+
+func (cd *ClickhouseMetaData) GetDumpHelper() dbi.DumpHelper {
+	return new(DumpHelper)
+}
+
+// 去除类型的Nullable包装，并返回是否可为null，如：LowCardinality(Nullable(String)) -> LowCardinality(String), true
+func stripNullable(columnType string) (string, bool) {
+	if strings.HasPrefix(columnType, "Nullable(") {
+		return strings.TrimSuffix(strings.TrimPrefix(columnType, "Nullable("), ")"), true
+	}
+	if strings.HasPrefix(columnType, "LowCardinality(Nullable(") {
+		return "LowCardinality(" + strings.TrimSuffix(strings.TrimPrefix(columnType, "LowCardinality(Nullable("), "))") + ")", true
+	}
+	return columnType, false
+}
+
+// 获取列的完整类型，可为null的列需要使用Nullable包装
+func genColumnType(column dbi.Column) string {
+	dataType := string(column.DataType)
+	if !column.Nullable {
+		return dataType
+	}
+	if strings.HasPrefix(dataType, "LowCardinality(") {
+		return fmt.Sprintf("LowCardinality(Nullable(%s))", unwrapType(dataType))
+	}
+	// 复合类型不能使用Nullable包装
+	if collx.ArrayContains([]string{"Array", "Map", "Tuple", "Nested", "JSON", "Object"}, baseTypeName(dataType)) {
+		return dataType
+	}
+	return fmt.Sprintf("Nullable(%s)", dataType)
+}
+
+// clickhouse查询id，通常为uuid
+var clickhouseQueryIdRegexp = regexp.MustCompile(`^[\w.-]+$`)
+
+// GetSessions clickhouse无持久会话，获取的是正在执行的查询
+func (cd *ClickhouseMetaData) GetSessions() ([]dbi.DbSession, error) {
+	_, res, err := cd.dc.Query(dbi.GetLocalSql(CLICKHOUSE_META_FILE, CLICKHOUSE_SESSIONS_KEY))
+	if err != nil {
+		return nil, err
+	}
+	return dbi.ToDbSessions(res), nil
+}
+
+// KillSession clickhouse无持久会话，终止会话即终止查询
+func (cd *ClickhouseMetaData) KillSession(sessionId string) error {
+	return cd.CancelQuery(sessionId)
+}
+
+func (cd *ClickhouseMetaData) CancelQuery(sessionId string) error {
+	if !clickhouseQueryIdRegexp.MatchString(sessionId) {
+		return errorx.NewBiz("查询id有误: %s", sessionId)
+	}
+	_, err := cd.dc.Exec(fmt.Sprintf("KILL QUERY WHERE query_id = '%s'", sessionId))
+	return err
+}
@@ -95,6 +95,15 @@ func (d *DbConn) ExecContext(ctx context.Context, execSql string, args ...any) (
 	return d.TxExecContext(ctx, nil, execSql, args...)
 }
 
+// 执行 update, insert, delete等sql，并返回执行结果，可用于获取自增主键等信息
+func (d *DbConn) ExecResultContext(ctx context.Context, execSql string, args ...any) (sql.Result, error) {
+	res, err := d.db.ExecContext(ctx, execSql, args...)
+	if err != nil {
+		return nil, wrapSqlError(err)
+	}
+	return res, nil
+}
+
 // 事务执行 update, insert, delete，建表等sql，若tx == nil，则不适用事务
 // 返回影响条数和错误
 func (d *DbConn) TxExecContext(ctx context.Context, tx *sql.Tx, execSql string, args ...any) (int64, error) {
@@ -22,6 +22,7 @@ const (
 	DbTypeMssql      DbType = "mssql"
 	DbTypeKingbaseEs DbType = "kingbaseEs"
 	DbTypeVastbase   DbType = "vastbase"
+	DbTypeClickhouse DbType = "clickhouse"
 )
 
 func ToDbType(dbType string) DbType {
@@ -4,9 +4,13 @@ import (
 	"embed"
 	"fmt"
 	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/errorx"
 	"mayfly-go/pkg/utils/collx"
 	"mayfly-go/pkg/utils/stringx"
+	"strconv"
 	"strings"
+
+	"github.com/may-fly/cast"
 )
 
 // 元数据接口（表、列、等元信息）
@@ -40,6 +44,9 @@ type MetaData interface {
 	// GenerateIndexDDL 生成索引ddl
 	GenerateIndexDDL(indexs []Index, tableInfo Table) []string
 
+	// GenerateAlterTableDDL 生成修改表结构ddl（新增、修改、删除列及删除索引）
+	GenerateAlterTableDDL(alterTable AlterTable) []string
+
 	GetSchemas() ([]string, error)
 
 	// GetDataHelper 获取数据处理助手 用于解析格式化列数据等
@@ -115,6 +122,24 @@ type Index struct {
 	IsPrimaryKey bool   `json:"isPrimaryKey"` // 是否是主键索引，某些情况需要判断并过滤掉主键索引
 }
 
+// 表结构变更信息
+type AlterTable struct {
+	Table         Table          // 表信息
+	AddColumns    []Column       // 新增的列
+	ModifyColumns []ModifyColumn // 修改的列
+	DropColumns   []Column       // 删除的列
+	DropIndexs    []Index        // 删除的索引
+
+	Columns []Column // 修改前表的全部列，供不支持修改列的数据库重建表使用
+	Indexs  []Index  // 修改前表的索引（不含主键）
+}
+
+// 修改的列信息
+type ModifyColumn struct {
+	OldColumn Column // 修改前的列
+	NewColumn Column // 修改后的列
+}
+
 type ColumnDataType string
 
 const (
@@ -212,3 +237,54 @@ func GetLocalSql(file, key string) string {
 	}
 	return resSql
 }
+
+// SessionMetaData 会话管理接口，为MetaData的可选能力，支持查看活跃会话的数据库MetaData实现该接口即可
+type SessionMetaData interface {
+	// GetSessions 获取活跃会话及其正在执行的sql
+	GetSessions() ([]DbSession, error)
+
+	// KillSession 终止指定会话
+	KillSession(sessionId string) error
+
+	// CancelQuery 取消指定会话正在执行的sql，会话保持连接
+	CancelQuery(sessionId string) error
+}
+
+// 数据库会话信息
+type DbSession struct {
+	Id         string `json:"id"`         // 会话id，用于终止会话或取消查询
+	User       string `json:"user"`       // 登录用户
+	Host       string `json:"host"`       // 客户端地址
+	Db         string `json:"db"`         // 当前库
+	State      string `json:"state"`      // 会话状态
+	Sql        string `json:"sql"`        // 正在执行的sql
+	Time       int64  `json:"time"`       // 当前状态持续时间（秒）
+	BlockingId string `json:"blockingId"` // 阻塞该会话的会话id
+}
+
+// ToDbSessions 将查询结果转为会话信息，查询列需使用DbSession对应的json字段名作为别名
+func ToDbSessions(res []map[string]any) []DbSession {
+	sessions := make([]DbSession, 0, len(res))
+	for _, re := range res {
+		sessions = append(sessions, DbSession{
+			Id:         cast.ToString(re["id"]),
+			User:       cast.ToString(re["user"]),
+			Host:       cast.ToString(re["host"]),
+			Db:         cast.ToString(re["db"]),
+			State:      cast.ToString(re["state"]),
+			Sql:        cast.ToString(re["sql"]),
+			Time:       cast.ToInt64(re["time"]),
+			BlockingId: cast.ToString(re["blockingId"]),
+		})
+	}
+	return sessions
+}
+
+// ParseSessionId 解析数字类型的会话id，防止拼接sql时注入
+func ParseSessionId(sessionId string) (int64, error) {
+	id, err := strconv.ParseInt(strings.TrimSpace(sessionId), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errorx.NewBiz("会话id有误: %s", sessionId)
+	}
+	return id, nil
+}
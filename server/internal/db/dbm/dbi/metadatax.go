@@ -2,6 +2,7 @@ package dbi
 
 import (
 	"fmt"
+	"mayfly-go/pkg/errorx"
 	"strings"
 )
 
@@ -22,6 +23,14 @@ func (md *MetaDataX) RemoveQuote(name string) string {
 	return RemoveQuote(md, name)
 }
 
+// GetSessionMetaData 获取会话管理接口，数据库不支持会话管理则返回错误
+func (md *MetaDataX) GetSessionMetaData() (SessionMetaData, error) {
+	if smd, ok := md.MetaData.(SessionMetaData); ok {
+		return smd, nil
+	}
+	return nil, errorx.NewBiz("该数据库类型暂不支持会话管理")
+}
+
 // QuoteIdentifier quotes an "identifier" (e.g. a table or a column name) to be
 // used as part of an SQL statement.  For example:
 //
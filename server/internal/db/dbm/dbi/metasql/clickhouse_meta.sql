@@ -0,0 +1,78 @@
+--CLICKHOUSE_DBS 数据库名信息
+SELECT
+  name AS dbname
+FROM
+  system.databases
+WHERE
+  name NOT IN ('system', 'information_schema', 'INFORMATION_SCHEMA')
+ORDER BY name
+---------------------------------------
+--CLICKHOUSE_TABLE_INFO 表详细信息
+SELECT
+  name tableName,
+  comment tableComment,
+  ifNull(total_rows, 0) tableRows,
+  ifNull(total_bytes, 0) dataLength,
+  0 indexLength,
+  toString(metadata_modification_time) createTime
+FROM
+  system.tables
+WHERE
+  database = currentDatabase()
+  AND is_temporary = 0
+  AND engine NOT IN ('View', 'MaterializedView', 'LiveView', 'WindowView')
+    {{if .tableNames}}
+        AND name IN ({{.tableNames}})
+    {{end}}
+ORDER BY name
+---------------------------------------
+--CLICKHOUSE_INDEX_INFO 索引信息
+SELECT
+  name indexName,
+  expr columnName,
+  type_full indexType,
+  granularity granularity,
+  '' indexComment
+FROM
+  system.data_skipping_indices
+WHERE
+  database = currentDatabase()
+  AND table = ?
+ORDER BY name
+---------------------------------------
+--CLICKHOUSE_COLUMN_MA 列信息元数据
+SELECT
+  table tableName,
+  name columnName,
+  type columnType,
+  default_kind defaultKind,
+  default_expression columnDefault,
+  comment columnComment,
+  is_in_primary_key isPrimaryKey,
+  ifNull(character_octet_length, 0) charMaxLength,
+  ifNull(numeric_precision, 0) numPrecision,
+  ifNull(numeric_scale, 0) numScale
+FROM
+  system.columns
+WHERE
+  database = currentDatabase()
+  AND table IN (%s)
+ORDER BY table,
+         position
+---------------------------------------
+--CLICKHOUSE_SESSIONS 正在执行的查询
+SELECT
+  query_id AS id,
+  user,
+  toString(address) AS host,
+  current_database AS db,
+  'running' AS state,
+  query AS sql,
+  toInt64(elapsed) AS time,
+  '' AS blockingId
+FROM
+  system.processes
+WHERE
+  is_initial_query = 1
+ORDER BY
+  elapsed DESC
@@ -80,4 +80,21 @@ from all_tab_columns a
 where a.owner = (SELECT SF_GET_SCHEMA_NAME_BY_ID(CURRENT_SCHID))
   and a.table_name in (%s)
 order by a.table_name,
-         a.column_id
\ No newline at end of file
+         a.column_id
+---------------------------------------
+--DM_SESSIONS 活跃会话信息
+SELECT
+  s.SESS_ID AS "id",
+  s.USER_NAME AS "user",
+  s.CLNT_IP AS "host",
+  s.CURR_SCH AS "db",
+  s.STATE AS "state",
+  s.SQL_TEXT AS "sql",
+  DATEDIFF(SS, s.LAST_RECV_TIME, SYSDATE) AS "time",
+  (SELECT TOP 1 b.SESS_ID FROM V$TRXWAIT w JOIN V$SESSIONS b ON b.TRX_ID = w.WAIT_FOR_ID WHERE w.ID = s.TRX_ID) AS "blockingId"
+FROM
+  V$SESSIONS s
+WHERE
+  s.SESS_ID != SESSID()
+ORDER BY
+  "time" DESC
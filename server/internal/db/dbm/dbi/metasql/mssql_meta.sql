@@ -97,3 +97,23 @@ FROM sys.tables t
 WHERE ss.name = ?
   and t.name in (%s)
 ORDER BY t.name, c.column_id
+---------------------------------------
+--MSSQL_SESSIONS 活跃会话信息
+SELECT
+  s.session_id AS id,
+  s.login_name AS [user],
+  s.host_name AS host,
+  DB_NAME(COALESCE(r.database_id, s.database_id)) AS db,
+  COALESCE(r.status, s.status) AS state,
+  t.text AS [sql],
+  DATEDIFF(SECOND, COALESCE(r.start_time, s.last_request_start_time), GETDATE()) AS [time],
+  NULLIF(r.blocking_session_id, 0) AS blockingId
+FROM
+  sys.dm_exec_sessions s
+  LEFT JOIN sys.dm_exec_requests r ON r.session_id = s.session_id
+  OUTER APPLY sys.dm_exec_sql_text(r.sql_handle) t
+WHERE
+  s.is_user_process = 1
+  AND s.session_id != @@SPID
+ORDER BY
+  [time] DESC
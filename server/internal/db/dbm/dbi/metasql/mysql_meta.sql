@@ -75,4 +75,27 @@ FROM information_schema.COLUMNS
 WHERE table_schema = (SELECT DATABASE())
   AND table_name IN (%s)
 ORDER BY table_name,
-         ordinal_position
\ No newline at end of file
+         ordinal_position
+---------------------------------------
+--MYSQL_SESSIONS 活跃会话信息（同SHOW FULL PROCESSLIST）
+SELECT
+  ID `id`,
+  USER `user`,
+  HOST `host`,
+  DB `db`,
+  CONCAT_WS(': ', COMMAND, NULLIF(STATE, '')) `state`,
+  INFO `sql`,
+  TIME `time`
+FROM
+  information_schema.PROCESSLIST
+WHERE
+  ID != CONNECTION_ID()
+ORDER BY
+  TIME DESC
+---------------------------------------
+--MYSQL_SESSION_LOCK_WAITS innodb锁等待信息
+SELECT
+  waiting_pid waitingId,
+  blocking_pid blockingId
+FROM
+  sys.innodb_lock_waits
@@ -73,3 +73,23 @@ FROM ALL_TAB_COLUMNS a
 WHERE a.OWNER = (SELECT sys_context('USERENV', 'CURRENT_SCHEMA') FROM DUAL)
   AND a.TABLE_NAME in (%s)
 order by a.COLUMN_ID
+---------------------------------------
+--ORACLE_SESSIONS 活跃会话信息
+SELECT
+  s.SID || ',' || s.SERIAL# AS "id",
+  s.USERNAME AS "user",
+  s.MACHINE AS "host",
+  s.SCHEMANAME AS "db",
+  s.STATUS AS "state",
+  q.SQL_TEXT AS "sql",
+  s.LAST_CALL_ET AS "time",
+  CASE WHEN b.SID IS NOT NULL THEN b.SID || ',' || b.SERIAL# END AS "blockingId"
+FROM
+  v$session s
+  LEFT JOIN v$sql q ON q.SQL_ID = s.SQL_ID AND q.CHILD_NUMBER = s.SQL_CHILD_NUMBER
+  LEFT JOIN v$session b ON b.SID = s.BLOCKING_SESSION
+WHERE
+  s.TYPE = 'USER'
+  AND s.SID != SYS_CONTEXT('USERENV', 'SID')
+ORDER BY
+  s.LAST_CALL_ET DESC
@@ -68,3 +68,21 @@ FROM information_schema.columns a
 WHERE a.table_schema = (select current_schema())
   and a.table_name in (%s)
 order by a.table_name, a.ordinal_position
+---------------------------------------
+--PGSQL_SESSIONS 活跃会话信息
+SELECT
+  pid AS "id",
+  usename AS "user",
+  client_addr AS "host",
+  datname AS "db",
+  state AS "state",
+  query AS "sql",
+  EXTRACT(EPOCH FROM (now() - COALESCE(state_change, backend_start)))::bigint AS "time",
+  %s AS "blockingId"
+FROM
+  pg_stat_activity
+WHERE
+  pid <> pg_backend_pid()
+  AND datname IS NOT NULL
+ORDER BY
+  "time" DESC
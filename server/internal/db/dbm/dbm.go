@@ -3,6 +3,7 @@ package dbm
 import (
 	"fmt"
 	"mayfly-go/internal/common/consts"
+	_ "mayfly-go/internal/db/dbm/clickhouse"
 	"mayfly-go/internal/db/dbm/dbi"
 	_ "mayfly-go/internal/db/dbm/dm"
 	_ "mayfly-go/internal/db/dbm/mssql"
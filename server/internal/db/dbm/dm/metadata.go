@@ -19,6 +19,7 @@ const (
 	DM_TABLE_INFO_KEY = "DM_TABLE_INFO"
 	DM_INDEX_INFO_KEY = "DM_INDEX_INFO"
 	DM_COLUMN_MA_KEY  = "DM_COLUMN_MA"
+	DM_SESSIONS_KEY   = "DM_SESSIONS"
 )
 
 type DMMetaData struct {
@@ -281,6 +282,51 @@ func (dd *DMMetaData) GenerateTableDDL(columns []dbi.Column, tableInfo dbi.Table
 	return sqlArr
 }
 
+func (dd *DMMetaData) GenerateAlterTableDDL(alterTable dbi.AlterTable) []string {
+	meta := dd.dc.GetMetaData()
+	tbName := meta.QuoteIdentifier(alterTable.Table.TableName)
+	sqlArr := make([]string, 0)
+	columnComments := make([]string, 0)
+
+	for _, index := range alterTable.DropIndexs {
+		sqlArr = append(sqlArr, fmt.Sprintf("drop index %s", meta.QuoteIdentifier(index.IndexName)))
+	}
+
+	for _, column := range alterTable.AddColumns {
+		sqlArr = append(sqlArr, fmt.Sprintf("alter table %s add column%s", tbName, dd.genColumnBasicSql(column)))
+		if column.ColumnComment != "" {
+			columnComments = append(columnComments, fmt.Sprintf("comment on column %s.%s is '%s'", tbName, meta.QuoteIdentifier(column.ColumnName), meta.QuoteEscape(column.ColumnComment)))
+		}
+	}
+
+	for _, modifyColumn := range alterTable.ModifyColumns {
+		oldColumn, newColumn := modifyColumn.OldColumn, modifyColumn.NewColumn
+		colName := meta.QuoteIdentifier(newColumn.ColumnName)
+
+		// 空值约束单独修改，modify时不指定
+		column := newColumn
+		column.Nullable = true
+		column.IsIdentity = false
+		sqlArr = append(sqlArr, fmt.Sprintf("alter table %s modify%s", tbName, dd.genColumnBasicSql(column)))
+		if oldColumn.Nullable != newColumn.Nullable {
+			if newColumn.Nullable {
+				sqlArr = append(sqlArr, fmt.Sprintf("alter table %s alter column %s set null", tbName, colName))
+			} else {
+				sqlArr = append(sqlArr, fmt.Sprintf("alter table %s alter column %s set not null", tbName, colName))
+			}
+		}
+		if oldColumn.ColumnComment != newColumn.ColumnComment {
+			columnComments = append(columnComments, fmt.Sprintf("comment on column %s.%s is '%s'", tbName, colName, meta.QuoteEscape(newColumn.ColumnComment)))
+		}
+	}
+
+	for _, column := range alterTable.DropColumns {
+		sqlArr = append(sqlArr, fmt.Sprintf("alter table %s drop column %s", tbName, meta.QuoteIdentifier(column.ColumnName)))
+	}
+
+	return append(sqlArr, columnComments...)
+}
+
 // 获取建表ddl
 func (dd *DMMetaData) GetTableDDL(tableName string, dropBeforeCreate bool) (string, error) {
 
@@ -337,3 +383,29 @@ func (dd *DMMetaData) GetColumnHelper() dbi.ColumnHelper {
 func (dd *DMMetaData) GetDumpHelper() dbi.DumpHelper {
 	return new(DumpHelper)
 }
+
+func (dd *DMMetaData) GetSessions() ([]dbi.DbSession, error) {
+	_, res, err := dd.dc.Query(dbi.GetLocalSql(DM_META_FILE, DM_SESSIONS_KEY))
+	if err != nil {
+		return nil, err
+	}
+	return dbi.ToDbSessions(res), nil
+}
+
+func (dd *DMMetaData) KillSession(sessionId string) error {
+	id, err := dbi.ParseSessionId(sessionId)
+	if err != nil {
+		return err
+	}
+	_, err = dd.dc.Exec(fmt.Sprintf("CALL SP_CLOSE_SESSION(%d)", id))
+	return err
+}
+
+func (dd *DMMetaData) CancelQuery(sessionId string) error {
+	id, err := dbi.ParseSessionId(sessionId)
+	if err != nil {
+		return err
+	}
+	_, err = dd.dc.Exec(fmt.Sprintf("CALL SP_CANCEL_SESSION_OPERATION(%d)", id))
+	return err
+}
@@ -20,6 +20,7 @@ const (
 	MSSQL_TABLE_INFO_KEY = "MSSQL_TABLE_INFO"
 	MSSQL_INDEX_INFO_KEY = "MSSQL_INDEX_INFO"
 	MSSQL_COLUMN_MA_KEY  = "MSSQL_COLUMN_MA"
+	MSSQL_SESSIONS_KEY   = "MSSQL_SESSIONS"
 )
 
 type MssqlMetaData struct {
@@ -361,6 +362,54 @@ func (md *MssqlMetaData) GenerateTableDDL(columns []dbi.Column, tableInfo dbi.Ta
 	return sqlArr
 }
 
+// 获取修改表结构ddl
+func (md *MssqlMetaData) GenerateAlterTableDDL(alterTable dbi.AlterTable) []string {
+	tbName := alterTable.Table.TableName
+	schemaName := md.dc.Info.CurrentSchema()
+	meta := md.dc.GetMetaData()
+	quoteTableName := fmt.Sprintf("%s.%s", meta.QuoteIdentifier(schemaName), meta.QuoteIdentifier(tbName))
+
+	sqlArr := make([]string, 0)
+	columnComments := make([]string, 0)
+	addCommentTmp := "EXECUTE sp_addextendedproperty N'MS_Description', N'%s', N'SCHEMA', N'%s', N'TABLE', N'%s', N'COLUMN', N'%s'"
+	updateCommentTmp := "EXECUTE sp_updateextendedproperty N'MS_Description', N'%s', N'SCHEMA', N'%s', N'TABLE', N'%s', N'COLUMN', N'%s'"
+
+	for _, index := range alterTable.DropIndexs {
+		sqlArr = append(sqlArr, fmt.Sprintf("DROP INDEX %s ON %s", meta.QuoteIdentifier(index.IndexName), quoteTableName))
+	}
+
+	for _, column := range alterTable.AddColumns {
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s ADD%s", quoteTableName, md.genColumnBasicSql(column)))
+		if column.ColumnComment != "" {
+			columnComments = append(columnComments, fmt.Sprintf(addCommentTmp, meta.QuoteEscape(column.ColumnComment), schemaName, tbName, column.ColumnName))
+		}
+	}
+
+	// mssql的默认值为约束，alter column不支持修改默认值
+	for _, modifyColumn := range alterTable.ModifyColumns {
+		oldColumn, newColumn := modifyColumn.OldColumn, modifyColumn.NewColumn
+		nullAble := " NULL"
+		if !newColumn.Nullable {
+			nullAble = " NOT NULL"
+		}
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s%s", quoteTableName, meta.QuoteIdentifier(newColumn.ColumnName), newColumn.GetColumnType(), nullAble))
+
+		if oldColumn.ColumnComment != newColumn.ColumnComment {
+			commentTmp := updateCommentTmp
+			if oldColumn.ColumnComment == "" {
+				commentTmp = addCommentTmp
+			}
+			columnComments = append(columnComments, fmt.Sprintf(commentTmp, meta.QuoteEscape(newColumn.ColumnComment), schemaName, tbName, newColumn.ColumnName))
+		}
+	}
+
+	for _, column := range alterTable.DropColumns {
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", quoteTableName, meta.QuoteIdentifier(column.ColumnName)))
+	}
+
+	return append(sqlArr, columnComments...)
+}
+
 // 获取建表ddl
 func (md *MssqlMetaData) GetTableDDL(tableName string, dropBeforeCreate bool) (string, error) {
 
@@ -420,3 +469,25 @@ func (md *MssqlMetaData) GetColumnHelper() dbi.ColumnHelper {
 func (md *MssqlMetaData) GetDumpHelper() dbi.DumpHelper {
 	return new(DumpHelper)
 }
+
+func (md *MssqlMetaData) GetSessions() ([]dbi.DbSession, error) {
+	_, res, err := md.dc.Query(dbi.GetLocalSql(MSSQL_META_FILE, MSSQL_SESSIONS_KEY))
+	if err != nil {
+		return nil, err
+	}
+	return dbi.ToDbSessions(res), nil
+}
+
+func (md *MssqlMetaData) KillSession(sessionId string) error {
+	id, err := dbi.ParseSessionId(sessionId)
+	if err != nil {
+		return err
+	}
+	_, err = md.dc.Exec(fmt.Sprintf("KILL %d", id))
+	return err
+}
+
+// CancelQuery mssql无法取消其他会话正在执行的sql，只能终止会话
+func (md *MssqlMetaData) CancelQuery(sessionId string) error {
+	return errorx.NewBiz("mssql不支持取消其他会话的sql，请直接终止会话")
+}
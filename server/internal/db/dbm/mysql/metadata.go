@@ -20,6 +20,9 @@ const (
 	MYSQL_TABLE_INFO_KEY = "MYSQL_TABLE_INFO"
 	MYSQL_INDEX_INFO_KEY = "MYSQL_INDEX_INFO"
 	MYSQL_COLUMN_MA_KEY  = "MYSQL_COLUMN_MA"
+
+	MYSQL_SESSIONS_KEY           = "MYSQL_SESSIONS"
+	MYSQL_SESSION_LOCK_WAITS_KEY = "MYSQL_SESSION_LOCK_WAITS"
 )
 
 type MysqlMetaData struct {
@@ -286,6 +289,28 @@ func (md *MysqlMetaData) GenerateTableDDL(columns []dbi.Column, tableInfo dbi.Ta
 	return sqlArr
 }
 
+// 获取修改表结构ddl
+func (md *MysqlMetaData) GenerateAlterTableDDL(alterTable dbi.AlterTable) []string {
+	meta := md.dc.GetMetaData()
+	tbName := meta.QuoteIdentifier(alterTable.Table.TableName)
+	sqlArr := make([]string, 0)
+
+	for _, index := range alterTable.DropIndexs {
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s DROP INDEX %s", tbName, meta.QuoteIdentifier(index.IndexName)))
+	}
+	for _, column := range alterTable.AddColumns {
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s ADD COLUMN%s", tbName, md.genColumnBasicSql(column)))
+	}
+	for _, modifyColumn := range alterTable.ModifyColumns {
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN%s", tbName, md.genColumnBasicSql(modifyColumn.NewColumn)))
+	}
+	for _, column := range alterTable.DropColumns {
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", tbName, meta.QuoteIdentifier(column.ColumnName)))
+	}
+
+	return sqlArr
+}
+
 // 获取建表ddl
 func (md *MysqlMetaData) GetTableDDL(tableName string, dropBeforeCreate bool) (string, error) {
 	// 1.获取表信息
@@ -341,3 +366,44 @@ func (md *MysqlMetaData) GetDataHelper() dbi.DataHelper {
 func (md *MysqlMetaData) GetColumnHelper() dbi.ColumnHelper {
 	return new(ColumnHelper)
 }
+
+func (md *MysqlMetaData) GetSessions() ([]dbi.DbSession, error) {
+	_, res, err := md.dc.Query(dbi.GetLocalSql(MYSQL_META_FILE, MYSQL_SESSIONS_KEY))
+	if err != nil {
+		return nil, err
+	}
+	sessions := dbi.ToDbSessions(res)
+
+	// 锁等待信息依赖sys库（mysql5.7+），获取失败则忽略
+	_, lockWaits, err := md.dc.Query(dbi.GetLocalSql(MYSQL_META_FILE, MYSQL_SESSION_LOCK_WAITS_KEY))
+	if err != nil {
+		logx.Debugf("获取mysql锁等待信息失败: %s", err.Error())
+		return sessions, nil
+	}
+	blockings := make(map[string]string, len(lockWaits))
+	for _, lw := range lockWaits {
+		blockings[cast.ToString(lw["waitingId"])] = cast.ToString(lw["blockingId"])
+	}
+	for i := range sessions {
+		sessions[i].BlockingId = blockings[sessions[i].Id]
+	}
+	return sessions, nil
+}
+
+func (md *MysqlMetaData) KillSession(sessionId string) error {
+	id, err := dbi.ParseSessionId(sessionId)
+	if err != nil {
+		return err
+	}
+	_, err = md.dc.Exec(fmt.Sprintf("KILL %d", id))
+	return err
+}
+
+func (md *MysqlMetaData) CancelQuery(sessionId string) error {
+	id, err := dbi.ParseSessionId(sessionId)
+	if err != nil {
+		return err
+	}
+	_, err = md.dc.Exec(fmt.Sprintf("KILL QUERY %d", id))
+	return err
+}
@@ -7,6 +7,7 @@ import (
 	"mayfly-go/pkg/logx"
 	"mayfly-go/pkg/utils/collx"
 	"mayfly-go/pkg/utils/stringx"
+	"regexp"
 	"strings"
 
 	"github.com/may-fly/cast"
@@ -19,6 +20,7 @@ const (
 	ORACLE_TABLE_INFO_KEY = "ORACLE_TABLE_INFO"
 	ORACLE_INDEX_INFO_KEY = "ORACLE_INDEX_INFO"
 	ORACLE_COLUMN_MA_KEY  = "ORACLE_COLUMN_MA"
+	ORACLE_SESSIONS_KEY   = "ORACLE_SESSIONS"
 )
 
 type OracleMetaData struct {
@@ -310,6 +312,53 @@ end`
 	return sqlArr
 }
 
+// 获取修改表结构ddl
+func (od *OracleMetaData) GenerateAlterTableDDL(alterTable dbi.AlterTable) []string {
+	meta := od.dc.GetMetaData()
+	quoteTableName := meta.QuoteIdentifier(alterTable.Table.TableName)
+	sqlArr := make([]string, 0)
+	columnComments := make([]string, 0)
+	commentTmp := "COMMENT ON COLUMN %s.%s IS '%s'"
+
+	for _, index := range alterTable.DropIndexs {
+		sqlArr = append(sqlArr, fmt.Sprintf("DROP INDEX %s", meta.QuoteIdentifier(index.IndexName)))
+	}
+
+	for _, column := range alterTable.AddColumns {
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s ADD (%s)", quoteTableName, od.genColumnBasicSql(column)))
+		if column.ColumnComment != "" {
+			columnComments = append(columnComments, fmt.Sprintf(commentTmp, quoteTableName, meta.QuoteIdentifier(column.ColumnName), meta.QuoteEscape(column.ColumnComment)))
+		}
+	}
+
+	for _, modifyColumn := range alterTable.ModifyColumns {
+		oldColumn, newColumn := modifyColumn.OldColumn, modifyColumn.NewColumn
+		// oracle修改为与原来相同的空值约束会报错(ORA-01442)，故只有空值约束变化时才指定
+		nullAble := ""
+		if oldColumn.Nullable != newColumn.Nullable {
+			if newColumn.Nullable {
+				nullAble = " NULL"
+			} else {
+				nullAble = " NOT NULL"
+			}
+		}
+		defVal := " DEFAULT NULL"
+		if newColumn.ColumnDefault != "" {
+			defVal = fmt.Sprintf(" DEFAULT %v", newColumn.ColumnDefault)
+		}
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s MODIFY (%s %s%s%s)", quoteTableName, meta.QuoteIdentifier(newColumn.ColumnName), newColumn.GetColumnType(), defVal, nullAble))
+		if oldColumn.ColumnComment != newColumn.ColumnComment {
+			columnComments = append(columnComments, fmt.Sprintf(commentTmp, quoteTableName, meta.QuoteIdentifier(newColumn.ColumnName), meta.QuoteEscape(newColumn.ColumnComment)))
+		}
+	}
+
+	for _, column := range alterTable.DropColumns {
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", quoteTableName, meta.QuoteIdentifier(column.ColumnName)))
+	}
+
+	return append(sqlArr, columnComments...)
+}
+
 // 获取建表ddl
 func (od *OracleMetaData) GetTableDDL(tableName string, dropBeforeCreate bool) (string, error) {
 
@@ -362,3 +411,31 @@ func (od *OracleMetaData) GetDataHelper() dbi.DataHelper {
 func (od *OracleMetaData) GetColumnHelper() dbi.ColumnHelper {
 	return new(ColumnHelper)
 }
+
+// oracle会话id格式为 sid,serial#
+var oracleSessionIdRegexp = regexp.MustCompile(`^\d+,\d+$`)
+
+func (od *OracleMetaData) GetSessions() ([]dbi.DbSession, error) {
+	_, res, err := od.dc.Query(dbi.GetLocalSql(ORACLE_META_FILE, ORACLE_SESSIONS_KEY))
+	if err != nil {
+		return nil, err
+	}
+	return dbi.ToDbSessions(res), nil
+}
+
+func (od *OracleMetaData) KillSession(sessionId string) error {
+	if !oracleSessionIdRegexp.MatchString(sessionId) {
+		return errorx.NewBiz("会话id有误: %s", sessionId)
+	}
+	_, err := od.dc.Exec(fmt.Sprintf("ALTER SYSTEM KILL SESSION '%s' IMMEDIATE", sessionId))
+	return err
+}
+
+// CancelQuery 取消会话正在执行的sql，需oracle 18c及以上版本
+func (od *OracleMetaData) CancelQuery(sessionId string) error {
+	if !oracleSessionIdRegexp.MatchString(sessionId) {
+		return errorx.NewBiz("会话id有误: %s", sessionId)
+	}
+	_, err := od.dc.Exec(fmt.Sprintf("ALTER SYSTEM CANCEL SQL '%s'", sessionId))
+	return err
+}
@@ -19,6 +19,7 @@ const (
 	PGSQL_TABLE_INFO_KEY = "PGSQL_TABLE_INFO"
 	PGSQL_INDEX_INFO_KEY = "PGSQL_INDEX_INFO"
 	PGSQL_COLUMN_MA_KEY  = "PGSQL_COLUMN_MA"
+	PGSQL_SESSIONS_KEY   = "PGSQL_SESSIONS"
 )
 
 type PgsqlMetaData struct {
@@ -186,7 +187,7 @@ func (pd *PgsqlMetaData) GenerateIndexDDL(indexs []dbi.Index, tableInfo dbi.Tabl
 		}
 
 		// 如果索引名存在，先删除索引
-		drops = append(drops, fmt.Sprintf("drop index if exists %s.%s", pd.dc.Info.CurrentSchema(), index.IndexName))
+		drops = append(drops, fmt.Sprintf("drop index if exists %s.%s", meta.QuoteIdentifier(pd.dc.Info.CurrentSchema()), meta.QuoteIdentifier(index.IndexName)))
 
 		// 取出列名，添加引号
 		cols := strings.Split(index.ColumnName, ",")
@@ -337,6 +338,57 @@ func (pd *PgsqlMetaData) GenerateTableDDL(columns []dbi.Column, tableInfo dbi.Ta
 	return sqlArr
 }
 
+func (pd *PgsqlMetaData) GenerateAlterTableDDL(alterTable dbi.AlterTable) []string {
+	meta := pd.dc.GetMetaData()
+	quoteTableName := meta.QuoteIdentifier(alterTable.Table.TableName)
+	sqlArr := make([]string, 0)
+	commentTmp := "comment on column %s.%s is '%s'"
+
+	for _, index := range alterTable.DropIndexs {
+		sqlArr = append(sqlArr, fmt.Sprintf("drop index if exists %s.%s", meta.QuoteIdentifier(pd.dc.Info.CurrentSchema()), meta.QuoteIdentifier(index.IndexName)))
+	}
+
+	for _, column := range alterTable.AddColumns {
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s ADD COLUMN%s", quoteTableName, pd.genColumnBasicSql(column)))
+		if column.ColumnComment != "" {
+			sqlArr = append(sqlArr, fmt.Sprintf(commentTmp, quoteTableName, meta.QuoteIdentifier(column.ColumnName), meta.QuoteEscape(column.ColumnComment)))
+		}
+	}
+
+	// pgsql需对类型、空值、默认值、注释分别修改
+	for _, modifyColumn := range alterTable.ModifyColumns {
+		oldColumn, newColumn := modifyColumn.OldColumn, modifyColumn.NewColumn
+		alterColumnSql := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", quoteTableName, meta.QuoteIdentifier(newColumn.ColumnName))
+
+		if oldColumn.GetColumnType() != newColumn.GetColumnType() {
+			sqlArr = append(sqlArr, fmt.Sprintf("%s TYPE %s", alterColumnSql, newColumn.GetColumnType()))
+		}
+		if oldColumn.Nullable != newColumn.Nullable {
+			if newColumn.Nullable {
+				sqlArr = append(sqlArr, fmt.Sprintf("%s DROP NOT NULL", alterColumnSql))
+			} else {
+				sqlArr = append(sqlArr, fmt.Sprintf("%s SET NOT NULL", alterColumnSql))
+			}
+		}
+		if oldColumn.ColumnDefault != newColumn.ColumnDefault {
+			if newColumn.ColumnDefault == "" {
+				sqlArr = append(sqlArr, fmt.Sprintf("%s DROP DEFAULT", alterColumnSql))
+			} else {
+				sqlArr = append(sqlArr, fmt.Sprintf("%s SET DEFAULT %s", alterColumnSql, newColumn.ColumnDefault))
+			}
+		}
+		if oldColumn.ColumnComment != newColumn.ColumnComment {
+			sqlArr = append(sqlArr, fmt.Sprintf(commentTmp, quoteTableName, meta.QuoteIdentifier(newColumn.ColumnName), meta.QuoteEscape(newColumn.ColumnComment)))
+		}
+	}
+
+	for _, column := range alterTable.DropColumns {
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", quoteTableName, meta.QuoteIdentifier(column.ColumnName)))
+	}
+
+	return sqlArr
+}
+
 // 获取建表ddl
 func (pd *PgsqlMetaData) GetTableDDL(tableName string, dropBeforeCreate bool) (string, error) {
 
@@ -419,3 +471,40 @@ func (pd *PgsqlMetaData) GetColumnHelper() dbi.ColumnHelper {
 func (pd *PgsqlMetaData) GetDumpHelper() dbi.DumpHelper {
 	return new(DumpHelper)
 }
+
+func (pd *PgsqlMetaData) GetSessions() ([]dbi.DbSession, error) {
+	// gauss、vastbase不支持pg_blocking_pids函数
+	blockingExpr := "array_to_string(pg_blocking_pids(pid), ',')"
+	if dbType := pd.dc.Info.Type; dbType == dbi.DbTypeGauss || dbType == dbi.DbTypeVastbase {
+		blockingExpr = "''"
+	}
+	_, res, err := pd.dc.Query(fmt.Sprintf(dbi.GetLocalSql(PGSQL_META_FILE, PGSQL_SESSIONS_KEY), blockingExpr))
+	if err != nil {
+		return nil, err
+	}
+	return dbi.ToDbSessions(res), nil
+}
+
+func (pd *PgsqlMetaData) KillSession(sessionId string) error {
+	return pd.execSessionFunc("pg_terminate_backend", sessionId)
+}
+
+func (pd *PgsqlMetaData) CancelQuery(sessionId string) error {
+	return pd.execSessionFunc("pg_cancel_backend", sessionId)
+}
+
+// 执行pg_terminate_backend、pg_cancel_backend等会话管理函数
+func (pd *PgsqlMetaData) execSessionFunc(funcName string, sessionId string) error {
+	pid, err := dbi.ParseSessionId(sessionId)
+	if err != nil {
+		return err
+	}
+	_, res, err := pd.dc.Query(fmt.Sprintf("SELECT %s(%d) AS res", funcName, pid))
+	if err != nil {
+		return err
+	}
+	if len(res) == 0 || !cast.ToBool(res[0]["res"]) {
+		return errorx.NewBiz("会话[%d]不存在或无权限操作该会话", pid)
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"mayfly-go/pkg/utils/collx"
 	"mayfly-go/pkg/utils/stringx"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/may-fly/cast"
@@ -286,6 +287,93 @@ func (sd *SqliteMetaData) GenerateTableDDL(columns []dbi.Column, tableInfo dbi.T
 	return sqlArr
 }
 
+// 获取修改表结构ddl
+func (sd *SqliteMetaData) GenerateAlterTableDDL(alterTable dbi.AlterTable) []string {
+	// sqlite不支持修改列定义，需重建表
+	if len(alterTable.ModifyColumns) > 0 {
+		return sd.genRebuildTableDDL(alterTable)
+	}
+
+	meta := sd.dc.GetMetaData()
+	tbName := meta.QuoteIdentifier(alterTable.Table.TableName)
+	sqlArr := make([]string, 0)
+
+	for _, index := range alterTable.DropIndexs {
+		sqlArr = append(sqlArr, fmt.Sprintf("DROP INDEX IF EXISTS %s", meta.QuoteIdentifier(index.IndexName)))
+	}
+
+	for _, column := range alterTable.AddColumns {
+		// sqlite新增列不能为主键
+		column.IsPrimaryKey = false
+		column.IsIdentity = false
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s ADD COLUMN%s", tbName, sd.genColumnBasicSql(column)))
+	}
+
+	for _, column := range alterTable.DropColumns {
+		sqlArr = append(sqlArr, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", tbName, meta.QuoteIdentifier(column.ColumnName)))
+	}
+
+	return sqlArr
+}
+
+// 生成重建表ddl：按修改后的列新建临时表 -> 迁移数据 -> 删除原表 -> 临时表重命名 -> 重建保留的索引
+func (sd *SqliteMetaData) genRebuildTableDDL(alterTable dbi.AlterTable) []string {
+	meta := sd.dc.GetMetaData()
+	tableName := alterTable.Table.TableName
+	tmpTableName := fmt.Sprintf("_%s_tmp", tableName)
+
+	dropColumns := make(map[string]bool)
+	for _, column := range alterTable.DropColumns {
+		dropColumns[strings.ToLower(column.ColumnName)] = true
+	}
+	modifyColumns := make(map[string]dbi.Column)
+	for _, modifyColumn := range alterTable.ModifyColumns {
+		modifyColumns[strings.ToLower(modifyColumn.NewColumn.ColumnName)] = modifyColumn.NewColumn
+	}
+
+	columns := make([]dbi.Column, 0)
+	copyColumns := make([]string, 0)
+	for _, column := range alterTable.Columns {
+		key := strings.ToLower(column.ColumnName)
+		if dropColumns[key] {
+			continue
+		}
+		if newColumn, ok := modifyColumns[key]; ok {
+			column = newColumn
+		}
+		columns = append(columns, column)
+		copyColumns = append(copyColumns, meta.QuoteIdentifier(column.ColumnName))
+	}
+	columns = append(columns, alterTable.AddColumns...)
+
+	sqlArr := sd.GenerateTableDDL(columns, dbi.Table{TableName: tmpTableName}, true)
+	quoteColumns := strings.Join(copyColumns, ", ")
+	sqlArr = append(sqlArr,
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", meta.QuoteIdentifier(tmpTableName), quoteColumns, quoteColumns, meta.QuoteIdentifier(tableName)),
+		fmt.Sprintf("DROP TABLE %s", meta.QuoteIdentifier(tableName)),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", meta.QuoteIdentifier(tmpTableName), meta.QuoteIdentifier(tableName)),
+	)
+
+	// 原表索引随表删除，需重建未被删除且不包含已删除列的索引
+	dropIndexs := make(map[string]bool)
+	for _, index := range alterTable.DropIndexs {
+		dropIndexs[strings.ToLower(index.IndexName)] = true
+	}
+	indexs := collx.ArrayFilter(alterTable.Indexs, func(index dbi.Index) bool {
+		if dropIndexs[strings.ToLower(index.IndexName)] {
+			return false
+		}
+		return !slices.ContainsFunc(strings.Split(index.ColumnName, ","), func(col string) bool {
+			return dropColumns[strings.ToLower(strings.TrimSpace(col))]
+		})
+	})
+	if len(indexs) > 0 {
+		sqlArr = append(sqlArr, sd.GenerateIndexDDL(indexs, alterTable.Table)...)
+	}
+
+	return sqlArr
+}
+
 // 获取建表ddl
 func (sd *SqliteMetaData) GetTableDDL(tableName string, dropBeforeCreate bool) (string, error) {
 	var builder strings.Builder
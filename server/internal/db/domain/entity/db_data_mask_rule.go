@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"mayfly-go/pkg/model"
+)
+
+// 数据脱敏规则，通过标签关联数据库
+type DbDataMaskRule struct {
+	model.Model
+
+	Name          string              `json:"name"`
+	Db            string              `json:"db"`            // 库名，为空则匹配所有库
+	Table         string              `json:"table"`         // 表名，为空则匹配所有表
+	Columns       model.Slice[string] `json:"columns"`       // 需要脱敏的列名
+	MaskType      DataMaskType        `json:"maskType"`      // 脱敏方式
+	Pattern       string              `json:"pattern"`       // 正则脱敏时匹配的正则表达式
+	Replacement   string              `json:"replacement"`   // 正则脱敏时的替换内容
+	RowColumn     string              `json:"rowColumn"`     // 行级脱敏条件列，为空则所有行均脱敏
+	RowPattern    string              `json:"rowPattern"`    // 行级脱敏条件列值需匹配的正则表达式
+	ExemptRoleIds model.Slice[int]    `json:"exemptRoleIds"` // 豁免脱敏的角色id
+	Status        int8                `json:"status"`        // 状态
+	Remark        string              `json:"remark"`
+}
+
+const (
+	DbDataMaskRuleStatusEnable  int8 = 1
+	DbDataMaskRuleStatusDisable int8 = -1
+)
+
+// 脱敏方式
+type DataMaskType string
+
+const (
+	DataMaskTypePhone  DataMaskType = "phone"  // 手机号，保留前3位及后4位
+	DataMaskTypeIdCard DataMaskType = "idcard" // 身份证号，保留前3位及后4位
+	DataMaskTypeEmail  DataMaskType = "email"  // 邮箱，保留用户名首字符及域名
+	DataMaskTypeRegex  DataMaskType = "regex"  // 正则替换
+	DataMaskTypeHash   DataMaskType = "hash"   // sha256哈希
+	DataMaskTypeFull   DataMaskType = "full"   // 完全遮盖
+)
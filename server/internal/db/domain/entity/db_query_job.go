@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"mayfly-go/pkg/model"
+	"time"
+)
+
+// 异步查询任务，后台执行查询并将结果导出至服务端文件
+type DbQueryJob struct {
+	model.Model
+
+	DbId       uint64           `json:"dbId"`       // 数据库id
+	Db         string           `json:"db"`         // 数据库名
+	Sql        string           `json:"sql"`        // 查询sql
+	Format     DbQueryJobFormat `json:"format"`     // 导出文件格式
+	Status     DbQueryJobStatus `json:"status"`     // 执行状态
+	RowCount   int64            `json:"rowCount"`   // 导出行数
+	FileName   string           `json:"fileName"`   // 导出文件名
+	FilePath   string           `json:"-"`          // 导出文件存储路径
+	FileSize   int64            `json:"fileSize"`   // 导出文件大小
+	ErrMsg     string           `json:"errMsg"`     // 执行失败信息
+	EndTime    *time.Time       `json:"endTime"`    // 执行结束时间
+	ExpireTime *time.Time       `json:"expireTime"` // 导出文件过期时间
+	Remark     string           `json:"remark"`
+}
+
+type DbQueryJobStatus int8
+
+const (
+	DbQueryJobStatusRunning  DbQueryJobStatus = 1  // 执行中
+	DbQueryJobStatusSuccess  DbQueryJobStatus = 2  // 执行成功
+	DbQueryJobStatusFail     DbQueryJobStatus = -1 // 执行失败
+	DbQueryJobStatusCanceled DbQueryJobStatus = -2 // 已取消
+)
+
+// 导出文件格式
+type DbQueryJobFormat string
+
+const (
+	DbQueryJobFormatCsv  DbQueryJobFormat = "csv"
+	DbQueryJobFormatXlsx DbQueryJobFormat = "xlsx"
+	DbQueryJobFormatJson DbQueryJobFormat = "json"
+)
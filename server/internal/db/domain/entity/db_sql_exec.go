@@ -19,14 +19,16 @@ type DbSqlExec struct {
 	Res      string `json:"res"`    // 执行结果
 
 	FlowBizKey string `json:"flowBizKey"` // 流程业务key
+	RollbackId uint64 `json:"rollbackId"` // 回滚该记录的sql执行记录id，0表示未回滚
 }
 
 const (
-	DbSqlExecTypeOther  int8 = -1 // 其他类型
-	DbSqlExecTypeUpdate int8 = 1  // 更新类型
-	DbSqlExecTypeDelete int8 = 2  // 删除类型
-	DbSqlExecTypeInsert int8 = 3  // 插入类型
-	DbSqlExecTypeQuery  int8 = 4  // 查询类型，如select、show等
+	DbSqlExecTypeOther    int8 = -1 // 其他类型
+	DbSqlExecTypeUpdate   int8 = 1  // 更新类型
+	DbSqlExecTypeDelete   int8 = 2  // 删除类型
+	DbSqlExecTypeInsert   int8 = 3  // 插入类型
+	DbSqlExecTypeQuery    int8 = 4  // 查询类型，如select、show等
+	DbSqlExecTypeRollback int8 = 5  // 回滚类型，回滚其他执行记录的sql，需在事务中执行
 
 	DbSqlExecStatusWait    = 1
 	DbSqlExecStatusSuccess = 2
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"mayfly-go/pkg/model"
+)
+
+// sql审核规则，通过标签关联数据库，执行sql前进行审核
+type DbSqlReviewRule struct {
+	model.Model
+
+	Name     string            `json:"name"`
+	RuleType SqlReviewRuleType `json:"ruleType"` // 规则类型
+	Level    SqlReviewLevel    `json:"level"`    // 违反规则时的处理级别
+	Param    string            `json:"param"`    // 规则参数，如最大影响行数、变更窗口时间段等
+	Status   int8              `json:"status"`   // 状态
+	Remark   string            `json:"remark"`
+}
+
+const (
+	DbSqlReviewRuleStatusEnable  int8 = 1
+	DbSqlReviewRuleStatusDisable int8 = -1
+)
+
+// sql审核规则类型
+type SqlReviewRuleType string
+
+const (
+	SqlReviewRuleNoSelectStar         SqlReviewRuleType = "noSelectStar"         // 禁止SELECT *
+	SqlReviewRuleDdlRequireComment    SqlReviewRuleType = "ddlRequireComment"    // 建表及新增、修改列需添加注释
+	SqlReviewRuleNoImplicitConversion SqlReviewRuleType = "noImplicitConversion" // 禁止索引列发生隐式类型转换
+	SqlReviewRuleMaxAffectedRows      SqlReviewRuleType = "maxAffectedRows"      // 通过EXPLAIN预估的最大影响行数，参数为行数
+	SqlReviewRuleChangeWindow         SqlReviewRuleType = "changeWindow"         // DROP、TRUNCATE仅允许在变更窗口内执行，参数如22:00-06:00
+)
+
+// 违反审核规则时的处理级别
+type SqlReviewLevel string
+
+const (
+	SqlReviewLevelError SqlReviewLevel = "error" // 禁止执行
+	SqlReviewLevelWarn  SqlReviewLevel = "warn"  // 提示警告后继续执行
+)
@@ -80,3 +80,12 @@ type DbRestoreHistoryQuery struct {
 	Id          uint64 `json:"id" form:"id"`
 	DbRestoreId uint64 `json:"dbRestoreId" form:"dbRestoreId"`
 }
+
+// DbQueryJobQuery 异步查询任务查询
+type DbQueryJobQuery struct {
+	DbId   uint64 `json:"dbId" form:"dbId"`
+	Db     string `json:"db" form:"db"`
+	Status int8   `json:"status" form:"status"`
+
+	CreatorId uint64 `json:"-" form:"-"`
+}
@@ -0,0 +1,10 @@
+package repository
+
+import (
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/pkg/base"
+)
+
+type DbDataMaskRule interface {
+	base.Repo[*entity.DbDataMaskRule]
+}
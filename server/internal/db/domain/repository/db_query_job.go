@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type DbQueryJob interface {
+	base.Repo[*entity.DbQueryJob]
+
+	// 分页获取
+	GetPageList(condition *entity.DbQueryJobQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+}
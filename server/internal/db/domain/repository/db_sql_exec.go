@@ -11,4 +11,7 @@ type DbSqlExec interface {
 
 	// 分页获取
 	GetPageList(condition *entity.DbSqlExecQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+
+	// UpdateRollbackId 标记执行记录已被指定记录回滚，已标记过则返回false
+	UpdateRollbackId(id uint64, rollbackId uint64) (bool, error)
 }
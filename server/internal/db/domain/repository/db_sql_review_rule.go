@@ -0,0 +1,10 @@
+package repository
+
+import (
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/pkg/base"
+)
+
+type DbSqlReviewRule interface {
+	base.Repo[*entity.DbSqlReviewRule]
+}
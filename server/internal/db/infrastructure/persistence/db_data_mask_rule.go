@@ -0,0 +1,15 @@
+package persistence
+
+import (
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/internal/db/domain/repository"
+	"mayfly-go/pkg/base"
+)
+
+type dbDataMaskRuleRepoImpl struct {
+	base.RepoImpl[*entity.DbDataMaskRule]
+}
+
+func newDbDataMaskRuleRepo() repository.DbDataMaskRule {
+	return &dbDataMaskRuleRepoImpl{base.RepoImpl[*entity.DbDataMaskRule]{M: new(entity.DbDataMaskRule)}}
+}
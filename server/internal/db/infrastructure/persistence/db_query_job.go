@@ -0,0 +1,27 @@
+package persistence
+
+import (
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/internal/db/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type dbQueryJobRepoImpl struct {
+	base.RepoImpl[*entity.DbQueryJob]
+}
+
+func newDbQueryJobRepo() repository.DbQueryJob {
+	return &dbQueryJobRepoImpl{base.RepoImpl[*entity.DbQueryJob]{M: new(entity.DbQueryJob)}}
+}
+
+// 分页获取
+func (d *dbQueryJobRepoImpl) GetPageList(condition *entity.DbQueryJobQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
+	qd := model.NewCond().
+		Eq("db_id", condition.DbId).
+		Eq("db", condition.Db).
+		Eq("status", condition.Status).
+		Eq("creator_id", condition.CreatorId).
+		OrderBy(orderBy...)
+	return d.PageByCondToAny(qd, pageParam, toEntity)
+}
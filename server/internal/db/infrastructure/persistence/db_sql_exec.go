@@ -4,6 +4,8 @@ import (
 	"mayfly-go/internal/db/domain/entity"
 	"mayfly-go/internal/db/domain/repository"
 	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/global"
+	"mayfly-go/pkg/gormx"
 	"mayfly-go/pkg/model"
 )
 
@@ -27,3 +29,15 @@ func (d *dbSqlExecRepoImpl) GetPageList(condition *entity.DbSqlExecQuery, pagePa
 		RLike("db", condition.Db).OrderBy(orderBy...)
 	return d.PageByCondToAny(qd, pageParam, toEntity)
 }
+
+func (d *dbSqlExecRepoImpl) UpdateRollbackId(id uint64, rollbackId uint64) (bool, error) {
+	db := global.Db.Model(d.NewModel()).
+		Where("id = ?", id).
+		Where("rollback_id = 0").
+		Scopes(gormx.UndeleteScope).
+		Update("rollback_id", rollbackId)
+	if db.Error != nil {
+		return false, db.Error
+	}
+	return db.RowsAffected == 1, nil
+}
@@ -0,0 +1,15 @@
+package persistence
+
+import (
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/internal/db/domain/repository"
+	"mayfly-go/pkg/base"
+)
+
+type dbSqlReviewRuleRepoImpl struct {
+	base.RepoImpl[*entity.DbSqlReviewRule]
+}
+
+func newDbSqlReviewRuleRepo() repository.DbSqlReviewRule {
+	return &dbSqlReviewRuleRepoImpl{base.RepoImpl[*entity.DbSqlReviewRule]{M: new(entity.DbSqlReviewRule)}}
+}
@@ -12,6 +12,9 @@ func InitIoc() {
 	ioc.Register(newDataSyncTaskRepo(), ioc.WithComponentName("DbDataSyncTaskRepo"))
 	ioc.Register(newDataSyncLogRepo(), ioc.WithComponentName("DbDataSyncLogRepo"))
 	ioc.Register(newDbTransferTaskRepo(), ioc.WithComponentName("DbTransferTaskRepo"))
+	ioc.Register(newDbDataMaskRuleRepo(), ioc.WithComponentName("DbDataMaskRuleRepo"))
+	ioc.Register(newDbSqlReviewRuleRepo(), ioc.WithComponentName("DbSqlReviewRuleRepo"))
+	ioc.Register(newDbQueryJobRepo(), ioc.WithComponentName("DbQueryJobRepo"))
 
 	ioc.Register(NewDbBackupRepo(), ioc.WithComponentName("DbBackupRepo"))
 	ioc.Register(NewDbBackupHistoryRepo(), ioc.WithComponentName("DbBackupHistoryRepo"))
@@ -47,6 +47,14 @@ func InitDbRouter(router *gin.RouterGroup) {
 		req.NewGet(":dbId/hint-tables", d.HintTables),
 
 		req.NewPost(":dbId/copy-table", d.CopyTable),
+
+		req.NewGet(":dbId/sessions", d.Sessions).RequiredPermissionCode("db:session:list"),
+
+		req.NewPost(":dbId/sessions/kill", d.KillSession).Log(req.NewLogSave("db-终止会话")).RequiredPermissionCode("db:session:kill"),
+
+		req.NewPost(":dbId/sessions/cancel", d.CancelSessionQuery).Log(req.NewLogSave("db-取消会话查询")).RequiredPermissionCode("db:session:cancel"),
+
+		req.NewPost("schema-diff", d.SchemaDiff).Log(req.NewLog("db-表结构对比")),
 	}
 
 	req.BatchSetGroup(db, reqs[:])
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"mayfly-go/internal/db/api"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/ioc"
+	"mayfly-go/pkg/req"
+
+	"github.com/gin-gonic/gin"
+)
+
+func InitDbDataMaskRuleRouter(router *gin.RouterGroup) {
+	rules := router.Group("/dbs/data-mask-rules")
+
+	d := new(api.DbDataMaskRule)
+	biz.ErrIsNil(ioc.Inject(d))
+
+	reqs := [...]*req.Conf{
+		req.NewGet("", d.DbDataMaskRules),
+
+		req.NewPost("", d.Save).Log(req.NewLogSave("db-数据脱敏规则-保存")).RequiredPermissionCode("db:datamask:save"),
+
+		req.NewDelete(":id", d.Delete).Log(req.NewLogSave("db-数据脱敏规则-删除")).RequiredPermissionCode("db:datamask:del"),
+	}
+
+	req.BatchSetGroup(rules, reqs[:])
+}
@@ -0,0 +1,31 @@
+package router
+
+import (
+	"mayfly-go/internal/db/api"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/ioc"
+	"mayfly-go/pkg/req"
+
+	"github.com/gin-gonic/gin"
+)
+
+func InitDbQueryJobRouter(router *gin.RouterGroup) {
+	jobs := router.Group("/dbs/query-jobs")
+
+	d := new(api.DbQueryJob)
+	biz.ErrIsNil(ioc.Inject(d))
+
+	reqs := [...]*req.Conf{
+		req.NewGet("", d.DbQueryJobs),
+
+		req.NewPost("", d.Submit).Log(req.NewLogSave("db-提交异步查询任务")),
+
+		req.NewPost(":id/cancel", d.Cancel).Log(req.NewLogSave("db-取消异步查询任务")),
+
+		req.NewDelete(":id", d.Delete).Log(req.NewLogSave("db-删除异步查询任务")),
+
+		req.NewGet(":id/download", d.Download).NoRes().Log(req.NewLogSave("db-下载异步查询任务导出文件")),
+	}
+
+	req.BatchSetGroup(jobs, reqs[:])
+}
@@ -16,6 +16,15 @@ func InitDbSqlExecRouter(router *gin.RouterGroup) {
 	biz.ErrIsNil(ioc.Inject(d))
 
 	// 获取所有数据库sql执行记录列表
-	req.NewGet("", d.DbSqlExecs).Group(db)
+	reqs := [...]*req.Conf{
+		req.NewGet("", d.DbSqlExecs),
+
+		// 获取回滚sql
+		req.NewGet(":id/rollback-sql", d.RollbackSql).Log(req.NewLogSave("db-获取回滚Sql")).RequiredPermissionCode("db:sqlexec:rollback"),
+
+		req.NewPost(":id/rollback", d.Rollback).Log(req.NewLogSave("db-回滚Sql执行记录")).RequiredPermissionCode("db:sqlexec:rollback"),
+	}
+
+	req.BatchSetGroup(db, reqs[:])
 
 }
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"mayfly-go/internal/db/api"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/ioc"
+	"mayfly-go/pkg/req"
+
+	"github.com/gin-gonic/gin"
+)
+
+func InitDbSqlReviewRuleRouter(router *gin.RouterGroup) {
+	rules := router.Group("/dbs/sql-review-rules")
+
+	d := new(api.DbSqlReviewRule)
+	biz.ErrIsNil(ioc.Inject(d))
+
+	reqs := [...]*req.Conf{
+		req.NewGet("", d.DbSqlReviewRules),
+
+		req.NewPost("", d.Save).Log(req.NewLogSave("db-sql审核规则-保存")).RequiredPermissionCode("db:sqlreview:save"),
+
+		req.NewDelete(":id", d.Delete).Log(req.NewLogSave("db-sql审核规则-删除")).RequiredPermissionCode("db:sqlreview:del"),
+	}
+
+	req.BatchSetGroup(rules, reqs[:])
+}
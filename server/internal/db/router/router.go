@@ -11,4 +11,7 @@ func Init(router *gin.RouterGroup) {
 	InitDbRestoreRouter(router)
 	InitDbDataSyncRouter(router)
 	InitDbTransferRouter(router)
+	InitDbDataMaskRuleRouter(router)
+	InitDbSqlReviewRuleRouter(router)
+	InitDbQueryJobRouter(router)
 }
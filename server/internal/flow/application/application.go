@@ -8,3 +8,7 @@ func InitIoc() {
 	ioc.Register(new(procdefAppImpl), ioc.WithComponentName("ProcdefApp"))
 	ioc.Register(new(procinstAppImpl), ioc.WithComponentName("ProcinstApp"))
 }
+
+func Init() {
+	ioc.Get[Procinst]("ProcinstApp").TimerHandleTimeoutTask()
+}
@@ -19,7 +19,8 @@ type FlowBizHandler interface {
 
 	// 业务流程处理函数
 	// @param bizHandleParam 业务处理信息，可获取实例状态、关联业务key等信息
-	FlowBizHandle(ctx context.Context, bizHandleParam *BizHandleParam) error
+	// @return 业务处理结果，不为空则记录至流程实例的业务处理结果中
+	FlowBizHandle(ctx context.Context, bizHandleParam *BizHandleParam) (any, error)
 }
 
 var (
@@ -33,11 +34,11 @@ func RegisterBizHandler(flowBizType string, handler FlowBizHandler) {
 }
 
 // 流程业务处理
-func FlowBizHandle(ctx context.Context, bizHandleParam *BizHandleParam) error {
+func FlowBizHandle(ctx context.Context, bizHandleParam *BizHandleParam) (any, error) {
 	flowBizType := bizHandleParam.BizType
 	if handler, ok := handlers[flowBizType]; !ok {
 		logx.Warnf("flow biz handler not found: bizType=%s", flowBizType)
-		return errorx.NewBiz("业务处理器不存在")
+		return nil, errorx.NewBiz("业务处理器不存在")
 	} else {
 		return handler.FlowBizHandle(ctx, bizHandleParam)
 	}
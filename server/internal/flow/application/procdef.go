@@ -55,6 +55,9 @@ func (p *procdefAppImpl) SaveProcdef(ctx context.Context, defParam *dto.SaveProc
 	if err := entity.ProcdefStatusEnum.Valid(def.Status); err != nil {
 		return err
 	}
+	if err := p.validTasks(def); err != nil {
+		return err
+	}
 
 	if def.Id == 0 {
 		if p.GetByCond(&entity.Procdef{DefKey: def.DefKey}) == nil {
@@ -75,6 +78,29 @@ func (p *procdefAppImpl) SaveProcdef(ctx context.Context, defParam *dto.SaveProc
 	})
 }
 
+// 校验审批节点配置
+func (p *procdefAppImpl) validTasks(def *entity.Procdef) error {
+	tasks := def.GetTasks()
+	if len(tasks) == 0 {
+		return errorx.NewBiz("审批节点不能为空")
+	}
+	for _, task := range tasks {
+		if task.UserId == "" && len(task.Assignees) == 0 {
+			return errorx.NewBiz("审批节点[%s]未配置审批人", task.Name)
+		}
+		if task.SignType != entity.ProcdefTaskSignTypeAny && task.SignType != entity.ProcdefTaskSignTypeAll {
+			return errorx.NewBiz("审批节点[%s]审批方式错误", task.Name)
+		}
+		if err := validTaskConds(task.Conditions); err != nil {
+			return err
+		}
+		if err := validTaskTimeout(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (p *procdefAppImpl) DeleteProcdef(ctx context.Context, defId uint64) error {
 	if err := p.canModify(defId); err != nil {
 		return err
@@ -6,10 +6,19 @@ import (
 	"mayfly-go/internal/flow/application/dto"
 	"mayfly-go/internal/flow/domain/entity"
 	"mayfly-go/internal/flow/domain/repository"
+	msgapp "mayfly-go/internal/msg/application"
+	sysapp "mayfly-go/internal/sys/application"
+	tagapp "mayfly-go/internal/tag/application"
 	"mayfly-go/pkg/base"
 	"mayfly-go/pkg/contextx"
 	"mayfly-go/pkg/errorx"
 	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/utils/stringx"
+	"strings"
+
+	"github.com/may-fly/cast"
 )
 
 type Procinst interface {
@@ -34,6 +43,9 @@ type Procinst interface {
 
 	// 驳回任务（允许重新提交）
 	BackTask(ctx context.Context, taskId uint64, remark string) error
+
+	// 定时处理超时未审批的任务（超时提醒、升级或自动拒绝）
+	TimerHandleTimeoutTask()
 }
 
 type procinstAppImpl struct {
@@ -41,6 +53,10 @@ type procinstAppImpl struct {
 
 	procinstTaskRepo repository.ProcinstTask `inject:"ProcinstTaskRepo"`
 	procdefApp       Procdef                 `inject:"ProcdefApp"`
+
+	roleApp sysapp.Role `inject:"RoleApp"`
+	teamApp tagapp.Team `inject:"TeamApp"`
+	msgApp  msgapp.Msg  `inject:"MsgApp"`
 }
 
 var _ (Procinst) = (*procinstAppImpl)(nil)
@@ -79,7 +95,10 @@ func (p *procinstAppImpl) StartProc(ctx context.Context, procdefId uint64, reqPa
 		Status:      entity.ProcinstStatusActive,
 	}
 
-	task := p.getNextTask(procdef, "")
+	task := p.getNextTask(procdef, "", reqParam.BizForm)
+	if task == nil {
+		return nil, errorx.NewBiz("流程[%s]未匹配到审批节点, 请检查审批节点条件配置", procdef.Name)
+	}
 	procinst.TaskKey = task.TaskKey
 
 	if err := p.Save(ctx, procinst); err != nil {
@@ -115,35 +134,50 @@ func (p *procinstAppImpl) CancelProc(ctx context.Context, procinstId uint64) err
 }
 
 func (p *procinstAppImpl) CompleteTask(ctx context.Context, instTaskId uint64, remark string) error {
-	instTask, err := p.getAndValidInstTask(ctx, instTaskId)
-	if err != nil {
-		return err
-	}
+	return p.handleInstTask(ctx, instTaskId, true, func(ctx context.Context, instTask *entity.ProcinstTask, procinst *entity.Procinst) error {
+		// 赋值状态和备注
+		instTask.Status = entity.ProcinstTaskStatusPass
+		instTask.Remark = remark
+		instTask.SetEnd()
 
-	// 赋值状态和备注
-	instTask.Status = entity.ProcinstTaskStatusPass
-	instTask.Remark = remark
-	instTask.SetEnd()
+		procdef, err := p.procdefApp.GetById(procinst.ProcdefId)
+		if err != nil {
+			return errorx.NewBiz("流程定义不存在")
+		}
 
-	procinst, _ := p.GetById(instTask.ProcinstId)
-	procdef, _ := p.procdefApp.GetById(procinst.ProcdefId)
+		// 会签节点，需该节点所有审批人都通过才可进入下一节点。流程实例已加锁，其他审批人的处理结果均已提交
+		if nowTask := procdef.GetTask(instTask.TaskKey); nowTask != nil && nowTask.SignType == entity.ProcdefTaskSignTypeAll {
+			processTasks, err := p.procinstTaskRepo.SelectByCond(&entity.ProcinstTask{ProcinstId: procinst.Id, TaskKey: instTask.TaskKey, Status: entity.ProcinstTaskStatusProcess})
+			if err != nil {
+				return err
+			}
+			if len(processTasks) > 1 {
+				return p.procinstTaskRepo.UpdateById(ctx, instTask)
+			}
+		}
 
-	// 获取下一实例审批任务
-	task := p.getNextTask(procdef, instTask.TaskKey)
-	if task == nil {
-		procinst.Status = entity.ProcinstStatusCompleted
-		procinst.SetEnd()
-	} else {
-		procinst.TaskKey = task.TaskKey
-	}
+		// 获取下一实例审批任务
+		task := p.getNextTask(procdef, instTask.TaskKey, procinst.BizForm)
+		if task == nil {
+			procinst.Status = entity.ProcinstStatusCompleted
+			procinst.SetEnd()
+		} else {
+			procinst.TaskKey = task.TaskKey
+		}
 
-	return p.Tx(ctx, func(ctx context.Context) error {
-		return p.UpdateById(ctx, procinst)
-	}, func(ctx context.Context) error {
-		return p.procinstTaskRepo.UpdateById(ctx, instTask)
-	}, func(ctx context.Context) error {
-		return p.createProcinstTask(ctx, procinst, task)
-	}, func(ctx context.Context) error {
+		if err := p.UpdateById(ctx, procinst); err != nil {
+			return err
+		}
+		// 或签节点，取消该节点其他审批人的任务
+		if err := p.cancelInstTasks(ctx, procinst.Id, "已由其他审批人处理"); err != nil {
+			return err
+		}
+		if err := p.procinstTaskRepo.UpdateById(ctx, instTask); err != nil {
+			return err
+		}
+		if err := p.createProcinstTask(ctx, procinst, task); err != nil {
+			return err
+		}
 		// 下一审批节点任务不存在，说明该流程已结束
 		if task == nil {
 			return p.triggerProcinstStatusChangeEvent(ctx, procinst)
@@ -153,52 +187,84 @@ func (p *procinstAppImpl) CompleteTask(ctx context.Context, instTaskId uint64, r
 }
 
 func (p *procinstAppImpl) RejectTask(ctx context.Context, instTaskId uint64, remark string) error {
-	instTask, err := p.getAndValidInstTask(ctx, instTaskId)
-	if err != nil {
-		return err
-	}
+	return p.handleInstTask(ctx, instTaskId, true, func(ctx context.Context, instTask *entity.ProcinstTask, procinst *entity.Procinst) error {
+		return p.rejectTask(ctx, instTask, procinst, remark)
+	})
+}
 
+// 拒绝任务并终止流程实例，需在handleInstTask中调用
+func (p *procinstAppImpl) rejectTask(ctx context.Context, instTask *entity.ProcinstTask, procinst *entity.Procinst, remark string) error {
 	// 赋值状态和备注
 	instTask.Status = entity.ProcinstTaskStatusReject
 	instTask.Remark = remark
 	instTask.SetEnd()
 
-	procinst, _ := p.GetById(instTask.ProcinstId)
 	// 更新流程实例为终止状态，无法重新提交
 	procinst.Status = entity.ProcinstStatusTerminated
 	procinst.BizStatus = entity.ProcinstBizStatusNo
 	procinst.SetEnd()
 
-	return p.Tx(ctx, func(ctx context.Context) error {
-		return p.UpdateById(ctx, procinst)
-	}, func(ctx context.Context) error {
-		return p.procinstTaskRepo.UpdateById(ctx, instTask)
-	}, func(ctx context.Context) error {
-		return p.triggerProcinstStatusChangeEvent(ctx, procinst)
-	})
+	if err := p.UpdateById(ctx, procinst); err != nil {
+		return err
+	}
+	if err := p.cancelInstTasks(ctx, procinst.Id, "流程已被拒绝"); err != nil {
+		return err
+	}
+	if err := p.procinstTaskRepo.UpdateById(ctx, instTask); err != nil {
+		return err
+	}
+	return p.triggerProcinstStatusChangeEvent(ctx, procinst)
 }
 
 func (p *procinstAppImpl) BackTask(ctx context.Context, instTaskId uint64, remark string) error {
-	instTask, err := p.getAndValidInstTask(ctx, instTaskId)
-	if err != nil {
-		return err
-	}
+	return p.handleInstTask(ctx, instTaskId, true, func(ctx context.Context, instTask *entity.ProcinstTask, procinst *entity.Procinst) error {
+		// 赋值状态和备注
+		instTask.Status = entity.ProcinstTaskStatusBack
+		instTask.Remark = remark
 
-	// 赋值状态和备注
-	instTask.Status = entity.ProcinstTaskStatusBack
-	instTask.Remark = remark
+		// 更新流程实例为挂起状态，等待重新提交
+		procinst.Status = entity.ProcinstStatusSuspended
 
-	procinst, _ := p.GetById(instTask.ProcinstId)
+		if err := p.UpdateById(ctx, procinst); err != nil {
+			return err
+		}
+		if err := p.cancelInstTasks(ctx, procinst.Id, "流程已被驳回"); err != nil {
+			return err
+		}
+		if err := p.procinstTaskRepo.UpdateById(ctx, instTask); err != nil {
+			return err
+		}
+		return p.triggerProcinstStatusChangeEvent(ctx, procinst)
+	})
+}
 
-	// 更新流程实例为挂起状态，等待重新提交
-	procinst.Status = entity.ProcinstStatusSuspended
+// 在事务中锁定任务所属的流程实例，并校验任务仍处于处理中，保证同一流程实例的任务串行处理。validAssignee为true则校验当前用户为任务处理人
+func (p *procinstAppImpl) handleInstTask(ctx context.Context, instTaskId uint64, validAssignee bool, handle func(ctx context.Context, instTask *entity.ProcinstTask, procinst *entity.Procinst) error) error {
+	instTask, err := p.procinstTaskRepo.GetById(instTaskId)
+	if err != nil {
+		return errorx.NewBiz("流程实例任务不存在")
+	}
+	if validAssignee {
+		la := contextx.GetLoginAccount(ctx)
+		if la == nil || instTask.Assignee != fmt.Sprintf("%d", la.Id) {
+			return errorx.NewBiz("当前用户不是任务处理人，无法完成任务")
+		}
+	}
 
 	return p.Tx(ctx, func(ctx context.Context) error {
-		return p.UpdateById(ctx, procinst)
-	}, func(ctx context.Context) error {
-		return p.procinstTaskRepo.UpdateById(ctx, instTask)
-	}, func(ctx context.Context) error {
-		return p.triggerProcinstStatusChangeEvent(ctx, procinst)
+		procinst, err := p.GetRepo().GetByIdForUpdate(ctx, instTask.ProcinstId)
+		if err != nil {
+			return errorx.NewBiz("流程实例不存在")
+		}
+		// 获取加锁后的任务最新状态，任务可能已被其他审批人处理而取消
+		instTask, err := p.procinstTaskRepo.GetById(instTaskId)
+		if err != nil {
+			return errorx.NewBiz("流程实例任务不存在")
+		}
+		if instTask.Status != entity.ProcinstTaskStatusProcess || procinst.Status != entity.ProcinstStatusActive {
+			return errorx.NewBiz("该任务已处理或已取消")
+		}
+		return handle(ctx, instTask, procinst)
 	})
 }
 
@@ -217,7 +283,7 @@ func (p *procinstAppImpl) cancelInstTasks(ctx context.Context, procinstId uint64
 
 // 触发流程实例状态改变事件
 func (p *procinstAppImpl) triggerProcinstStatusChangeEvent(ctx context.Context, procinst *entity.Procinst) error {
-	err := FlowBizHandle(ctx, &BizHandleParam{
+	handleRes, err := FlowBizHandle(ctx, &BizHandleParam{
 		BizType:        procinst.BizType,
 		BizKey:         procinst.BizKey,
 		BizForm:        procinst.BizForm,
@@ -240,58 +306,96 @@ func (p *procinstAppImpl) triggerProcinstStatusChangeEvent(ctx context.Context,
 	if procinst.Status == entity.ProcinstStatusCompleted {
 		procinst.BizStatus = entity.ProcinstBizStatusSuccess
 		procinst.BizHandleRes = "success"
+		if handleRes != nil {
+			procinst.BizHandleRes = stringx.TruncateStr(anyx.ToString(handleRes), 1000)
+		}
 		return p.UpdateById(ctx, procinst)
 	}
 	return err
 }
 
-// 获取并校验实例任务
-func (p *procinstAppImpl) getAndValidInstTask(ctx context.Context, instTaskId uint64) (*entity.ProcinstTask, error) {
-	instTask, err := p.procinstTaskRepo.GetById(instTaskId)
-	if err != nil {
-		return nil, errorx.NewBiz("流程实例任务不存在")
+// 创建流程实例节点任务，节点存在多个审批人时，为每个审批人创建一个任务
+func (p *procinstAppImpl) createProcinstTask(ctx context.Context, procinst *entity.Procinst, task *entity.ProcdefTask) error {
+	if task == nil {
+		return nil
 	}
 
-	la := contextx.GetLoginAccount(ctx)
-	if instTask.Assignee != fmt.Sprintf("%d", la.Id) {
-		return nil, errorx.NewBiz("当前用户不是任务处理人，无法完成任务")
+	assignees, err := p.getTaskAssignees(task)
+	if err != nil {
+		return err
 	}
 
-	return instTask, nil
+	procinstTasks := make([]*entity.ProcinstTask, 0)
+	for _, assignee := range assignees {
+		procinstTasks = append(procinstTasks, &entity.ProcinstTask{
+			ProcinstId: procinst.Id,
+			Status:     entity.ProcinstTaskStatusProcess,
+
+			TaskKey:  task.TaskKey,
+			TaskName: task.Name,
+			Assignee: assignee,
+		})
+	}
+	return p.procinstTaskRepo.BatchInsert(ctx, procinstTasks)
 }
 
-// 创建流程实例节点任务
-func (p *procinstAppImpl) createProcinstTask(ctx context.Context, procinst *entity.Procinst, task *entity.ProcdefTask) error {
-	if task == nil {
-		return nil
+// 获取审批节点的所有审批人账号id，包括指定用户以及角色、团队下的用户
+func (p *procinstAppImpl) getTaskAssignees(task *entity.ProcdefTask) ([]string, error) {
+	accountIds := make([]uint64, 0)
+	for _, userId := range strings.Split(task.UserId, ",") {
+		if userId = strings.TrimSpace(userId); userId != "" {
+			accountIds = append(accountIds, cast.ToUint64(userId))
+		}
 	}
 
-	procinstTask := &entity.ProcinstTask{
-		ProcinstId: procinst.Id,
-		Status:     entity.ProcinstTaskStatusProcess,
+	for _, assignee := range task.Assignees {
+		switch assignee.Type {
+		case entity.ProcdefTaskAssigneeTypeRole:
+			ids, err := p.roleApp.GetRoleAccountIds(assignee.Id)
+			if err != nil {
+				return nil, errorx.NewBiz("获取角色关联账号失败: %s", err.Error())
+			}
+			accountIds = append(accountIds, ids...)
+		case entity.ProcdefTaskAssigneeTypeTeam:
+			ids, err := p.teamApp.GetMemberAccountIds(assignee.Id)
+			if err != nil {
+				return nil, errorx.NewBiz("获取团队成员失败: %s", err.Error())
+			}
+			accountIds = append(accountIds, ids...)
+		default:
+			accountIds = append(accountIds, assignee.Id)
+		}
+	}
 
-		TaskKey:  task.TaskKey,
-		TaskName: task.Name,
-		Assignee: task.UserId,
+	accountIds = collx.ArrayDeduplicate(collx.ArrayRemoveFunc(accountIds, func(id uint64) bool { return id == 0 }))
+	if len(accountIds) == 0 {
+		return nil, errorx.NewBiz("审批节点[%s]未找到审批人", task.Name)
 	}
-	return p.procinstTaskRepo.Insert(ctx, procinstTask)
+	return collx.ArrayMap(accountIds, func(id uint64) string { return fmt.Sprintf("%d", id) }), nil
 }
 
-// 获取下一审批节点任务
-func (p *procinstAppImpl) getNextTask(procdef *entity.Procdef, nowTaskKey string) *entity.ProcdefTask {
+// 获取下一审批节点任务，不满足节点条件的节点将被跳过
+func (p *procinstAppImpl) getNextTask(procdef *entity.Procdef, nowTaskKey string, bizForm string) *entity.ProcdefTask {
 	tasks := procdef.GetTasks()
 	if len(tasks) == 0 {
 		return nil
 	}
 
-	if nowTaskKey == "" {
-		// nowTaskKey为空，则说明为刚启动该流程实例
-		return tasks[0]
+	// nowTaskKey为空，则说明为刚启动该流程实例，从第一个节点开始匹配
+	start := 0
+	if nowTaskKey != "" {
+		start = len(tasks)
+		for index, t := range tasks {
+			if t.TaskKey == nowTaskKey {
+				start = index + 1
+				break
+			}
+		}
 	}
 
-	for index, t := range tasks {
-		if (t.TaskKey == nowTaskKey) && (index < len(tasks)-1) {
-			return tasks[index+1]
+	for _, t := range tasks[start:] {
+		if matchTaskConds(t.Conditions, bizForm) {
+			return t
 		}
 	}
 
@@ -0,0 +1,184 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"mayfly-go/internal/flow/domain/entity"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/rediscli"
+	"mayfly-go/pkg/scheduler"
+	"time"
+
+	"github.com/may-fly/cast"
+)
+
+const timeoutTaskJobKey = "flow:procinst:timeout-task"
+
+// 校验审批节点超时配置
+func validTaskTimeout(task *entity.ProcdefTask) error {
+	if task.RemindHours < 0 || task.TimeoutHours < 0 {
+		return errorx.NewBiz("审批节点[%s]超时时间不能小于0", task.Name)
+	}
+	if task.TimeoutHours == 0 {
+		return nil
+	}
+	if task.RemindHours > 0 && task.RemindHours >= task.TimeoutHours {
+		return errorx.NewBiz("审批节点[%s]提醒时间需小于超时时间", task.Name)
+	}
+
+	switch task.TimeoutAction {
+	case entity.ProcdefTaskTimeoutActionEscalate:
+		if len(task.EscalateAssignees) == 0 {
+			return errorx.NewBiz("审批节点[%s]未配置超时升级审批人", task.Name)
+		}
+	case entity.ProcdefTaskTimeoutActionReject:
+	default:
+		return errorx.NewBiz("审批节点[%s]超时处理方式错误", task.Name)
+	}
+	return nil
+}
+
+func (p *procinstAppImpl) TimerHandleTimeoutTask() {
+	logx.Debug("开始定时处理超时的流程审批任务...")
+	scheduler.AddFunByKey(timeoutTaskJobKey, "@every 10m", func() {
+		// 简单使用redis分布式锁防止多实例同一时刻重复执行
+		if lock := rediscli.NewLock(timeoutTaskJobKey, 60*time.Second); lock != nil {
+			if !lock.Lock() {
+				return
+			}
+			defer lock.UnLock()
+		}
+
+		if err := p.handleTimeoutTasks(context.Background()); err != nil {
+			logx.Errorf("处理超时的流程审批任务失败: %s", err.Error())
+		}
+	})
+}
+
+// 处理所有处理中的审批任务，达到提醒时间则提醒审批人，达到超时时间则根据节点配置升级或自动拒绝
+func (p *procinstAppImpl) handleTimeoutTasks(ctx context.Context) error {
+	instTasks, err := p.procinstTaskRepo.SelectByCond(&entity.ProcinstTask{Status: entity.ProcinstTaskStatusProcess})
+	if err != nil {
+		return err
+	}
+
+	// 已处理超时的流程实例节点，同一节点的多个审批任务只需处理一次
+	timeoutHandled := make(map[string]bool)
+	procdefs := make(map[uint64]*entity.Procdef)
+
+	for _, instTask := range instTasks {
+		if instTask.CreateTime == nil {
+			continue
+		}
+		procinst, err := p.GetById(instTask.ProcinstId)
+		if err != nil || procinst.Status != entity.ProcinstStatusActive || procinst.TaskKey != instTask.TaskKey {
+			continue
+		}
+
+		procdef := procdefs[procinst.ProcdefId]
+		if procdef == nil {
+			if procdef, err = p.procdefApp.GetById(procinst.ProcdefId); err != nil {
+				continue
+			}
+			procdefs[procinst.ProcdefId] = procdef
+		}
+		task := procdef.GetTask(instTask.TaskKey)
+		if task == nil {
+			continue
+		}
+
+		elapsed := time.Since(*instTask.CreateTime)
+		// 已升级的任务不再进行超时处理，仅提醒
+		if task.TimeoutHours > 0 && !instTask.Escalated && elapsed >= time.Duration(task.TimeoutHours)*time.Hour {
+			nodeKey := fmt.Sprintf("%d:%s", instTask.ProcinstId, instTask.TaskKey)
+			if timeoutHandled[nodeKey] {
+				continue
+			}
+			timeoutHandled[nodeKey] = true
+
+			if err := p.handleTimeoutTask(ctx, procinst, instTask, task); err != nil {
+				logx.Errorf("流程[%s]审批节点[%s]超时处理失败: %s", procinst.ProcdefName, task.Name, err.Error())
+			}
+			continue
+		}
+
+		if task.RemindHours > 0 && instTask.RemindTime == nil && elapsed >= time.Duration(task.RemindHours)*time.Hour {
+			p.remindTask(ctx, procinst, instTask)
+		}
+	}
+	return nil
+}
+
+// 超时提醒审批人，每个任务仅提醒一次
+func (p *procinstAppImpl) remindTask(ctx context.Context, procinst *entity.Procinst, instTask *entity.ProcinstTask) {
+	now := time.Now()
+	instTask.RemindTime = &now
+	if err := p.procinstTaskRepo.UpdateById(ctx, instTask); err != nil {
+		logx.Errorf("更新流程审批任务提醒时间失败: %s", err.Error())
+		return
+	}
+
+	p.msgApp.CreateAndSend(&model.LoginAccount{Id: cast.ToUint64(instTask.Assignee), Username: "system"},
+		msgdto.InfoSysMsg("流程审批提醒", fmt.Sprintf("流程[%s]的审批节点[%s]已等待您处理超过%s, 请及时处理",
+			procinst.ProcdefName, instTask.TaskName, time.Since(*instTask.CreateTime).Round(time.Minute).String())))
+}
+
+// 审批节点超时处理
+func (p *procinstAppImpl) handleTimeoutTask(ctx context.Context, procinst *entity.Procinst, instTask *entity.ProcinstTask, task *entity.ProcdefTask) error {
+	switch task.TimeoutAction {
+	case entity.ProcdefTaskTimeoutActionReject:
+		logx.Infof("流程[%s]审批节点[%s]超时未处理, 自动拒绝", procinst.ProcdefName, task.Name)
+		return p.handleInstTask(ctx, instTask.Id, false, func(ctx context.Context, instTask *entity.ProcinstTask, procinst *entity.Procinst) error {
+			return p.rejectTask(ctx, instTask, procinst, fmt.Sprintf("超过%d小时未处理, 系统自动拒绝", task.TimeoutHours))
+		})
+	case entity.ProcdefTaskTimeoutActionEscalate:
+		logx.Infof("流程[%s]审批节点[%s]超时未处理, 升级至其他审批人", procinst.ProcdefName, task.Name)
+		return p.escalateTask(ctx, procinst, task)
+	}
+	return nil
+}
+
+// 将超时节点升级至配置的升级审批人，并取消原审批人的任务
+func (p *procinstAppImpl) escalateTask(ctx context.Context, procinst *entity.Procinst, task *entity.ProcdefTask) error {
+	assignees, err := p.getTaskAssignees(&entity.ProcdefTask{Name: task.Name, Assignees: task.EscalateAssignees})
+	if err != nil {
+		return err
+	}
+
+	escalateTasks := make([]*entity.ProcinstTask, 0)
+	for _, assignee := range assignees {
+		escalateTasks = append(escalateTasks, &entity.ProcinstTask{
+			ProcinstId: procinst.Id,
+			Status:     entity.ProcinstTaskStatusProcess,
+
+			TaskKey:   task.TaskKey,
+			TaskName:  task.Name,
+			Assignee:  assignee,
+			Escalated: true,
+		})
+	}
+
+	return p.Tx(ctx, func(ctx context.Context) error {
+		// 锁定流程实例，避免与审批人同时处理该节点
+		lockedProcinst, err := p.GetRepo().GetByIdForUpdate(ctx, procinst.Id)
+		if err != nil {
+			return err
+		}
+		if lockedProcinst.Status != entity.ProcinstStatusActive || lockedProcinst.TaskKey != task.TaskKey {
+			return errorx.NewBiz("审批节点已被处理")
+		}
+		return p.cancelInstTasks(ctx, procinst.Id, fmt.Sprintf("超过%d小时未处理, 已升级至其他审批人", task.TimeoutHours))
+	}, func(ctx context.Context) error {
+		if err := p.procinstTaskRepo.BatchInsert(ctx, escalateTasks); err != nil {
+			return err
+		}
+		for _, escalateTask := range escalateTasks {
+			p.msgApp.CreateAndSend(&model.LoginAccount{Id: cast.ToUint64(escalateTask.Assignee), Username: "system"},
+				msgdto.InfoSysMsg("流程审批提醒", fmt.Sprintf("流程[%s]的审批节点[%s]已超时升级, 请您及时处理", procinst.ProcdefName, task.Name)))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,94 @@
+package application
+
+import (
+	"mayfly-go/internal/flow/domain/entity"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"regexp"
+	"strings"
+
+	"github.com/may-fly/cast"
+	"github.com/tidwall/gjson"
+)
+
+// 审批节点条件操作符函数
+// @param fieldVal 业务表单中对应字段的值
+// @param condVal 条件中配置的比较值
+type TaskCondOpFunc func(fieldVal string, condVal string) bool
+
+var (
+	taskCondOps = map[string]TaskCondOpFunc{
+		"eq": func(fieldVal, condVal string) bool { return fieldVal == condVal },
+		"ne": func(fieldVal, condVal string) bool { return fieldVal != condVal },
+		"in": func(fieldVal, condVal string) bool {
+			for _, v := range strings.Split(condVal, ",") {
+				if strings.TrimSpace(v) == fieldVal {
+					return true
+				}
+			}
+			return false
+		},
+		"contains": func(fieldVal, condVal string) bool {
+			return strings.Contains(strings.ToLower(fieldVal), strings.ToLower(condVal))
+		},
+		"prefix": func(fieldVal, condVal string) bool { return strings.HasPrefix(fieldVal, condVal) },
+		"regex": func(fieldVal, condVal string) bool {
+			matched, err := regexp.MatchString(condVal, fieldVal)
+			return err == nil && matched
+		},
+		"gt": func(fieldVal, condVal string) bool { return cast.ToFloat64(fieldVal) > cast.ToFloat64(condVal) },
+		"lt": func(fieldVal, condVal string) bool { return cast.ToFloat64(fieldVal) < cast.ToFloat64(condVal) },
+	}
+)
+
+// 注册审批节点条件操作符，可用于扩展自定义条件
+func RegisterTaskCondOp(op string, opFunc TaskCondOpFunc) {
+	logx.Infof("flow register task condition op: %s", op)
+	taskCondOps[op] = opFunc
+}
+
+// 校验审批节点条件配置
+func validTaskConds(conds []*entity.ProcdefTaskCond) error {
+	for _, cond := range conds {
+		if cond.Field == "" {
+			return errorx.NewBiz("审批节点条件字段不能为空")
+		}
+		if _, ok := taskCondOps[cond.Op]; !ok {
+			return errorx.NewBiz("不支持的审批节点条件操作符: %s", cond.Op)
+		}
+	}
+	return nil
+}
+
+// 判断业务表单是否满足审批节点的所有条件。
+// 若字段值为数组，则任意元素满足即视为满足，如codePaths中任意标签路径以xx为前缀
+func matchTaskConds(conds []*entity.ProcdefTaskCond, bizForm string) bool {
+	for _, cond := range conds {
+		opFunc, ok := taskCondOps[cond.Op]
+		if !ok {
+			logx.Warnf("flow task condition op not found: %s", cond.Op)
+			return false
+		}
+
+		fieldRes := gjson.Get(bizForm, cond.Field)
+		fieldVals := []string{fieldRes.String()}
+		if fieldRes.IsArray() {
+			fieldVals = make([]string, 0)
+			for _, v := range fieldRes.Array() {
+				fieldVals = append(fieldVals, v.String())
+			}
+		}
+
+		matched := false
+		for _, fieldVal := range fieldVals {
+			if opFunc(fieldVal, cond.Value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
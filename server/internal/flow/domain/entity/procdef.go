@@ -34,12 +34,65 @@ func (p *Procdef) GetTasks() []*ProcdefTask {
 	return tasks
 }
 
+// 根据任务key获取审批节点任务
+func (p *Procdef) GetTask(taskKey string) *ProcdefTask {
+	for _, task := range p.GetTasks() {
+		if task.TaskKey == taskKey {
+			return task
+		}
+	}
+	return nil
+}
+
 type ProcdefTask struct {
-	Name    string `json:"name" form:"name"`       // 审批节点任务名称
-	TaskKey string `json:"taskKey" form:"taskKey"` // 任务key
-	UserId  string `json:"userId"`                 // 审批人
+	Name       string                 `json:"name" form:"name"`       // 审批节点任务名称
+	TaskKey    string                 `json:"taskKey" form:"taskKey"` // 任务key
+	UserId     string                 `json:"userId"`                 // 审批人
+	Assignees  []*ProcdefTaskAssignee `json:"assignees"`              // 审批候选人（用户、角色、团队），与UserId一并作为审批人
+	SignType   ProcdefTaskSignType    `json:"signType"`               // 多人审批方式
+	Conditions []*ProcdefTaskCond     `json:"conditions"`             // 节点条件，全部满足才会进入该节点，为空则总是进入
+
+	RemindHours       int                      `json:"remindHours"`       // 任务超过N小时未处理则提醒审批人，0为不提醒
+	TimeoutHours      int                      `json:"timeoutHours"`      // 任务超过M小时未处理则执行超时处理，0为不处理
+	TimeoutAction     ProcdefTaskTimeoutAction `json:"timeoutAction"`     // 超时处理方式
+	EscalateAssignees []*ProcdefTaskAssignee   `json:"escalateAssignees"` // 超时升级后的审批人
+}
+
+// 审批候选人
+type ProcdefTaskAssignee struct {
+	Type ProcdefTaskAssigneeType `json:"type"` // 候选人类型
+	Id   uint64                  `json:"id"`   // 用户id、角色id或团队id
+}
+
+// 审批节点条件，通过业务表单信息判断是否需要进入该审批节点
+type ProcdefTaskCond struct {
+	Field string `json:"field"` // 业务表单字段路径（gjson语法），如：type、codePaths
+	Op    string `json:"op"`    // 条件操作符，如：eq、ne、in、contains、prefix、regex等
+	Value string `json:"value"` // 比较值
 }
 
+type ProcdefTaskAssigneeType string
+
+const (
+	ProcdefTaskAssigneeTypeUser ProcdefTaskAssigneeType = "user"
+	ProcdefTaskAssigneeTypeRole ProcdefTaskAssigneeType = "role"
+	ProcdefTaskAssigneeTypeTeam ProcdefTaskAssigneeType = "team"
+)
+
+type ProcdefTaskTimeoutAction int8
+
+const (
+	ProcdefTaskTimeoutActionEscalate ProcdefTaskTimeoutAction = 1 // 升级至其他审批人
+	ProcdefTaskTimeoutActionReject   ProcdefTaskTimeoutAction = 2 // 自动拒绝
+)
+
+type ProcdefTaskSignType int8
+
+const (
+	ProcdefTaskSignTypeAny ProcdefTaskSignType = 0 // 或签，任意一人审批通过即可
+	ProcdefTaskSignTypeAll ProcdefTaskSignType = 1 // 会签，需所有人审批通过
+)
+
 type ProcdefStatus int8
 
 const (
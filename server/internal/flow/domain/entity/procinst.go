@@ -77,6 +77,9 @@ type ProcinstTask struct {
 	Remark   string             `json:"remark"`
 	EndTime  *time.Time         `json:"endTime"`
 	Duration int64              `json:"duration"` // 持续时间（开始到结束）
+
+	RemindTime *time.Time `json:"remindTime"` // 超时提醒时间
+	Escalated  bool       `json:"escalated"`  // 是否为超时升级后创建的任务
 }
 
 func (a *ProcinstTask) TableName() string {
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"mayfly-go/internal/flow/domain/entity"
 	"mayfly-go/pkg/base"
 	"mayfly-go/pkg/model"
@@ -10,6 +11,9 @@ type Procinst interface {
 	base.Repo[*entity.Procinst]
 
 	GetPageList(condition *entity.ProcinstQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+
+	// GetByIdForUpdate 获取并锁定流程实例（select for update），需在事务中调用
+	GetByIdForUpdate(ctx context.Context, id uint64) (*entity.Procinst, error)
 }
 
 type ProcinstTask interface {
@@ -1,10 +1,16 @@
 package persistence
 
 import (
+	"context"
 	"mayfly-go/internal/flow/domain/entity"
 	"mayfly-go/internal/flow/domain/repository"
 	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/global"
+	"mayfly-go/pkg/gormx"
 	"mayfly-go/pkg/model"
+
+	"gorm.io/gorm/clause"
 )
 
 type procinstImpl struct {
@@ -20,6 +26,20 @@ func (p *procinstImpl) GetPageList(condition *entity.ProcinstQuery, pageParam *m
 	return p.PageByCondToAny(qd, pageParam, toEntity)
 }
 
+func (p *procinstImpl) GetByIdForUpdate(ctx context.Context, id uint64) (*entity.Procinst, error) {
+	db := contextx.GetDb(ctx)
+	if db == nil {
+		db = global.Db
+	}
+	procinst := new(entity.Procinst)
+	err := db.Model(procinst).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Scopes(gormx.UndeleteScope).
+		Where("id = ?", id).
+		First(procinst).Error
+	return procinst, err
+}
+
 //-----------procinst task--------------
 
 type procinstTaskImpl struct {
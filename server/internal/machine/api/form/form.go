@@ -43,15 +43,84 @@ type MachineCronJobForm struct {
 	SaveExecResType int      `json:"saveExecResType" binding:"required"`
 	Remark          string   `json:"remark"`
 	CodePaths       []string `json:"codePaths"`
+
+	Timeout             int      `json:"timeout"`             // 单次执行最大时间(秒)
+	RetryCount          int      `json:"retryCount"`          // 失败重试次数
+	RetryInterval       int      `json:"retryInterval"`       // 首次重试间隔(秒)
+	SkipIfRunning       int8     `json:"skipIfRunning"`       // 上次执行未结束时是否跳过
+	FailNotifyThreshold int      `json:"failNotifyThreshold"` // 连续失败通知阈值
+	Receivers           []string `json:"receivers"`           // 失败通知接收人
+}
+
+type MachineBatchExecForm struct {
+	Name        string   `json:"name" binding:"required"`
+	Script      string   `json:"script" binding:"required"`
+	TagPaths    []string `json:"tagPaths" binding:"required"`
+	Concurrency int      `json:"concurrency"` // 并发执行的机器数
+	Timeout     int      `json:"timeout"`     // 单台机器执行超时时间(秒)
+	ClientId    string   `json:"clientId"`    // 接收实时输出的websocket客户端id
 }
 
 type MachineCmdConfForm struct {
-	Id       uint64   `json:"id"`
-	Name     string   `json:"name"`
-	Cmds     []string `json:"cmds"`     // 命令配置
-	Status   int8     `json:"execCmds"` // 状态
-	Stratege string   `json:"stratege"` // 策略，空禁用
-	Remark   string   `json:"remark"`   // 备注
+	Id        uint64   `json:"id"`
+	Name      string   `json:"name"`
+	Cmds      []string `json:"cmds"`      // 命令配置
+	Status    int8     `json:"execCmds"`  // 状态
+	Stratege  string   `json:"stratege"`  // 策略，空禁用，warn警告，alert告警，approval审批
+	Receivers []string `json:"receivers"` // 告警接收人或审批人用户名
+	Remark    string   `json:"remark"`    // 备注
 
 	CodePaths []string `json:"codePaths"`
 }
+
+type MachineAlertRuleForm struct {
+	Id        uint64   `json:"id"`
+	Name      string   `json:"name" binding:"required"`
+	Metric    string   `json:"metric" binding:"required"`    // 告警指标 cpu、mem、disk、load1
+	Threshold float32  `json:"threshold" binding:"required"` // 阈值
+	Duration  int      `json:"duration"`                     // 持续时间(分钟)
+	Receivers []string `json:"receivers" binding:"required"` // 告警接收人用户名
+	Status    int8     `json:"status" binding:"required"`
+	Remark    string   `json:"remark"`
+
+	CodePaths []string `json:"codePaths"`
+}
+
+type TermSessionInviteForm struct {
+	Username string `json:"username" binding:"required"` // 协作者用户名
+}
+
+// 完成分片上传
+type MachineFileChunkCompleteForm struct {
+	MachineId    uint64 `json:"machineId" binding:"required"`
+	Protocol     int    `json:"protocol" binding:"required"`
+	AuthCertName string `json:"authCertName" binding:"required"`
+	Path         string `json:"path" binding:"required"`
+	Filename     string `json:"filename" binding:"required"`
+	Size         int64  `json:"size"`
+	Checksum     string `json:"checksum" binding:"required"` // 文件sha256值
+}
+
+// 机器间文件传输
+type MachineFileTransferForm struct {
+	MachineId    uint64 `json:"machineId" binding:"required"`
+	Protocol     int    `json:"protocol" binding:"required"`
+	AuthCertName string `json:"authCertName" binding:"required"`
+	Path         string `json:"path" binding:"required"` // 源文件路径
+
+	TargetMachineId    uint64 `json:"targetMachineId" binding:"required"`
+	TargetProtocol     int    `json:"targetProtocol" binding:"required"`
+	TargetAuthCertName string `json:"targetAuthCertName" binding:"required"`
+	TargetPath         string `json:"targetPath" binding:"required"` // 目标目录
+
+	ClientId string `json:"clientId"`
+}
+
+// 开启端口转发
+type MachinePortForwardForm struct {
+	AuthCertName string `json:"authCertName" binding:"required"`
+	RemoteHost   string `json:"remoteHost"` // 机器所在网络中的目标地址，默认127.0.0.1
+	RemotePort   int    `json:"remotePort" binding:"required"`
+	LocalPort    int    `json:"localPort"` // mayfly-go服务端监听端口，为空则随机选取
+	Duration     int    `json:"duration"`  // 有效时长(分钟)
+}
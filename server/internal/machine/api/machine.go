@@ -1,8 +1,11 @@
 package api
 
 import (
+	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 	"mayfly-go/internal/common/consts"
 	"mayfly-go/internal/event"
 	"mayfly-go/internal/machine/api/form"
@@ -37,10 +40,12 @@ import (
 )
 
 type Machine struct {
-	MachineApp          application.Machine       `inject:""`
-	MachineTermOpApp    application.MachineTermOp `inject:""`
-	TagApp              tagapp.TagTree            `inject:"TagTreeApp"`
-	ResourceAuthCertApp tagapp.ResourceAuthCert   `inject:""`
+	MachineApp            application.Machine            `inject:""`
+	MachineTermOpApp      application.MachineTermOp      `inject:""`
+	MachineTermOpIndexApp application.MachineTermOpIndex `inject:""`
+	MachineMonitorApp     application.MachineMonitor     `inject:""`
+	TagApp                tagapp.TagTree                 `inject:"TagTreeApp"`
+	ResourceAuthCertApp   tagapp.ResourceAuthCert        `inject:""`
 }
 
 func (m *Machine) Machines(rc *req.Ctx) {
@@ -93,6 +98,20 @@ func (m *Machine) MachineStats(rc *req.Ctx) {
 	rc.ResData = cli.GetAllStats()
 }
 
+// 获取机器监控历史记录，默认获取最近一小时的记录
+func (m *Machine) MachineMonitors(rc *req.Ctx) {
+	cond := req.BindQuery(rc, new(entity.MachineMonitorQuery))
+	cond.MachineId = GetMachineId(rc)
+	if cond.StartTime == nil {
+		startTime := time.Now().Add(-time.Hour)
+		cond.StartTime = &startTime
+	}
+
+	res, err := m.MachineMonitorApp.GetMonitors(cond)
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
 // 保存机器信息
 func (m *Machine) SaveMachine(rc *req.Ctx) {
 	machineForm := new(form.MachineForm)
@@ -218,12 +237,28 @@ func (m *Machine) WsSSH(g *gin.Context) {
 	cols := rc.QueryIntDefault("cols", 80)
 	rows := rc.QueryIntDefault("rows", 32)
 
+	// 指定了目标类型则进入机器上的容器
+	var target *mcm.TerminalTarget
+	if targetType := rc.Query("targetType"); targetType != "" {
+		target = &mcm.TerminalTarget{
+			Type:      targetType,
+			Container: rc.Query("container"),
+			Pod:       rc.Query("pod"),
+			Namespace: rc.Query("namespace"),
+			Shell:     rc.Query("shell"),
+		}
+	}
+
 	// 记录系统操作日志
 	rc.WithLog(req.NewLogSave("机器-终端操作"))
-	rc.ReqParam = cli.Info
+	if target != nil {
+		rc.ReqParam = collx.Kvs("machine", cli.Info, "target", target)
+	} else {
+		rc.ReqParam = cli.Info
+	}
 	req.LogHandler(rc)
 
-	err = m.MachineTermOpApp.TermConn(rc.MetaCtx, cli, wsConn, rows, cols)
+	err = m.MachineTermOpApp.TermConn(rc.MetaCtx, cli, wsConn, rows, cols, target)
 	biz.ErrIsNilAppendErr(err, mcm.GetErrorContentRn("连接失败: %s"))
 }
 
@@ -297,6 +332,7 @@ func (m *Machine) WsGuacamole(g *gin.Context) {
 
 	if mi.EnableRecorder == 1 {
 		// 操作记录 查看文档：https://guacamole.apache.org/doc/gug/configuring-guacamole.html#graphical-recording
+		// 服务端仅能记录guacd发送至客户端的指令，键盘输入等需由guacd录制
 		params["recording-path"] = fmt.Sprintf("/rdp-rec/%s", ac)
 		params["create-recording-path"] = "true"
 		params["recording-include-keys"] = "true"
@@ -319,33 +355,96 @@ func (m *Machine) WsGuacamole(g *gin.Context) {
 		}
 	}
 
-	tunnel, err := guac.DoConnect(query, params, rc.GetLoginAccount().Username)
-	if err != nil {
-		return
-	}
-	defer func() {
-		if err = tunnel.Close(); err != nil {
-			logx.Warnf("Error closing tunnel: %v", err)
+	// 开启终端回放时，由服务端记录guacd发送至客户端的指令流
+	err = m.MachineTermOpApp.GuacConn(rc.MetaCtx, mi, func(rec io.Writer) error {
+		tunnel, err := guac.DoConnect(query, params, rc.GetLoginAccount().Username)
+		if err != nil {
+			return err
 		}
-	}()
+		defer func() {
+			if err = tunnel.Close(); err != nil {
+				logx.Warnf("Error closing tunnel: %v", err)
+			}
+		}()
 
-	sessions.Add(ac, wsConn, g.Request, tunnel)
+		sessions.Add(ac, wsConn, g.Request, tunnel)
 
-	defer sessions.Delete(ac, wsConn, g.Request, tunnel)
+		defer sessions.Delete(ac, wsConn, g.Request, tunnel)
 
-	writer := tunnel.AcquireWriter()
-	reader := tunnel.AcquireReader()
+		writer := tunnel.AcquireWriter()
+		reader := tunnel.AcquireReader()
 
-	defer tunnel.ReleaseWriter()
-	defer tunnel.ReleaseReader()
+		defer tunnel.ReleaseWriter()
+		defer tunnel.ReleaseReader()
 
-	go guac.WsToGuacd(wsConn, tunnel, writer)
-	guac.GuacdToWs(wsConn, tunnel, reader)
+		if rec != nil {
+			reader = guac.NewRecordingReader(reader, rec)
+		}
+
+		go guac.WsToGuacd(wsConn, tunnel, writer)
+		guac.GuacdToWs(wsConn, tunnel, reader)
+		return nil
+	})
+	if err != nil {
+		logx.Warnf("guacamole connect failed: %v", err)
+	}
 
 	//OnConnect
 	//OnDisconnect
 }
 
+// 按录像时间回放RDP/VNC会话记录，可通过speed参数指定回放倍速
+func (m *Machine) WsReplayGuacTermOpRecord(g *gin.Context) {
+	wsConn, err := ws.Upgrader.Upgrade(g.Writer, g.Request, nil)
+	defer func() {
+		if wsConn != nil {
+			if err := recover(); err != nil {
+				wsConn.WriteMessage(websocket.TextMessage, guac.NewInstruction("error", anyx.ToString(err), "512").Byte())
+			}
+			wsConn.Close()
+		}
+	}()
+	biz.ErrIsNilAppendErr(err, "升级websocket失败: %s")
+
+	// 与查看终端回放记录权限一致
+	rc := req.NewCtxWithGin(g).WithRequiredPermission(req.NewPermission("machine:update"))
+	if err = req.PermissionHandler(rc); err != nil {
+		panic(errorx.NewBiz("您没有权限查看该回放记录, 请重新登录后再试~"))
+	}
+
+	termOp, err := m.MachineTermOpApp.GetById(uint64(rc.PathParamInt("recId")))
+	biz.ErrIsNil(err, "终端操作记录不存在")
+	biz.IsTrue(termOp.MachineId == GetMachineId(rc), "终端操作记录不存在")
+	machine, err := m.MachineApp.GetById(termOp.MachineId)
+	biz.ErrIsNil(err, "机器信息不存在")
+	biz.ErrIsNilAppendErr(m.TagApp.CanAccess(rc.GetLoginAccount().Id, m.TagApp.ListTagPathByTypeAndCode(int8(tagentity.TagTypeMachine), machine.Code)...), "%s")
+
+	speed := cast.ToFloat64(rc.Query("speed"))
+	if speed == 0 {
+		speed = 1
+	}
+	biz.IsTrue(speed >= guac.MinReplaySpeed && speed <= guac.MaxReplaySpeed, "回放倍速需在%v~%v之间", guac.MinReplaySpeed, guac.MaxReplaySpeed)
+
+	// 客户端断开连接时结束回放
+	ctx, cancel := context.WithCancel(rc.MetaCtx)
+	defer cancel()
+	go func() {
+		for {
+			if _, _, err := wsConn.ReadMessage(); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	err = m.MachineTermOpApp.ReplayGuacTermOp(ctx, termOp, speed, func(data []byte) error {
+		return wsConn.WriteMessage(websocket.TextMessage, data)
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		logx.Warnf("回放图形化会话记录[%d]失败: %s", termOp.Id, err.Error())
+	}
+}
+
 func GetMachineId(rc *req.Ctx) uint64 {
 	machineId, _ := strconv.Atoi(rc.PathParam("machineId"))
 	biz.IsTrue(machineId != 0, "machineId错误")
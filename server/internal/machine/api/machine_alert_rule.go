@@ -0,0 +1,50 @@
+package api
+
+import (
+	"mayfly-go/internal/machine/api/form"
+	"mayfly-go/internal/machine/api/vo"
+	"mayfly-go/internal/machine/application"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+
+	tagapp "mayfly-go/internal/tag/application"
+	tagentity "mayfly-go/internal/tag/domain/entity"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+)
+
+type MachineAlertRule struct {
+	MachineAlertRuleApp application.MachineAlertRule `inject:""`
+	TagTreeRelateApp    tagapp.TagTreeRelate         `inject:"TagTreeRelateApp"`
+}
+
+func (m *MachineAlertRule) MachineAlertRules(rc *req.Ctx) {
+	cond := req.BindQuery(rc, new(entity.MachineAlertRule))
+
+	var vos []*vo.MachineAlertRuleVO
+	err := m.MachineAlertRuleApp.ListByCondToAny(cond, &vos)
+	biz.ErrIsNil(err)
+
+	m.TagTreeRelateApp.FillTagInfo(tagentity.TagRelateTypeMachineAlert, collx.ArrayMap(vos, func(mvo *vo.MachineAlertRuleVO) tagentity.IRelateTag {
+		return mvo
+	})...)
+
+	rc.ResData = vos
+}
+
+func (m *MachineAlertRule) Save(rc *req.Ctx) {
+	ruleForm := new(form.MachineAlertRuleForm)
+	rule := req.BindJsonAndCopyTo[*entity.MachineAlertRule](rc, ruleForm, new(entity.MachineAlertRule))
+	rc.ReqParam = ruleForm
+
+	err := m.MachineAlertRuleApp.SaveAlertRule(rc.MetaCtx, &dto.SaveMachineAlertRule{
+		AlertRule: rule,
+		CodePaths: ruleForm.CodePaths,
+	})
+	biz.ErrIsNil(err)
+}
+
+func (m *MachineAlertRule) Delete(rc *req.Ctx) {
+	biz.ErrIsNil(m.MachineAlertRuleApp.DeleteAlertRule(rc.MetaCtx, uint64(rc.PathParamInt("id"))))
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"mayfly-go/internal/common/consts"
+	"mayfly-go/internal/machine/api/form"
+	"mayfly-go/internal/machine/application"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	tagapp "mayfly-go/internal/tag/application"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/req"
+)
+
+type MachineBatchExec struct {
+	MachineBatchExecApp application.MachineBatchExec `inject:""`
+	TagApp              tagapp.TagTree               `inject:"TagTreeApp"`
+}
+
+func (m *MachineBatchExec) BatchExecs(rc *req.Ctx) {
+	cond, pageParam := req.BindQueryAndPage(rc, new(entity.MachineBatchExec))
+	// 非管理员仅可查看自己创建的任务
+	if laId := rc.GetLoginAccount().Id; laId != consts.AdminId {
+		cond.CreatorId = laId
+	}
+	res, err := m.MachineBatchExecApp.GetPageList(cond, pageParam, new([]entity.MachineBatchExec), "id DESC")
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+// 获取批量执行任务中各机器的执行结果
+func (m *MachineBatchExec) BatchExecResults(rc *req.Ctx) {
+	res, err := m.MachineBatchExecApp.GetExecResults(rc.MetaCtx, uint64(rc.PathParamInt("id")))
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+// 在指定标签下的所有机器上执行命令
+func (m *MachineBatchExec) Run(rc *req.Ctx) {
+	execForm := req.BindJsonAndValid(rc, new(form.MachineBatchExecForm))
+	rc.ReqParam = execForm
+
+	la := rc.GetLoginAccount()
+	for _, tagPath := range execForm.TagPaths {
+		biz.ErrIsNilAppendErr(m.TagApp.CanAccess(la.Id, tagPath), "%s")
+	}
+
+	batchExec, err := m.MachineBatchExecApp.Run(rc.MetaCtx, &dto.MachineBatchExec{
+		Name:        execForm.Name,
+		Script:      execForm.Script,
+		TagPaths:    execForm.TagPaths,
+		Concurrency: execForm.Concurrency,
+		Timeout:     execForm.Timeout,
+		ClientId:    execForm.ClientId,
+	})
+	biz.ErrIsNil(err)
+	rc.ResData = batchExec
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+)
+
+// 获取当前账号可审批的待审批终端命令
+func (m *Machine) PendingCmdApprovals(rc *req.Ctx) {
+	rc.ResData = m.MachineTermOpApp.GetPendingCmdApprovals(rc.MetaCtx)
+}
+
+func (m *Machine) ApproveCmd(rc *req.Ctx) {
+	id := rc.PathParam("id")
+	rc.ReqParam = collx.Kvs("id", id, "pass", true)
+	biz.ErrIsNil(m.MachineTermOpApp.ApproveCmd(rc.MetaCtx, id, true))
+}
+
+func (m *Machine) RejectCmd(rc *req.Ctx) {
+	id := rc.PathParam("id")
+	rc.ReqParam = collx.Kvs("id", id, "pass", false)
+	biz.ErrIsNil(m.MachineTermOpApp.ApproveCmd(rc.MetaCtx, id, false))
+}
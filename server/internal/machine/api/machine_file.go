@@ -13,6 +13,7 @@ import (
 	"mayfly-go/internal/machine/mcm"
 	msgapp "mayfly-go/internal/msg/application"
 	msgdto "mayfly-go/internal/msg/application/dto"
+	tagapp "mayfly-go/internal/tag/application"
 	"mayfly-go/pkg/biz"
 	"mayfly-go/pkg/errorx"
 	"mayfly-go/pkg/logx"
@@ -35,6 +36,7 @@ type MachineFile struct {
 	MachineApp     application.Machine     `inject:""`
 	MachineFileApp application.MachineFile `inject:""`
 	MsgApp         msgapp.Msg              `inject:""`
+	TagApp         tagapp.TagTree          `inject:"TagTreeApp"`
 }
 
 const (
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"fmt"
+	"mayfly-go/internal/machine/api/form"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/config"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/may-fly/cast"
+)
+
+// 获取分片上传已上传的大小，客户端从该位置继续上传
+func (m *MachineFile) ChunkUploadOffset(rc *req.Ctx) {
+	opForm := req.BindQuery(rc, new(dto.MachineFileOp))
+	filename := rc.Query("filename")
+
+	offset, err := m.MachineFileApp.GetChunkUploadOffset(rc.MetaCtx, opForm, filename)
+	biz.ErrIsNilAppendErr(err, "获取已上传大小失败: %s")
+	rc.ResData = collx.M{"offset": offset}
+}
+
+func (m *MachineFile) UploadChunk(rc *req.Ctx) {
+	opForm := &dto.MachineFileOp{
+		MachineId:    cast.ToUint64(rc.PostForm("machineId")),
+		Protocol:     cast.ToInt(rc.PostForm("protocol")),
+		AuthCertName: rc.PostForm("authCertName"),
+		Path:         rc.PostForm("path"),
+	}
+	filename := rc.PostForm("filename")
+	offset := cast.ToInt64(rc.PostForm("offset"))
+
+	fileheader, err := rc.FormFile("file")
+	biz.ErrIsNilAppendErr(err, "读取分片失败: %s")
+	maxUploadFileSize := config.GetMachine().UploadMaxFileSize
+	biz.IsTrue(fileheader.Size <= maxUploadFileSize, "分片大小不能超过%d字节", maxUploadFileSize)
+
+	file, err := fileheader.Open()
+	biz.ErrIsNilAppendErr(err, "读取分片失败: %s")
+	defer file.Close()
+
+	newOffset, err := m.MachineFileApp.UploadChunk(rc.MetaCtx, opForm, filename, offset, file)
+	biz.ErrIsNilAppendErr(err, "分片上传失败: %s")
+	rc.ResData = collx.M{"offset": newOffset}
+}
+
+func (m *MachineFile) CompleteChunkUpload(rc *req.Ctx) {
+	completeForm := req.BindJsonAndValid(rc, new(form.MachineFileChunkCompleteForm))
+	opForm := &dto.MachineFileOp{
+		MachineId:    completeForm.MachineId,
+		Protocol:     completeForm.Protocol,
+		AuthCertName: completeForm.AuthCertName,
+		Path:         completeForm.Path,
+	}
+
+	mi, err := m.MachineFileApp.CompleteChunkUpload(rc.MetaCtx, opForm, completeForm.Filename, completeForm.Size, completeForm.Checksum)
+	rc.ReqParam = collx.Kvs("machine", mi, "path", path.Join(completeForm.Path, completeForm.Filename), "size", completeForm.Size, "checksum", completeForm.Checksum)
+	biz.ErrIsNilAppendErr(err, "文件上传失败: %s")
+	m.MsgApp.CreateAndSend(rc.GetLoginAccount(), msgdto.SuccessSysMsg("文件上传成功", fmt.Sprintf("[%s]文件已成功上传至 %s[%s:%s]", completeForm.Filename, mi.Name, mi.Ip, completeForm.Path)))
+}
+
+// 将目录打包为tar.gz下载
+func (m *MachineFile) DownloadDir(rc *req.Ctx) {
+	opForm := req.BindQuery(rc, new(dto.MachineFileOp))
+	biz.NotEmpty(opForm.Path, "目录路径不能为空")
+
+	rc.Header("Content-Type", "application/gzip")
+	rc.Header("Content-Disposition", "attachment; filename="+path.Base(path.Clean(opForm.Path))+".tar.gz")
+
+	mi, err := m.MachineFileApp.DownloadDirAsTarGz(rc.MetaCtx, opForm, rc.GetWriter())
+	rc.ReqParam = collx.Kvs("machine", mi, "path", opForm.Path)
+	if err == nil {
+		return
+	}
+	// 未写出任何内容时可直接返回错误信息，否则响应已部分写出，只能记录日志
+	if w, ok := rc.GetWriter().(gin.ResponseWriter); ok && !w.Written() {
+		rc.Header("Content-Disposition", "")
+		biz.ErrIsNilAppendErr(err, "目录打包下载失败: %s")
+	}
+	logx.Errorf("目录[%s]打包下载失败: %s", opForm.Path, err.Error())
+}
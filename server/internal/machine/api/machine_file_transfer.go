@@ -0,0 +1,40 @@
+package api
+
+import (
+	"mayfly-go/internal/machine/api/form"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+)
+
+// 将文件从当前机器直接传输至其他机器
+func (m *MachineFile) TransferFile(rc *req.Ctx) {
+	transferForm := req.BindJsonAndValid(rc, new(form.MachineFileTransferForm))
+	rc.ReqParam = transferForm
+
+	la := rc.GetLoginAccount()
+	for _, ac := range []string{transferForm.AuthCertName, transferForm.TargetAuthCertName} {
+		cli, err := m.MachineFileApp.GetMachineCli(ac)
+		biz.ErrIsNilAppendErr(err, "获取机器连接失败: %s")
+		biz.ErrIsNilAppendErr(m.TagApp.CanAccess(la.Id, cli.Info.CodePath...), "%s")
+	}
+
+	transferId, err := m.MachineFileApp.TransferFile(rc.MetaCtx, &dto.MachineFileTransfer{
+		Src: &dto.MachineFileOp{
+			MachineId:    transferForm.MachineId,
+			Protocol:     transferForm.Protocol,
+			AuthCertName: transferForm.AuthCertName,
+			Path:         transferForm.Path,
+		},
+		Target: &dto.MachineFileOp{
+			MachineId:    transferForm.TargetMachineId,
+			Protocol:     transferForm.TargetProtocol,
+			AuthCertName: transferForm.TargetAuthCertName,
+			Path:         transferForm.TargetPath,
+		},
+		ClientId: transferForm.ClientId,
+	})
+	biz.ErrIsNilAppendErr(err, "文件传输失败: %s")
+	rc.ResData = collx.M{"transferId": transferId}
+}
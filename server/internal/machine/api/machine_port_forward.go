@@ -0,0 +1,82 @@
+package api
+
+import (
+	"mayfly-go/internal/machine/api/form"
+	"mayfly-go/internal/machine/application"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	tagapp "mayfly-go/internal/tag/application"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+)
+
+type MachinePortForward struct {
+	MachinePortForwardApp application.MachinePortForward `inject:""`
+	MachineApp            application.Machine            `inject:""`
+	TagApp                tagapp.TagTree                 `inject:"TagTreeApp"`
+}
+
+// 获取所有端口转发审计记录
+func (m *MachinePortForward) PortForwards(rc *req.Ctx) {
+	cond, pageParam := req.BindQueryAndPage(rc, new(entity.MachinePortForwardQuery))
+	res, err := m.MachinePortForwardApp.GetPageList(cond, pageParam, new([]entity.MachinePortForward), "id DESC")
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+// 获取当前账号的端口转发审计记录
+func (m *MachinePortForward) MyPortForwards(rc *req.Ctx) {
+	cond, pageParam := req.BindQueryAndPage(rc, new(entity.MachinePortForwardQuery))
+	cond.Creator = ""
+	cond.CreatorId = rc.GetLoginAccount().Id
+	res, err := m.MachinePortForwardApp.GetPageList(cond, pageParam, new([]entity.MachinePortForward), "id DESC")
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+// 获取所有活跃的端口转发会话
+func (m *MachinePortForward) ActivePortForwards(rc *req.Ctx) {
+	rc.ResData = m.MachinePortForwardApp.GetActivePortForwards(0)
+}
+
+// 获取当前账号活跃的端口转发会话
+func (m *MachinePortForward) MyActivePortForwards(rc *req.Ctx) {
+	rc.ResData = m.MachinePortForwardApp.GetActivePortForwards(rc.GetLoginAccount().Id)
+}
+
+func (m *MachinePortForward) OpenPortForward(rc *req.Ctx) {
+	pfForm := req.BindJsonAndValid(rc, new(form.MachinePortForwardForm))
+	rc.ReqParam = pfForm
+
+	mi, err := m.MachineApp.ToMachineInfoByAc(pfForm.AuthCertName)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(m.TagApp.CanAccess(rc.GetLoginAccount().Id, mi.CodePath...), "%s")
+
+	cli, err := mi.Conn()
+	biz.ErrIsNilAppendErr(err, "机器连接失败: %s")
+
+	pf, err := m.MachinePortForwardApp.OpenPortForward(rc.MetaCtx, cli, &dto.MachinePortForward{
+		RemoteHost: pfForm.RemoteHost,
+		RemotePort: pfForm.RemotePort,
+		LocalPort:  pfForm.LocalPort,
+		Duration:   pfForm.Duration,
+		ClientIp:   rc.ClientIP(),
+	})
+	biz.ErrIsNil(err)
+	rc.ResData = pf
+}
+
+// 关闭自己开启的端口转发
+func (m *MachinePortForward) ClosePortForward(rc *req.Ctx) {
+	id := uint64(rc.PathParamInt("id"))
+	rc.ReqParam = collx.Kvs("id", id)
+	biz.ErrIsNil(m.MachinePortForwardApp.ClosePortForward(rc.MetaCtx, id, false))
+}
+
+// 强制关闭任意端口转发
+func (m *MachinePortForward) TerminatePortForward(rc *req.Ctx) {
+	id := uint64(rc.PathParamInt("id"))
+	rc.ReqParam = collx.Kvs("id", id)
+	biz.ErrIsNil(m.MachinePortForwardApp.ClosePortForward(rc.MetaCtx, id, true))
+}
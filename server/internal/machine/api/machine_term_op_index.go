@@ -0,0 +1,40 @@
+package api
+
+import (
+	"mayfly-go/internal/machine/api/vo"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+)
+
+// 检索终端操作记录中执行的命令及终端输出，结果可通过termOpId及recOffset定位至回放时间点
+func (m *Machine) SearchTermOpRecords(rc *req.Ctx) {
+	condition, pageParam := req.BindQueryAndPage(rc, new(entity.MachineTermOpIndexQuery))
+	biz.NotEmpty(condition.Keyword, "检索关键字不能为空")
+
+	var indexes []*vo.MachineTermOpIndexVO
+	res, err := m.MachineTermOpIndexApp.GetPageList(condition, pageParam, &indexes)
+	biz.ErrIsNil(err)
+
+	machineIds := collx.ArrayDeduplicate(collx.ArrayMap(indexes, func(i *vo.MachineTermOpIndexVO) uint64 { return i.MachineId }))
+	if len(machineIds) > 0 {
+		machines, _ := m.MachineApp.GetByIds(machineIds, "id", "name")
+		machineNames := make(map[uint64]string, len(machines))
+		for _, machine := range machines {
+			machineNames[machine.Id] = machine.Name
+		}
+		for _, index := range indexes {
+			index.MachineName = machineNames[index.MachineId]
+		}
+	}
+	rc.ResData = res
+}
+
+// 重建终端操作记录的检索索引
+func (m *Machine) IndexTermOpRecord(rc *req.Ctx) {
+	termOp, err := m.MachineTermOpApp.GetById(uint64(rc.PathParamInt("recId")))
+	biz.ErrIsNil(err, "终端操作记录不存在")
+	rc.ReqParam = collx.Kvs("termOpId", termOp.Id, "machineId", termOp.MachineId)
+	biz.ErrIsNilAppendErr(m.MachineTermOpIndexApp.IndexTermOp(rc.MetaCtx, termOp), "建立索引失败: %s")
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"mayfly-go/internal/machine/api/form"
+	"mayfly-go/internal/machine/mcm"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/ws"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// 终端会话监控权限，可查看所有终端会话、只读附加及强制终止会话
+const termSessionMonitorPermCode = "machine:terminal:monitor"
+
+// 获取所有活跃的终端会话
+func (m *Machine) TermSessions(rc *req.Ctx) {
+	rc.ResData = m.MachineTermOpApp.GetTermSessions(0)
+}
+
+// 获取当前账号拥有或受邀协作的终端会话
+func (m *Machine) MyTermSessions(rc *req.Ctx) {
+	rc.ResData = m.MachineTermOpApp.GetTermSessions(rc.GetLoginAccount().Id)
+}
+
+func (m *Machine) InviteTermSessionCollaborator(rc *req.Ctx) {
+	inviteForm := req.BindJsonAndValid(rc, new(form.TermSessionInviteForm))
+	sessionId := rc.PathParam("sessionId")
+	rc.ReqParam = collx.Kvs("sessionId", sessionId, "username", inviteForm.Username)
+	biz.ErrIsNil(m.MachineTermOpApp.InviteTermSessionCollaborator(rc.MetaCtx, sessionId, inviteForm.Username))
+}
+
+func (m *Machine) RemoveTermSessionCollaborator(rc *req.Ctx) {
+	sessionId := rc.PathParam("sessionId")
+	accountId := uint64(rc.PathParamInt("accountId"))
+	rc.ReqParam = collx.Kvs("sessionId", sessionId, "accountId", accountId)
+	biz.ErrIsNil(m.MachineTermOpApp.RemoveTermSessionCollaborator(rc.MetaCtx, sessionId, accountId))
+}
+
+func (m *Machine) TerminateTermSession(rc *req.Ctx) {
+	sessionId := rc.PathParam("sessionId")
+	reason := rc.Query("reason")
+	rc.ReqParam = collx.Kvs("sessionId", sessionId, "reason", reason)
+	biz.ErrIsNil(m.MachineTermOpApp.TerminateTermSession(rc.MetaCtx, sessionId, reason))
+}
+
+// 附加至其他用户的终端会话，受邀协作者可写入终端，具有监控权限的用户只读
+func (m *Machine) WsAttachTermSession(g *gin.Context) {
+	wsConn, err := ws.Upgrader.Upgrade(g.Writer, g.Request, nil)
+	defer func() {
+		if wsConn != nil {
+			if err := recover(); err != nil {
+				wsConn.WriteMessage(websocket.TextMessage, []byte(anyx.ToString(err)))
+			}
+			wsConn.Close()
+		}
+	}()
+	biz.ErrIsNilAppendErr(err, "升级websocket失败: %s")
+
+	// 权限校验
+	rc := req.NewCtxWithGin(g).WithRequiredPermission(req.NewPermission("machine:terminal"))
+	if err = req.PermissionHandler(rc); err != nil {
+		panic(errorx.NewBiz(mcm.GetErrorContentRn("您没有权限操作该机器终端,请重新登录后再试~")))
+	}
+
+	sessionId := rc.PathParam("sessionId")
+	// 记录系统操作日志
+	rc.WithLog(req.NewLogSave("机器-附加终端会话"))
+	rc.ReqParam = collx.Kvs("sessionId", sessionId)
+	req.LogHandler(rc)
+
+	monitor := req.HasPermissionCode(rc.GetLoginAccount().Id, termSessionMonitorPermCode)
+	err = m.MachineTermOpApp.AttachTermSession(rc.MetaCtx, sessionId, wsConn, monitor)
+	biz.ErrIsNilAppendErr(err, mcm.GetErrorContentRn("附加终端会话失败: %s"))
+}
@@ -57,6 +57,13 @@ type MachineCronJobVO struct {
 	SaveExecResType int    `json:"saveExecResType"`
 	Remark          string `json:"remark"`
 	Running         bool   `json:"running" gorm:"-"` // 是否运行中
+
+	Timeout             int                 `json:"timeout"`
+	RetryCount          int                 `json:"retryCount"`
+	RetryInterval       int                 `json:"retryInterval"`
+	SkipIfRunning       int8                `json:"skipIfRunning"`
+	FailNotifyThreshold int                 `json:"failNotifyThreshold"`
+	Receivers           model.Slice[string] `json:"receivers"`
 }
 
 func (mcj *MachineCronJobVO) GetRelateId() uint64 {
@@ -100,13 +107,44 @@ type MachineCmdConfVO struct {
 	tagentity.RelateTags // 标签信息
 	model.Model
 
-	Name     string              `json:"name"`
-	Cmds     model.Slice[string] `json:"cmds"`     // 命令配置
-	Status   int8                `json:"execCmds"` // 状态
-	Stratege string              `json:"stratege"` // 策略，空禁用
-	Remark   string              `json:"remark"`   // 备注
+	Name      string              `json:"name"`
+	Cmds      model.Slice[string] `json:"cmds"`      // 命令配置
+	Status    int8                `json:"execCmds"`  // 状态
+	Stratege  string              `json:"stratege"`  // 策略，空禁用
+	Receivers model.Slice[string] `json:"receivers"` // 告警接收人或审批人用户名
+	Remark    string              `json:"remark"`    // 备注
 }
 
 func (mcc *MachineCmdConfVO) GetRelateId() uint64 {
 	return mcc.Id
 }
+
+type MachineAlertRuleVO struct {
+	tagentity.RelateTags // 标签信息
+	model.Model
+
+	Name      string              `json:"name"`
+	Metric    string              `json:"metric"`
+	Threshold float32             `json:"threshold"`
+	Duration  int                 `json:"duration"`
+	Receivers model.Slice[string] `json:"receivers"`
+	Status    int8                `json:"status"`
+	Remark    string              `json:"remark"`
+}
+
+func (mar *MachineAlertRuleVO) GetRelateId() uint64 {
+	return mar.Id
+}
+
+// 终端操作记录检索结果
+type MachineTermOpIndexVO struct {
+	Id          uint64     `json:"id"`
+	TermOpId    uint64     `json:"termOpId"`
+	MachineId   uint64     `json:"machineId"`
+	MachineName string     `json:"machineName" gorm:"-"`
+	Creator     string     `json:"creator"`
+	Type        int8       `json:"type"`
+	Content     string     `json:"content"`
+	RecOffset   float64    `json:"recOffset"` // 距回放开始的秒数，用于回放时定位
+	OpTime      *time.Time `json:"opTime"`
+}
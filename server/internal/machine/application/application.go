@@ -9,8 +9,13 @@ func InitIoc() {
 	ioc.Register(new(machineFileAppImpl), ioc.WithComponentName("MachineFileApp"))
 	ioc.Register(new(machineScriptAppImpl), ioc.WithComponentName("MachineScriptApp"))
 	ioc.Register(new(machineCronJobAppImpl), ioc.WithComponentName("MachineCronJobApp"))
+	ioc.Register(new(machineBatchExecAppImpl), ioc.WithComponentName("MachineBatchExecApp"))
 	ioc.Register(new(machineTermOpAppImpl), ioc.WithComponentName("MachineTermOpApp"))
+	ioc.Register(new(machineTermOpIndexAppImpl), ioc.WithComponentName("MachineTermOpIndexApp"))
 	ioc.Register(new(machineCmdConfAppImpl), ioc.WithComponentName("MachineCmdConfApp"))
+	ioc.Register(new(machineMonitorAppImpl), ioc.WithComponentName("MachineMonitorApp"))
+	ioc.Register(new(machineAlertRuleAppImpl), ioc.WithComponentName("MachineAlertRuleApp"))
+	ioc.Register(new(machinePortForwardAppImpl), ioc.WithComponentName("MachinePortForwardApp"))
 }
 
 func GetMachineApp() Machine {
@@ -29,6 +34,18 @@ func GetMachineCronJobApp() MachineCronJob {
 	return ioc.Get[MachineCronJob]("MachineCronJobApp")
 }
 
+func GetMachineBatchExecApp() MachineBatchExec {
+	return ioc.Get[MachineBatchExec]("MachineBatchExecApp")
+}
+
 func GetMachineTermOpApp() MachineTermOp {
 	return ioc.Get[MachineTermOp]("MachineTermOpApp")
 }
+
+func GetMachineMonitorApp() MachineMonitor {
+	return ioc.Get[MachineMonitor]("MachineMonitorApp")
+}
+
+func GetMachinePortForwardApp() MachinePortForward {
+	return ioc.Get[MachinePortForward]("MachinePortForwardApp")
+}
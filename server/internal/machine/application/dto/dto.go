@@ -3,6 +3,7 @@ package dto
 import (
 	"mayfly-go/internal/machine/domain/entity"
 	tagentity "mayfly-go/internal/tag/domain/entity"
+	"time"
 )
 
 type SaveMachine struct {
@@ -18,6 +19,13 @@ type MachineFileOp struct {
 	Path         string `json:"path" form:"path"`                                     // 文件路径
 }
 
+// 机器间文件传输
+type MachineFileTransfer struct {
+	Src      *MachineFileOp // 源机器文件
+	Target   *MachineFileOp // 目标机器及目录
+	ClientId string         // 接收传输进度的websocket客户端id
+}
+
 type SaveMachineCmdConf struct {
 	CmdConf   *entity.MachineCmdConf
 	CodePaths []string
@@ -27,3 +35,39 @@ type SaveMachineCronJob struct {
 	CronJob   *entity.MachineCronJob
 	CodePaths []string
 }
+
+type MachineBatchExec struct {
+	Name        string
+	Script      string
+	TagPaths    []string
+	Concurrency int    // 并发执行的机器数
+	Timeout     int    // 单台机器执行超时时间(秒)
+	ClientId    string // 接收实时输出的websocket客户端id
+}
+
+type SaveMachineAlertRule struct {
+	AlertRule *entity.MachineAlertRule
+	CodePaths []string
+}
+
+// 待审批的终端命令
+type MachineCmdApproval struct {
+	Id          string    `json:"id"`
+	SessionId   string    `json:"sessionId"`
+	MachineId   uint64    `json:"machineId"`
+	MachineName string    `json:"machineName"`
+	Ip          string    `json:"ip"`
+	Cmd         string    `json:"cmd"`
+	ConfName    string    `json:"confName"`  // 匹配的命令配置名称
+	Applicant   string    `json:"applicant"` // 执行命令的用户
+	Approvers   []string  `json:"approvers"`
+	CreateTime  time.Time `json:"createTime"`
+}
+
+type MachinePortForward struct {
+	RemoteHost string // 机器所在网络中的目标地址
+	RemotePort int
+	LocalPort  int    // mayfly-go服务端监听端口，为0则随机选取
+	Duration   int    // 有效时长(分钟)
+	ClientIp   string // 开启端口转发的客户端ip，只接受该ip的连接
+}
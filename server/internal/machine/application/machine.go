@@ -17,8 +17,10 @@ import (
 	"mayfly-go/pkg/global"
 	"mayfly-go/pkg/logx"
 	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/rediscli"
 	"mayfly-go/pkg/scheduler"
 	"mayfly-go/pkg/utils/collx"
+	"time"
 )
 
 type Machine interface {
@@ -56,6 +58,9 @@ type Machine interface {
 	GetMachineStats(machineId uint64) (*mcm.Stats, error)
 
 	ToMachineInfoByAc(ac string) (*mcm.MachineInfo, error)
+
+	// 根据机器id获取机器信息（使用默认授权凭证）
+	ToMachineInfoById(machineId uint64) (*mcm.MachineInfo, error)
 }
 
 type machineAppImpl struct {
@@ -63,6 +68,7 @@ type machineAppImpl struct {
 
 	tagApp              tagapp.TagTree          `inject:"TagTreeApp"`
 	resourceAuthCertApp tagapp.ResourceAuthCert `inject:"ResourceAuthCertApp"`
+	machineMonitorApp   MachineMonitor          `inject:"MachineMonitorApp"`
 }
 
 var _ (Machine) = (*machineAppImpl)(nil)
@@ -251,9 +257,16 @@ func (m *machineAppImpl) GetSshTunnelMachine(machineId int) (*mcm.SshTunnelMachi
 	})
 }
 
+const updateStatsJobKey = "machine:stats:update"
+
 func (m *machineAppImpl) TimerUpdateStats() {
 	logx.Debug("开始定时收集并缓存服务器状态信息...")
 	scheduler.AddFun("@every 2m", func() {
+		// 简单使用redis分布式锁防止多实例同一时刻重复采集并保存监控记录，采集为异步执行，故不主动释放锁，待其自动过期
+		if lock := rediscli.NewLock(updateStatsJobKey, 60*time.Second); lock != nil && !lock.Lock() {
+			return
+		}
+
 		machineIds, _ := m.ListByCond(model.NewModelCond(&entity.Machine{Status: entity.MachineStatusEnable, Protocol: entity.MachineProtocolSsh}).Columns("id"))
 		for _, ma := range machineIds {
 			go func(mid uint64) {
@@ -268,7 +281,11 @@ func (m *machineAppImpl) TimerUpdateStats() {
 					logx.Errorf("定时获取机器[id=%d]状态信息失败, 获取机器cli失败: %s", mid, err.Error())
 					return
 				}
-				cache.SaveMachineStats(mid, cli.GetAllStats())
+				stats := cli.GetAllStats()
+				cache.SaveMachineStats(mid, stats)
+				if err := m.machineMonitorApp.SaveStats(context.Background(), cli.Info, stats); err != nil {
+					logx.Errorf("保存机器[id=%d]监控记录失败: %s", mid, err.Error())
+				}
 				logx.Debugf("定时获取机器[id=%d]状态信息结束", mid)
 			}(ma.Id)
 		}
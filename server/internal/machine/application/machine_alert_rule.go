@@ -0,0 +1,155 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/internal/machine/infrastructure/cache"
+	"mayfly-go/internal/machine/mcm"
+	msgapp "mayfly-go/internal/msg/application"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	sysapp "mayfly-go/internal/sys/application"
+	tagapp "mayfly-go/internal/tag/application"
+	tagentity "mayfly-go/internal/tag/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"time"
+)
+
+type MachineAlertRule interface {
+	base.App[*entity.MachineAlertRule]
+
+	SaveAlertRule(ctx context.Context, param *dto.SaveMachineAlertRule) error
+
+	DeleteAlertRule(ctx context.Context, id uint64) error
+
+	// 根据机器监控记录校验关联的告警规则，指标持续超出阈值则通知告警接收人
+	CheckAlert(ctx context.Context, mi *mcm.MachineInfo, mm *entity.MachineMonitor)
+}
+
+type machineAlertRuleAppImpl struct {
+	base.AppImpl[*entity.MachineAlertRule, repository.MachineAlertRule]
+
+	tagTreeRelateApp tagapp.TagTreeRelate `inject:"TagTreeRelateApp"`
+	accountApp       sysapp.Account       `inject:"AccountApp"`
+	msgApp           msgapp.Msg           `inject:"MsgApp"`
+}
+
+var _ (MachineAlertRule) = (*machineAlertRuleAppImpl)(nil)
+
+// 注入MachineAlertRuleRepo
+func (m *machineAlertRuleAppImpl) InjectMachineAlertRuleRepo(repo repository.MachineAlertRule) {
+	m.Repo = repo
+}
+
+func (m *machineAlertRuleAppImpl) SaveAlertRule(ctx context.Context, param *dto.SaveMachineAlertRule) error {
+	alertRule := param.AlertRule
+	if alertRule.Metric.Name() == "" {
+		return errorx.NewBiz("不支持的告警指标: %s", alertRule.Metric)
+	}
+	if alertRule.Threshold <= 0 {
+		return errorx.NewBiz("告警阈值必须大于0")
+	}
+	if alertRule.Duration < 0 {
+		return errorx.NewBiz("持续时间不能小于0")
+	}
+	if len(alertRule.Receivers) == 0 {
+		return errorx.NewBiz("告警接收人不能为空")
+	}
+
+	return m.Tx(ctx, func(ctx context.Context) error {
+		return m.Save(ctx, alertRule)
+	}, func(ctx context.Context) error {
+		return m.tagTreeRelateApp.RelateTag(ctx, tagentity.TagRelateTypeMachineAlert, alertRule.Id, param.CodePaths...)
+	})
+}
+
+func (m *machineAlertRuleAppImpl) DeleteAlertRule(ctx context.Context, id uint64) error {
+	_, err := m.GetById(id)
+	if err != nil {
+		return errorx.NewBiz("该告警规则不存在")
+	}
+
+	return m.Tx(ctx, func(ctx context.Context) error {
+		return m.DeleteById(ctx, id)
+	}, func(ctx context.Context) error {
+		return m.tagTreeRelateApp.DeleteByCond(ctx, &tagentity.TagTreeRelate{
+			RelateType: tagentity.TagRelateTypeMachineAlert,
+			RelateId:   id,
+		})
+	})
+}
+
+func (m *machineAlertRuleAppImpl) CheckAlert(ctx context.Context, mi *mcm.MachineInfo, mm *entity.MachineMonitor) {
+	ruleIds, err := m.tagTreeRelateApp.GetRelateIds(ctx, tagentity.TagRelateTypeMachineAlert, mi.CodePath...)
+	if err != nil {
+		logx.Errorf("获取机器告警规则失败: %s", err.Error())
+		return
+	}
+	if len(ruleIds) == 0 {
+		return
+	}
+
+	rules, _ := m.GetByIds(ruleIds)
+	for _, rule := range rules {
+		if rule.Status != entity.MachineAlertRuleStatusEnable {
+			continue
+		}
+		m.checkRule(rule, mi, mm)
+	}
+}
+
+func (m *machineAlertRuleAppImpl) checkRule(rule *entity.MachineAlertRule, mi *mcm.MachineInfo, mm *entity.MachineMonitor) {
+	value := rule.Metric.Value(mm)
+	state := cache.GetMachineAlertState(rule.Id, mi.Id)
+
+	// 指标恢复正常，若已告警则发送恢复通知
+	if value < rule.Threshold {
+		if state == nil {
+			return
+		}
+		cache.DelMachineAlertState(rule.Id, mi.Id)
+		if state.Alerted {
+			m.notify(rule, msgdto.SuccessSysMsg("机器监控告警恢复",
+				fmt.Sprintf("机器[%s-%s]%s已恢复正常, 当前值: %.2f, 告警规则: %s", mi.Name, mi.Ip, rule.Metric.Name(), value, rule.Name)))
+		}
+		return
+	}
+
+	if state == nil {
+		state = &cache.MachineAlertState{FirstTime: mm.CreateTime}
+	}
+	if !state.Alerted && mm.CreateTime.Sub(state.FirstTime) >= time.Duration(rule.Duration)*time.Minute {
+		state.Alerted = true
+		m.notify(rule, msgdto.ErrSysMsg("机器监控告警",
+			fmt.Sprintf("机器[%s-%s]%s持续%d分钟超过阈值%.2f, 当前值: %.2f, 告警规则: %s", mi.Name, mi.Ip, rule.Metric.Name(), rule.Duration, rule.Threshold, value, rule.Name)))
+	}
+	// 采样中断一段时间后重新计算持续时间
+	if err := cache.SaveMachineAlertState(rule.Id, mi.Id, state, time.Duration(rule.Duration)*time.Minute+10*time.Minute); err != nil {
+		logx.Errorf("保存机器告警状态失败: %s", err.Error())
+	}
+}
+
+// 通知告警规则的所有接收人
+func (m *machineAlertRuleAppImpl) notify(rule *entity.MachineAlertRule, msg *msgdto.SysMsg) {
+	sendSysMsgByUsernames(m.accountApp, m.msgApp, rule.Receivers, msg)
+}
+
+// 根据用户名发送系统消息
+func sendSysMsgByUsernames(accountApp sysapp.Account, msgApp msgapp.Msg, usernames []string, msg *msgdto.SysMsg) {
+	if len(usernames) == 0 {
+		return
+	}
+	accounts, err := accountApp.ListByCond(model.NewCond().In("username", usernames), "id", "username")
+	if err != nil {
+		logx.Errorf("获取消息接收人失败: %s", err.Error())
+		return
+	}
+	for _, account := range accounts {
+		msgApp.CreateAndSend(&model.LoginAccount{Id: account.Id, Username: "system"}, msg)
+	}
+}
@@ -0,0 +1,314 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	tagapp "mayfly-go/internal/tag/application"
+	tagentity "mayfly-go/internal/tag/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/ws"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBatchExecConcurrency = 10
+	maxBatchExecConcurrency     = 100
+	defaultBatchExecTimeout     = 60
+	maxBatchExecTimeout         = 3600
+	// 保存的命令执行输出最大长度
+	maxExecOutputLen = 4000
+
+	// 批量执行实时消息类别
+	batchExecMsgCategory = "machineBatchExec"
+)
+
+// 批量执行实时推送的消息类型
+const (
+	batchExecMsgTypeOutput = "output" // 机器执行输出
+	batchExecMsgTypeResult = "result" // 单台机器执行结束
+	batchExecMsgTypeEnd    = "end"    // 批量执行任务结束
+)
+
+type MachineBatchExec interface {
+	base.App[*entity.MachineBatchExec]
+
+	// 分页获取批量执行任务列表
+	GetPageList(condition *entity.MachineBatchExec, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+
+	// 获取批量执行任务中各机器的执行结果，仅任务创建者或可访问任务所有标签的账号可获取
+	GetExecResults(ctx context.Context, batchExecId uint64) ([]*entity.MachineBatchExecRes, error)
+
+	// 在指定标签下启用的ssh机器上并发执行命令，执行输出通过websocket实时推送
+	Run(ctx context.Context, param *dto.MachineBatchExec) (*entity.MachineBatchExec, error)
+
+	// 将服务重启前未执行结束的任务置为失败
+	InitJob()
+}
+
+type machineBatchExecAppImpl struct {
+	base.AppImpl[*entity.MachineBatchExec, repository.MachineBatchExec]
+
+	machineBatchExecResRepo repository.MachineBatchExecRes `inject:"MachineBatchExecResRepo"`
+	machineApp              Machine                        `inject:"MachineApp"`
+	tagTreeApp              tagapp.TagTree                 `inject:"TagTreeApp"`
+	machineCmdConfApp       MachineCmdConf                 `inject:"MachineCmdConfApp"`
+}
+
+var _ (MachineBatchExec) = (*machineBatchExecAppImpl)(nil)
+
+// 注入MachineBatchExecRepo
+func (m *machineBatchExecAppImpl) InjectMachineBatchExecRepo(repo repository.MachineBatchExec) {
+	m.Repo = repo
+}
+
+func (m *machineBatchExecAppImpl) GetPageList(condition *entity.MachineBatchExec, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
+	return m.GetRepo().GetPageList(condition, pageParam, toEntity, orderBy...)
+}
+
+func (m *machineBatchExecAppImpl) GetExecResults(ctx context.Context, batchExecId uint64) ([]*entity.MachineBatchExecRes, error) {
+	batchExec, err := m.GetById(batchExecId)
+	if err != nil {
+		return nil, errorx.NewBiz("该批量执行任务不存在")
+	}
+	if la := contextx.GetLoginAccount(ctx); la.Id != batchExec.CreatorId {
+		for _, tagPath := range batchExec.TagPaths {
+			if err := m.tagTreeApp.CanAccess(la.Id, tagPath); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m.machineBatchExecResRepo.SelectByCond(model.NewCond().Eq("batch_exec_id", batchExecId).OrderByAsc("id"))
+}
+
+func (m *machineBatchExecAppImpl) Run(ctx context.Context, param *dto.MachineBatchExec) (*entity.MachineBatchExec, error) {
+	if len(param.TagPaths) == 0 {
+		return nil, errorx.NewBiz("标签不能为空")
+	}
+	if param.Concurrency <= 0 {
+		param.Concurrency = defaultBatchExecConcurrency
+	}
+	if param.Concurrency > maxBatchExecConcurrency {
+		return nil, errorx.NewBiz("并发数不能超过%d", maxBatchExecConcurrency)
+	}
+	if param.Timeout <= 0 {
+		param.Timeout = defaultBatchExecTimeout
+	}
+	if param.Timeout > maxBatchExecTimeout {
+		return nil, errorx.NewBiz("超时时间不能超过%d秒", maxBatchExecTimeout)
+	}
+
+	var machineTags []tagentity.TagTree
+	m.tagTreeApp.ListByQuery(&tagentity.TagTreeQuery{CodePathLikes: param.TagPaths, Type: tagentity.TagTypeMachine}, &machineTags)
+	// 仅在启用的ssh协议机器上执行
+	machines, err := m.machineApp.ListByCond(model.NewCond().In("code", collx.ArrayDeduplicate(collx.ArrayMap(machineTags, func(tag tagentity.TagTree) string {
+		return tag.Code
+	}))).Eq("status", entity.MachineStatusEnable).Eq("protocol", entity.MachineProtocolSsh), "id", "code", "name", "ip")
+	if err != nil {
+		return nil, err
+	}
+	if len(machines) == 0 {
+		return nil, errorx.NewBiz("所选标签下不存在启用的ssh机器")
+	}
+	for _, machine := range machines {
+		if err := m.checkScriptCmdConfs(ctx, machine, param.Script); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	batchExec := &entity.MachineBatchExec{
+		Name:         param.Name,
+		Script:       param.Script,
+		TagPaths:     param.TagPaths,
+		Concurrency:  param.Concurrency,
+		Timeout:      param.Timeout,
+		Status:       entity.MachineBatchExecStatusRunning,
+		MachineCount: len(machines),
+		StartTime:    &now,
+	}
+	if err := m.Insert(ctx, batchExec); err != nil {
+		return nil, err
+	}
+
+	la := contextx.GetLoginAccount(ctx)
+	go m.runBatchExec(contextx.NewLoginAccount(la), batchExec, machines, func(msg *batchExecMsg) {
+		ws.SendJsonMsg(ws.UserId(la.Id), param.ClientId, msgdto.InfoSysMsg("机器批量执行", msg).WithCategory(batchExecMsgCategory))
+	})
+	return batchExec, nil
+}
+
+// 校验脚本是否命中机器的命令配置，命中禁止或需审批的命令则不允许批量执行
+func (m *machineBatchExecAppImpl) checkScriptCmdConfs(ctx context.Context, machine *entity.Machine, script string) error {
+	mi, err := m.machineApp.ToMachineInfoById(machine.Id)
+	if err != nil {
+		return err
+	}
+	cmdConfs := m.machineCmdConfApp.GetCmdConfsByMachineTags(ctx, mi.CodePath...)
+	if len(cmdConfs) == 0 {
+		return nil
+	}
+
+	// 终端按单条命令过滤，故脚本需逐行及整体匹配
+	cmds := append(strings.Split(script, "\n"), script)
+	for _, cmdConf := range cmdConfs {
+		if cmdConf.Stratege != entity.MachineCmdStrategeDeny && cmdConf.Stratege != entity.MachineCmdStrategeApproval {
+			continue
+		}
+		for _, cmd := range cmds {
+			if cmd = strings.TrimSpace(cmd); cmd == "" || !cmdConf.CmdRegexp.MatchString(cmd) {
+				continue
+			}
+			if cmdConf.Stratege == entity.MachineCmdStrategeApproval {
+				return errorx.NewBiz("机器[%s]的命令[%s]命中命令规则[%s], 需审批后执行, 不支持批量执行", machine.Name, cmd, cmdConf.ConfName)
+			}
+			return errorx.NewBiz("机器[%s]的命令[%s]命中命令规则[%s], 已被禁用", machine.Name, cmd, cmdConf.ConfName)
+		}
+	}
+	return nil
+}
+
+func (m *machineBatchExecAppImpl) InitJob() {
+	now := time.Now()
+	m.UpdateByCond(context.TODO(), &entity.MachineBatchExec{Status: entity.MachineBatchExecStatusFail, EndTime: &now}, &entity.MachineBatchExec{Status: entity.MachineBatchExecStatusRunning})
+}
+
+// 批量执行实时推送的消息
+type batchExecMsg struct {
+	BatchExecId uint64 `json:"batchExecId"`
+	Type        string `json:"type"`
+	MachineId   uint64 `json:"machineId,omitempty"`
+	MachineName string `json:"machineName,omitempty"`
+	Data        string `json:"data,omitempty"`
+	Status      int8   `json:"status,omitempty"`
+}
+
+func (m *machineBatchExecAppImpl) runBatchExec(ctx context.Context, batchExec *entity.MachineBatchExec, machines []*entity.Machine, send func(msg *batchExecMsg)) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	// 控制并发执行的机器数
+	sem := make(chan struct{}, batchExec.Concurrency)
+
+	for _, machine := range machines {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(machine *entity.Machine) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			execRes := m.runOnMachine(ctx, batchExec, machine, send)
+			mu.Lock()
+			if execRes.Status == entity.MachineBatchExecResStatusSuccess {
+				batchExec.SuccessCount++
+			} else {
+				batchExec.FailCount++
+			}
+			mu.Unlock()
+		}(machine)
+	}
+	wg.Wait()
+
+	now := time.Now()
+	batchExec.EndTime = &now
+	batchExec.Status = entity.MachineBatchExecStatusSuccess
+	if batchExec.FailCount > 0 {
+		batchExec.Status = entity.MachineBatchExecStatusFail
+	}
+	if err := m.UpdateById(ctx, batchExec); err != nil {
+		logx.Errorf("更新批量执行任务[%d]结果失败: %s", batchExec.Id, err.Error())
+	}
+	send(&batchExecMsg{BatchExecId: batchExec.Id, Type: batchExecMsgTypeEnd, Status: batchExec.Status})
+}
+
+// 在单台机器上执行命令，并保存执行结果
+func (m *machineBatchExecAppImpl) runOnMachine(ctx context.Context, batchExec *entity.MachineBatchExec, machine *entity.Machine, send func(msg *batchExecMsg)) *entity.MachineBatchExecRes {
+	startTime := time.Now()
+	execRes := &entity.MachineBatchExecRes{
+		BatchExecId: batchExec.Id,
+		MachineId:   machine.Id,
+		MachineCode: machine.Code,
+		MachineName: machine.Name,
+		Ip:          machine.Ip,
+		StartTime:   &startTime,
+	}
+
+	output := &execOutput{send: func(data string) {
+		send(&batchExecMsg{BatchExecId: batchExec.Id, Type: batchExecMsgTypeOutput, MachineId: machine.Id, MachineName: machine.Name, Data: data})
+	}}
+
+	err := func() (err error) {
+		defer func() {
+			if e := recover(); e != nil {
+				err = errorx.NewBiz("%s", anyx.ToString(e))
+			}
+		}()
+
+		cli, err := m.machineApp.GetCli(machine.Id)
+		if err != nil {
+			return err
+		}
+		timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(batchExec.Timeout)*time.Second)
+		defer cancel()
+		return cli.RunWithContext(timeoutCtx, batchExec.Script, output)
+	}()
+
+	execRes.Res = output.String()
+	switch {
+	case err == nil:
+		execRes.Status = entity.MachineBatchExecResStatusSuccess
+	case errors.Is(err, context.DeadlineExceeded):
+		execRes.Status = entity.MachineBatchExecResStatusTimeout
+		execRes.Res += "\n执行超时"
+	default:
+		execRes.Status = entity.MachineBatchExecResStatusFail
+		execRes.Res += "\n" + err.Error()
+	}
+	endTime := time.Now()
+	execRes.EndTime = &endTime
+
+	if err := m.machineBatchExecResRepo.Insert(ctx, execRes); err != nil {
+		logx.Errorf("保存机器[%s]批量执行结果失败: %s", machine.Code, err.Error())
+	}
+	send(&batchExecMsg{BatchExecId: batchExec.Id, Type: batchExecMsgTypeResult, MachineId: machine.Id, MachineName: machine.Name, Status: execRes.Status})
+	return execRes
+}
+
+// 命令执行输出，保存有限长度的输出内容，send不为空时实时推送输出
+type execOutput struct {
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	send func(data string)
+}
+
+func (o *execOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if remain := maxExecOutputLen - o.buf.Len(); remain > 0 {
+		o.buf.Write(p[:min(len(p), remain)])
+	}
+	if o.send != nil {
+		o.send(string(p))
+	}
+	return len(p), nil
+}
+
+func (o *execOutput) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	// 截断可能导致末尾字符不完整
+	return strings.ToValidUTF8(o.buf.String(), "")
+}
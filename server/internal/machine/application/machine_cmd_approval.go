@@ -0,0 +1,223 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/mcm"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	"mayfly-go/pkg/cache"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/rediscli"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/utils/jsonx"
+	"mayfly-go/pkg/utils/stringx"
+	"slices"
+	"sync"
+	"time"
+)
+
+// 命令审批等待超时时间，超时未审批则丢弃该命令
+const cmdApprovalTimeout = 5 * time.Minute
+
+// 命令策略优先级，多个命令配置匹配时取优先级最高的策略
+var cmdStrategePriority = map[string]int{
+	entity.MachineCmdStrategeWarn:     1,
+	entity.MachineCmdStrategeAlert:    2,
+	entity.MachineCmdStrategeApproval: 3,
+	entity.MachineCmdStrategeDeny:     4,
+}
+
+const (
+	cmdApprovalKey       = "mayfly:machine:cmd-approvals"        // 待审批命令hash, field: 审批id
+	cmdApprovalResultKey = "mayfly:machine:cmd-approval-result:" // 审批结果, 后接审批id
+)
+
+// 未配置redis时使用本地存储待审批命令, key: 审批id
+var localCmdApprovals sync.Map
+
+// 根据命令配置过滤终端命令
+func (m *machineTermOpAppImpl) filterCmd(sessionId string, mi *mcm.MachineInfo, username string, cmd string, cmdConfs []*MachineCmd) error {
+	var matched *MachineCmd
+	for _, cmdConf := range cmdConfs {
+		if !cmdConf.CmdRegexp.MatchString(cmd) {
+			continue
+		}
+		if matched == nil || cmdStrategePriority[cmdConf.Stratege] > cmdStrategePriority[matched.Stratege] {
+			matched = cmdConf
+		}
+	}
+	if matched == nil {
+		return nil
+	}
+
+	switch matched.Stratege {
+	case entity.MachineCmdStrategeWarn:
+		return &mcm.CmdWarning{Msg: fmt.Sprintf("警告: 该命令命中高危命令规则[%s], 请谨慎操作", matched.ConfName)}
+	case entity.MachineCmdStrategeAlert:
+		msg := fmt.Sprintf("[%s]在机器[%s-%s]执行了命令[%s], 命中命令规则[%s]", username, mi.Name, mi.Ip, cmd, matched.ConfName)
+		logx.Warn(msg)
+		sendSysMsgByUsernames(m.accountApp, m.msgApp, matched.Receivers, msgdto.ErrSysMsg("终端命令告警", msg))
+		return nil
+	case entity.MachineCmdStrategeApproval:
+		approval := &dto.MachineCmdApproval{
+			Id:          stringx.Rand(16),
+			SessionId:   sessionId,
+			MachineId:   mi.Id,
+			MachineName: mi.Name,
+			Ip:          mi.Ip,
+			Cmd:         cmd,
+			ConfName:    matched.ConfName,
+			Applicant:   username,
+			Approvers:   matched.Receivers,
+			CreateTime:  time.Now(),
+		}
+		return &mcm.CmdHold{
+			Msg: fmt.Sprintf("该命令命中命令规则[%s], 需审批后执行, 等待审批中...", matched.ConfName),
+			Wait: func(ctx context.Context) error {
+				return m.waitCmdApproval(ctx, approval)
+			},
+		}
+	default:
+		return errorx.NewBiz("该命令已被禁用...")
+	}
+}
+
+// 挂起命令并通知审批人，阻塞直至审批完成、超时、取消或会话结束
+func (m *machineTermOpAppImpl) waitCmdApproval(ctx context.Context, approval *dto.MachineCmdApproval) error {
+	if err := saveCmdApproval(approval); err != nil {
+		return errorx.NewBiz("保存命令审批信息失败: %s", err.Error())
+	}
+	defer delCmdApproval(approval.Id)
+	resultKey := cmdApprovalResultKey + approval.Id
+	defer cache.Del(resultKey)
+
+	sendSysMsgByUsernames(m.accountApp, m.msgApp, approval.Approvers, msgdto.InfoSysMsg("终端命令审批",
+		fmt.Sprintf("[%s]在机器[%s-%s]执行命令[%s]需要您审批, 审批编号: %s", approval.Applicant, approval.MachineName, approval.Ip, approval.Cmd, approval.Id)))
+
+	// 审批可能由其他节点处理，故轮询审批结果
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	timer := time.NewTimer(cmdApprovalTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			switch cache.GetStr(resultKey) {
+			case "":
+				continue
+			case "1":
+				return nil
+			default:
+				return errorx.NewBiz("该命令审批未通过, 已丢弃")
+			}
+		case <-timer.C:
+			return errorx.NewBiz("该命令审批超时, 已丢弃")
+		case <-ctx.Done():
+			// 终端会话结束或用户取消(Ctrl+C)该命令
+			return errorx.NewBiz("该命令已取消执行")
+		}
+	}
+}
+
+func (m *machineTermOpAppImpl) GetPendingCmdApprovals(ctx context.Context) []*dto.MachineCmdApproval {
+	username := contextx.GetLoginAccount(ctx).Username
+	approvals := collx.ArrayFilter(listCmdApprovals(), func(approval *dto.MachineCmdApproval) bool {
+		return approval.Applicant != username && slices.Contains(approval.Approvers, username)
+	})
+	slices.SortFunc(approvals, func(a, b *dto.MachineCmdApproval) int {
+		return a.CreateTime.Compare(b.CreateTime)
+	})
+	return approvals
+}
+
+func (m *machineTermOpAppImpl) ApproveCmd(ctx context.Context, id string, pass bool) error {
+	approval := getCmdApproval(id)
+	if approval == nil {
+		return errorx.NewBiz("该审批不存在或已结束")
+	}
+
+	la := contextx.GetLoginAccount(ctx)
+	if !slices.Contains(approval.Approvers, la.Username) {
+		return errorx.NewBiz("您不是该命令的审批人")
+	}
+	if approval.Applicant == la.Username {
+		return errorx.NewBiz("不能审批自己执行的命令")
+	}
+	// 多个审批人同时审批时，只有成功删除待审批信息的审批生效
+	if !delCmdApproval(id) {
+		return errorx.NewBiz("该审批不存在或已结束")
+	}
+
+	logx.Infof("[%s]审批终端命令[%s - %s]结果: %v", la.Username, approval.Applicant, approval.Cmd, pass)
+	result := "0"
+	if pass {
+		result = "1"
+	}
+	return cache.SetStr(cmdApprovalResultKey+id, result, cmdApprovalTimeout)
+}
+
+// 保存待审批命令，配置了redis则存于redis以支持多节点审批
+func saveCmdApproval(approval *dto.MachineCmdApproval) error {
+	if !cache.UseRedisCache() {
+		localCmdApprovals.Store(approval.Id, approval)
+		return nil
+	}
+	return rediscli.GetCli().HSet(context.Background(), cmdApprovalKey, approval.Id, jsonx.ToStr(approval)).Err()
+}
+
+func getCmdApproval(id string) *dto.MachineCmdApproval {
+	if !cache.UseRedisCache() {
+		if value, ok := localCmdApprovals.Load(id); ok {
+			return value.(*dto.MachineCmdApproval)
+		}
+		return nil
+	}
+	approvalStr := rediscli.HGet(cmdApprovalKey, id)
+	if approvalStr == "" {
+		return nil
+	}
+	approval, err := jsonx.To(approvalStr, new(dto.MachineCmdApproval))
+	if err != nil || isCmdApprovalExpired(approval) {
+		return nil
+	}
+	return approval
+}
+
+// 获取所有待审批命令，并清理节点异常退出而遗留的过期审批
+func listCmdApprovals() []*dto.MachineCmdApproval {
+	approvals := make([]*dto.MachineCmdApproval, 0)
+	if !cache.UseRedisCache() {
+		localCmdApprovals.Range(func(key, value any) bool {
+			approvals = append(approvals, value.(*dto.MachineCmdApproval))
+			return true
+		})
+		return approvals
+	}
+
+	for id, approvalStr := range rediscli.HGetAll(cmdApprovalKey) {
+		approval, err := jsonx.To(approvalStr, new(dto.MachineCmdApproval))
+		if err != nil || isCmdApprovalExpired(approval) {
+			rediscli.HDel(cmdApprovalKey, id)
+			continue
+		}
+		approvals = append(approvals, approval)
+	}
+	return approvals
+}
+
+// 删除待审批命令，返回是否删除成功
+func delCmdApproval(id string) bool {
+	if !cache.UseRedisCache() {
+		_, ok := localCmdApprovals.LoadAndDelete(id)
+		return ok
+	}
+	return rediscli.HDel(cmdApprovalKey, id) > 0
+}
+
+func isCmdApprovalExpired(approval *dto.MachineCmdApproval) bool {
+	return time.Since(approval.CreateTime) > cmdApprovalTimeout
+}
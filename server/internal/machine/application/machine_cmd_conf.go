@@ -14,8 +14,10 @@ import (
 )
 
 type MachineCmd struct {
+	ConfName  string         // 命令配置名称
 	CmdRegexp *regexp.Regexp // 命令正则表达式
 	Stratege  string         // 策略（拒绝或审批等）
+	Receivers []string       // 告警接收人或审批人用户名
 }
 
 type MachineCmdConf interface {
@@ -43,6 +45,15 @@ func (m *machineCmdConfAppImpl) InjectMachineCmdConfRepo(repo repository.Machine
 
 func (m *machineCmdConfAppImpl) SaveCmdConf(ctx context.Context, cmdConfParam *dto.SaveMachineCmdConf) error {
 	cmdConf := cmdConfParam.CmdConf
+	switch cmdConf.Stratege {
+	case entity.MachineCmdStrategeDeny, entity.MachineCmdStrategeWarn:
+	case entity.MachineCmdStrategeAlert, entity.MachineCmdStrategeApproval:
+		if len(cmdConf.Receivers) == 0 {
+			return errorx.NewBiz("告警接收人或审批人不能为空")
+		}
+	default:
+		return errorx.NewBiz("不支持的命令策略: %s", cmdConf.Stratege)
+	}
 
 	return m.Tx(ctx, func(ctx context.Context) error {
 		return m.Save(ctx, cmdConf)
@@ -84,7 +95,7 @@ func (m *machineCmdConfAppImpl) GetCmdConfsByMachineTags(ctx context.Context, ta
 			if p, err := regexp.Compile(cmd); err != nil {
 				logx.Errorf("命令配置[%s]，正则编译失败", cmd)
 			} else {
-				cmds = append(cmds, &MachineCmd{CmdRegexp: p})
+				cmds = append(cmds, &MachineCmd{ConfName: cmdConf.Name, CmdRegexp: p, Stratege: cmdConf.Stratege, Receivers: cmdConf.Receivers})
 			}
 		}
 	}
@@ -2,9 +2,16 @@ package application
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"mayfly-go/internal/machine/application/dto"
 	"mayfly-go/internal/machine/domain/entity"
 	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/internal/machine/infrastructure/cache"
+	"mayfly-go/internal/machine/mcm"
+	msgapp "mayfly-go/internal/msg/application"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	sysapp "mayfly-go/internal/sys/application"
 	tagapp "mayfly-go/internal/tag/application"
 	tagentity "mayfly-go/internal/tag/domain/entity"
 	"mayfly-go/pkg/base"
@@ -15,9 +22,19 @@ import (
 	"mayfly-go/pkg/scheduler"
 	"mayfly-go/pkg/utils/collx"
 	"mayfly-go/pkg/utils/stringx"
+	"sync"
 	"time"
 )
 
+const (
+	maxCronJobRetryCount    = 10
+	maxCronJobRetryInterval = 10 * time.Minute // 重试间隔最大值
+	cronJobRunningLockTtl   = time.Minute      // 未设置超时时间的计划任务执行中标记的过期时间，执行期间定时续期
+)
+
+// 正在执行的计划任务, key: cronJobId:machineId。配置了redis则使用redis标记以支持多实例
+var runningCronJobs sync.Map
+
 type MachineCronJob interface {
 	base.App[*entity.MachineCronJob]
 
@@ -47,6 +64,9 @@ type machineCronJobAppImpl struct {
 
 	tagTreeApp       tagapp.TagTree       `inject:"TagTreeApp"`
 	tagTreeRelateApp tagapp.TagTreeRelate `inject:"TagTreeRelateApp"`
+
+	accountApp sysapp.Account `inject:"AccountApp"`
+	msgApp     msgapp.Msg     `inject:"MsgApp"`
 }
 
 var _ (MachineCronJob) = (*machineCronJobAppImpl)(nil)
@@ -69,6 +89,12 @@ func (m *machineCronJobAppImpl) GetExecPageList(condition *entity.MachineCronJob
 // 保存机器任务信息
 func (m *machineCronJobAppImpl) SaveMachineCronJob(ctx context.Context, param *dto.SaveMachineCronJob) error {
 	mcj := param.CronJob
+	if mcj.Timeout < 0 || mcj.RetryCount < 0 || mcj.RetryInterval < 0 || mcj.FailNotifyThreshold < 0 {
+		return errorx.NewBiz("超时时间、重试次数、重试间隔及失败通知阈值不能为负数")
+	}
+	if mcj.RetryCount > maxCronJobRetryCount {
+		return errorx.NewBiz("重试次数不能超过%d", maxCronJobRetryCount)
+	}
 
 	// 赋值cron job key
 	if mcj.Id == 0 {
@@ -132,12 +158,9 @@ func (m *machineCronJobAppImpl) InitCronJob() {
 }
 
 func (m *machineCronJobAppImpl) RunCronJob(key string) {
-	// 简单使用redis分布式锁防止多实例同一时刻重复执行
-	if lock := rediscli.NewLock(key, 30*time.Second); lock != nil {
-		if !lock.Lock() {
-			return
-		}
-		defer lock.UnLock()
+	// 简单使用redis分布式锁防止多实例重复执行同一次触发，锁待过期后自动释放，避免执行较快时其他实例仍可获取锁
+	if lock := rediscli.NewLock(fmt.Sprintf("%s:%d", key, time.Now().Unix()), 30*time.Second); lock != nil && !lock.Lock() {
+		return
 	}
 
 	cronJob := new(entity.MachineCronJob)
@@ -176,6 +199,14 @@ func (m *machineCronJobAppImpl) addCronJob(mcj *entity.MachineCronJob) {
 }
 
 func (m *machineCronJobAppImpl) runCronJob0(mid uint64, cronJob *entity.MachineCronJob) {
+	release, marked := markCronJobRunning(fmt.Sprintf("%d:%d", cronJob.Id, mid), cronJob)
+	if marked {
+		defer release()
+	} else if cronJob.SkipIfRunning == entity.MachineCronJobSkipIfRunningYes {
+		logx.Warnf("机器:[%d]上次执行的[%s]计划任务未结束, 跳过本次执行", mid, cronJob.Name)
+		return
+	}
+
 	execRes := &entity.MachineCronJobExec{
 		CronJobId: cronJob.Id,
 		ExecTime:  time.Now(),
@@ -188,7 +219,18 @@ func (m *machineCronJobAppImpl) runCronJob0(mid uint64, cronJob *entity.MachineC
 		execRes.MachineCode = machine.Code
 	} else {
 		execRes.MachineCode = machineCli.Info.Code
-		res, err = machineCli.Run(cronJob.Script)
+		for retry := 0; ; retry++ {
+			res, err = m.runScript(machineCli, cronJob)
+			if err == nil || retry >= cronJob.RetryCount {
+				break
+			}
+
+			// 指数退避重试
+			interval := min(time.Duration(max(cronJob.RetryInterval, 1))*time.Second<<retry, maxCronJobRetryInterval)
+			logx.Warnf("机器:[%d]执行[%s]计划任务失败, %s后进行第%d次重试: %s", mid, cronJob.Name, interval, retry+1, err.Error())
+			time.Sleep(interval)
+		}
+
 		if err != nil {
 			if res == "" {
 				res = err.Error()
@@ -199,6 +241,7 @@ func (m *machineCronJobAppImpl) runCronJob0(mid uint64, cronJob *entity.MachineC
 		}
 	}
 	execRes.Res = res
+	m.checkConsecutiveFails(mid, execRes.MachineCode, cronJob, err, res)
 
 	if cronJob.SaveExecResType == entity.SaveExecResTypeNo ||
 		(cronJob.SaveExecResType == entity.SaveExecResTypeOnError && err == nil) {
@@ -213,3 +256,100 @@ func (m *machineCronJobAppImpl) runCronJob0(mid uint64, cronJob *entity.MachineC
 	// 保存执行记录
 	m.machineCronJobExecRepo.Insert(context.TODO(), execRes)
 }
+
+// 标记计划任务在机器上执行中，直至调用返回的释放函数。若已在执行中则返回false
+func markCronJobRunning(runningKey string, cronJob *entity.MachineCronJob) (func(), bool) {
+	lock := rediscli.NewLock("machine:cronjob:running:"+runningKey, cronJobRunningTtl(cronJob))
+	if lock == nil {
+		if _, running := runningCronJobs.LoadOrStore(runningKey, struct{}{}); running {
+			return nil, false
+		}
+		return func() { runningCronJobs.Delete(runningKey) }, true
+	}
+
+	if !lock.Lock() {
+		return nil, false
+	}
+	if cronJob.Timeout > 0 {
+		return func() { lock.UnLock() }, true
+	}
+
+	// 未设置超时时间则无法预估执行时长，执行期间定时续期
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cronJobRunningLockTtl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				lock.RefreshLock()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		lock.UnLock()
+	}, true
+}
+
+// 执行中标记的过期时间，需覆盖所有重试的执行超时时间及重试间隔
+func cronJobRunningTtl(cronJob *entity.MachineCronJob) time.Duration {
+	if cronJob.Timeout <= 0 {
+		return cronJobRunningLockTtl
+	}
+	ttl := time.Duration(cronJob.Timeout) * time.Second * time.Duration(cronJob.RetryCount+1)
+	for retry := 0; retry < cronJob.RetryCount; retry++ {
+		ttl += min(time.Duration(max(cronJob.RetryInterval, 1))*time.Second<<retry, maxCronJobRetryInterval)
+	}
+	// 预留连接机器等耗时
+	return ttl + time.Minute
+}
+
+// 执行计划任务脚本，超出最大执行时间则终止执行
+func (m *machineCronJobAppImpl) runScript(cli *mcm.Cli, cronJob *entity.MachineCronJob) (string, error) {
+	if cronJob.Timeout <= 0 {
+		return cli.Run(cronJob.Script)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cronJob.Timeout)*time.Second)
+	defer cancel()
+	output := new(execOutput)
+	err := cli.RunWithContext(ctx, cronJob.Script, output)
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = errorx.NewBiz("执行超时(%d秒)", cronJob.Timeout)
+	}
+	res := output.String()
+	if err != nil && res != "" {
+		res = res + "\n" + err.Error()
+	}
+	return res, err
+}
+
+// 统计计划任务在机器上的连续失败次数，达到阈值时发送失败通知
+func (m *machineCronJobAppImpl) checkConsecutiveFails(mid uint64, machineCode string, cronJob *entity.MachineCronJob, err error, res string) {
+	if cronJob.FailNotifyThreshold <= 0 {
+		return
+	}
+	if err == nil {
+		cache.DelMachineCronJobFails(cronJob.Id, mid)
+		return
+	}
+
+	fails, err := cache.IncrMachineCronJobFails(cronJob.Id, mid)
+	if err != nil {
+		logx.Errorf("更新计划任务[%s]连续失败次数失败: %s", cronJob.Name, err.Error())
+		return
+	}
+	if fails != cronJob.FailNotifyThreshold {
+		return
+	}
+
+	msg := msgdto.ErrSysMsg("计划任务执行失败", fmt.Sprintf("计划任务[%s]在机器[%s]上已连续失败%d次, 最近一次执行结果: %s", cronJob.Name, machineCode, fails, stringx.TruncateStr(res, 200)))
+	if len(cronJob.Receivers) > 0 {
+		sendSysMsgByUsernames(m.accountApp, m.msgApp, cronJob.Receivers, msg)
+		return
+	}
+	m.msgApp.CreateAndSend(&model.LoginAccount{Id: cronJob.CreatorId, Username: "system"}, msg)
+}
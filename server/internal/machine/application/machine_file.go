@@ -11,6 +11,7 @@ import (
 	"mayfly-go/internal/machine/domain/entity"
 	"mayfly-go/internal/machine/domain/repository"
 	"mayfly-go/internal/machine/mcm"
+	msgapp "mayfly-go/internal/msg/application"
 	"mayfly-go/pkg/base"
 	"mayfly-go/pkg/contextx"
 	"mayfly-go/pkg/errorx"
@@ -70,6 +71,21 @@ type MachineFile interface {
 
 	UploadFiles(ctx context.Context, opParam *dto.MachineFileOp, basePath string, fileHeaders []*multipart.FileHeader, paths []string) (*mcm.MachineInfo, error)
 
+	// 获取分片上传已上传的大小，用于断点续传
+	GetChunkUploadOffset(ctx context.Context, opParam *dto.MachineFileOp, filename string) (int64, error)
+
+	// 从offset处写入分片内容，offset需与已上传大小一致，返回写入后的已上传大小
+	UploadChunk(ctx context.Context, opParam *dto.MachineFileOp, filename string, offset int64, reader io.Reader) (int64, error)
+
+	// 完成分片上传，校验文件大小及sha256后重命名为目标文件
+	CompleteChunkUpload(ctx context.Context, opParam *dto.MachineFileOp, filename string, size int64, checksum string) (*mcm.MachineInfo, error)
+
+	// 将目录打包为tar.gz并写入writer
+	DownloadDirAsTarGz(ctx context.Context, opParam *dto.MachineFileOp, writer io.Writer) (*mcm.MachineInfo, error)
+
+	// 将文件从源机器直接传输至目标机器目录，异步执行并推送传输进度，中断后再次传输可从已传输位置继续，返回传输id
+	TransferFile(ctx context.Context, param *dto.MachineFileTransfer) (string, error)
+
 	// 移除文件
 	RemoveFile(ctx context.Context, opParam *dto.MachineFileOp, path ...string) (*mcm.MachineInfo, error)
 
@@ -83,7 +99,8 @@ type MachineFile interface {
 type machineFileAppImpl struct {
 	base.AppImpl[*entity.MachineFile, repository.MachineFile]
 
-	machineApp Machine `inject:"MachineApp"`
+	machineApp Machine    `inject:"MachineApp"`
+	msgApp     msgapp.Msg `inject:"MsgApp"`
 }
 
 // 注入MachineFileRepo
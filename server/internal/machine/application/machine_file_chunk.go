@@ -0,0 +1,228 @@
+package application
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/config"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/mcm"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/sftp"
+)
+
+// 分片上传临时文件后缀
+const chunkUploadTmpSuffix = ".mfpart"
+
+func (m *machineFileAppImpl) GetChunkUploadOffset(ctx context.Context, opParam *dto.MachineFileOp, filename string) (int64, error) {
+	tmpPath, err := getChunkUploadTmpPath(opParam, filename)
+	if err != nil {
+		return 0, err
+	}
+	_, sftpCli, err := m.getChunkUploadSftpCli(opParam)
+	if err != nil {
+		return 0, err
+	}
+
+	fi, err := sftpCli.Stat(tmpPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+func (m *machineFileAppImpl) UploadChunk(ctx context.Context, opParam *dto.MachineFileOp, filename string, offset int64, reader io.Reader) (int64, error) {
+	tmpPath, err := getChunkUploadTmpPath(opParam, filename)
+	if err != nil {
+		return 0, err
+	}
+	_, sftpCli, err := m.getChunkUploadSftpCli(opParam)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := sftpCli.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	// 仅允许从已上传位置继续写入，避免文件出现空洞或覆盖已上传内容
+	if fi.Size() != offset {
+		return fi.Size(), errorx.NewBiz("分片偏移量[%d]与已上传大小[%d]不一致", offset, fi.Size())
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+
+	maxUploadFileSize := config.GetMachine().UploadMaxFileSize
+	n, err := io.Copy(f, io.LimitReader(reader, maxUploadFileSize-offset+1))
+	if err != nil {
+		return offset + n, err
+	}
+	if offset+n > maxUploadFileSize {
+		f.Truncate(offset)
+		return offset, errorx.NewBiz("文件大小不能超过%d字节", maxUploadFileSize)
+	}
+	return offset + n, nil
+}
+
+func (m *machineFileAppImpl) CompleteChunkUpload(ctx context.Context, opParam *dto.MachineFileOp, filename string, size int64, checksum string) (*mcm.MachineInfo, error) {
+	tmpPath, err := getChunkUploadTmpPath(opParam, filename)
+	if err != nil {
+		return nil, err
+	}
+	mi, sftpCli, err := m.getChunkUploadSftpCli(opParam)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := sftpCli.Open(tmpPath)
+	if err != nil {
+		return mi, errorx.NewBiz("分片上传文件不存在: %s", err.Error())
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return mi, err
+	}
+	if fi.Size() != size {
+		f.Close()
+		return mi, errorx.NewBiz("文件未上传完成, 已上传%d字节, 文件大小%d字节", fi.Size(), size)
+	}
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, f)
+	f.Close()
+	if err != nil {
+		return mi, err
+	}
+	if !strings.EqualFold(hex.EncodeToString(hash.Sum(nil)), checksum) {
+		// 校验失败则删除临时文件，需重新上传
+		sftpCli.Remove(tmpPath)
+		return mi, errorx.NewBiz("文件校验失败, 请重新上传")
+	}
+
+	return mi, renameRemoteFile(sftpCli, tmpPath, path.Join(opParam.Path, filename))
+}
+
+func (m *machineFileAppImpl) DownloadDirAsTarGz(ctx context.Context, opParam *dto.MachineFileOp, writer io.Writer) (*mcm.MachineInfo, error) {
+	mi, sftpCli, err := m.GetMachineSftpCli(opParam)
+	if err != nil {
+		return nil, err
+	}
+
+	root := path.Clean(opParam.Path)
+	fi, err := sftpCli.Stat(root)
+	if err != nil {
+		return mi, err
+	}
+	if !fi.IsDir() {
+		return mi, errorx.NewBiz("该路径不是目录")
+	}
+
+	gw := gzip.NewWriter(writer)
+	tw := tar.NewWriter(gw)
+	// 归档内文件路径以所下载目录名开头
+	parent := path.Dir(root)
+	walker := sftpCli.Walk(root)
+	for walker.Step() {
+		if ctx.Err() != nil {
+			return mi, ctx.Err()
+		}
+		if err := walker.Err(); err != nil {
+			logx.Warnf("打包目录时读取文件[%s]失败: %s", walker.Path(), err.Error())
+			continue
+		}
+		if err := writeTarEntry(tw, sftpCli, walker.Path(), strings.TrimPrefix(strings.TrimPrefix(walker.Path(), parent), "/"), walker.Stat()); err != nil {
+			return mi, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return mi, err
+	}
+	return mi, gw.Close()
+}
+
+// 写入单个文件至tar归档，仅处理目录、普通文件及符号链接
+func writeTarEntry(tw *tar.Writer, sftpCli *sftp.Client, filePath string, name string, fi fs.FileInfo) error {
+	var link string
+	switch {
+	case fi.IsDir(), fi.Mode().IsRegular():
+	case fi.Mode()&fs.ModeSymlink != 0:
+		target, err := sftpCli.ReadLink(filePath)
+		if err != nil {
+			return err
+		}
+		link = target
+	default:
+		return nil
+	}
+
+	header, err := tar.FileInfoHeader(fi, link)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if fi.IsDir() {
+		header.Name += "/"
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		return nil
+	}
+
+	f, err := sftpCli.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+func (m *machineFileAppImpl) getChunkUploadSftpCli(opParam *dto.MachineFileOp) (*mcm.MachineInfo, *sftp.Client, error) {
+	if opParam.Protocol == entity.MachineProtocolRdp {
+		return nil, nil, errorx.NewBiz("RDP机器暂不支持分片上传")
+	}
+	return m.GetMachineSftpCli(opParam)
+}
+
+// 重命名远程文件，目标文件已存在则覆盖
+func renameRemoteFile(sftpCli *sftp.Client, oldPath, newPath string) error {
+	if err := sftpCli.PosixRename(oldPath, newPath); err != nil {
+		// 服务端不支持posix-rename扩展时，先删除已存在的目标文件再重命名
+		sftpCli.Remove(newPath)
+		return sftpCli.Rename(oldPath, newPath)
+	}
+	return nil
+}
+
+// 获取分片上传的临时文件路径，上传完成后重命名为目标文件
+func getChunkUploadTmpPath(opParam *dto.MachineFileOp, filename string) (string, error) {
+	if filename == "" || filename != path.Base(filename) || filename == ".." {
+		return "", errorx.NewBiz("文件名不合法")
+	}
+	return path.Join(opParam.Path, "."+filename+chunkUploadTmpSuffix), nil
+}
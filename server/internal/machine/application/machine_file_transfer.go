@@ -0,0 +1,217 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/utils/stringx"
+	"mayfly-go/pkg/ws"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+const (
+	// 传输中断后自动重试次数
+	maxFileTransferRetries = 3
+	fileTransferRetryDelay = 3 * time.Second
+
+	fileTransferMsgCategory = "machineFileTransfer"
+	// 临时文件对应的源文件信息（大小及修改时间）保存文件后缀，用于判断能否断点续传
+	fileTransferMetaSuffix = ".meta"
+)
+
+// 机器间文件传输进度
+type fileTransferProgress struct {
+	Id          string `json:"id"`
+	Title       string `json:"title"`
+	Size        int64  `json:"size"`
+	Transferred int64  `json:"transferred"`
+	Terminated  bool   `json:"terminated"`
+	Err         string `json:"err,omitempty"`
+}
+
+func (m *machineFileAppImpl) TransferFile(ctx context.Context, param *dto.MachineFileTransfer) (string, error) {
+	if param.Src.Protocol == entity.MachineProtocolRdp || param.Target.Protocol == entity.MachineProtocolRdp {
+		return "", errorx.NewBiz("RDP机器暂不支持机器间文件传输")
+	}
+
+	srcMi, srcCli, err := m.GetMachineSftpCli(param.Src)
+	if err != nil {
+		return "", err
+	}
+	fi, err := srcCli.Stat(param.Src.Path)
+	if err != nil {
+		return "", errorx.NewBiz("读取源文件失败: %s", err.Error())
+	}
+	if !fi.Mode().IsRegular() {
+		return "", errorx.NewBiz("仅支持传输普通文件")
+	}
+
+	filename := path.Base(param.Src.Path)
+	tmpPath, err := getChunkUploadTmpPath(param.Target, filename)
+	if err != nil {
+		return "", err
+	}
+	targetMi, targetCli, err := m.GetMachineSftpCli(param.Target)
+	if err != nil {
+		return "", err
+	}
+	if dirFi, err := targetCli.Stat(param.Target.Path); err != nil || !dirFi.IsDir() {
+		return "", errorx.NewBiz("目标目录不存在")
+	}
+
+	la := contextx.GetLoginAccount(ctx)
+	progress := &fileTransferProgress{
+		Id:    stringx.Rand(32),
+		Title: fmt.Sprintf("%s[%s:%s] -> %s[%s:%s]", srcMi.Name, srcMi.Ip, param.Src.Path, targetMi.Name, targetMi.Ip, param.Target.Path),
+		Size:  fi.Size(),
+	}
+	sendProgress := func() {
+		ws.SendJsonMsg(ws.UserId(la.Id), param.ClientId, msgdto.InfoSysMsg("机器文件传输进度", progress).WithCategory(fileTransferMsgCategory))
+	}
+
+	go func() {
+		err := m.runTransfer(param, tmpPath, progress, sendProgress)
+		progress.Terminated = true
+		if err != nil {
+			progress.Err = err.Error()
+			logx.Errorf("机器文件传输失败[%s]: %s", progress.Title, err.Error())
+			m.msgApp.CreateAndSend(la, msgdto.ErrSysMsg("文件传输失败", fmt.Sprintf("%s 传输失败, 重新传输可从已传输位置继续: %s", progress.Title, err.Error())))
+		} else {
+			m.msgApp.CreateAndSend(la, msgdto.SuccessSysMsg("文件传输成功", fmt.Sprintf("%s 传输成功", progress.Title)))
+		}
+		sendProgress()
+	}()
+
+	return progress.Id, nil
+}
+
+// 执行文件传输，传输中断时重新获取连接并从已传输位置继续
+func (m *machineFileAppImpl) runTransfer(param *dto.MachineFileTransfer, tmpPath string, progress *fileTransferProgress, sendProgress func()) error {
+	var err error
+	for retry := 0; retry <= maxFileTransferRetries; retry++ {
+		if retry > 0 {
+			logx.Warnf("机器文件传输中断[%s], 进行第%d次重试: %s", progress.Title, retry, err.Error())
+			time.Sleep(fileTransferRetryDelay)
+		}
+
+		var srcCli, targetCli *sftp.Client
+		if _, srcCli, err = m.GetMachineSftpCli(param.Src); err != nil {
+			continue
+		}
+		if _, targetCli, err = m.GetMachineSftpCli(param.Target); err != nil {
+			continue
+		}
+
+		if err = transferFile(srcCli, targetCli, param.Src.Path, tmpPath, progress, sendProgress); err == nil {
+			targetCli.Remove(tmpPath + fileTransferMetaSuffix)
+			return renameRemoteFile(targetCli, tmpPath, path.Join(param.Target.Path, path.Base(param.Src.Path)))
+		}
+	}
+	return err
+}
+
+// 从目标临时文件已有大小处继续传输源文件内容，源文件已变更则从头传输
+func transferFile(srcCli, targetCli *sftp.Client, srcPath, tmpPath string, progress *fileTransferProgress, sendProgress func()) error {
+	sfi, err := srcCli.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+	progress.Size = sfi.Size()
+	srcMeta := fmt.Sprintf("%d:%d", sfi.Size(), sfi.ModTime().UnixNano())
+
+	tf, err := targetCli.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE)
+	if err != nil {
+		return err
+	}
+	defer tf.Close()
+	tfi, err := tf.Stat()
+	if err != nil {
+		return err
+	}
+	offset := tfi.Size()
+	// 临时文件对应的源文件大小及修改时间不一致，说明源文件已变更，重新传输
+	if offset > progress.Size || readRemoteFile(targetCli, tmpPath+fileTransferMetaSuffix) != srcMeta {
+		if err := tf.Truncate(0); err != nil {
+			return err
+		}
+		offset = 0
+		if err := writeRemoteFile(targetCli, tmpPath+fileTransferMetaSuffix, srcMeta); err != nil {
+			return err
+		}
+	}
+
+	sf, err := srcCli.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer sf.Close()
+	if _, err := sf.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := tf.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	progress.Transferred = offset
+	sendProgress()
+	n, err := io.Copy(&progressWriter{w: tf, written: &progress.Transferred, report: sendProgress}, sf)
+	if err != nil {
+		return err
+	}
+	if offset+n != progress.Size {
+		return errorx.NewBiz("传输大小[%d]与源文件大小[%d]不一致", offset+n, progress.Size)
+	}
+	return nil
+}
+
+// 读取远程小文件内容，不存在或读取失败返回空字符串
+func readRemoteFile(cli *sftp.Client, filePath string) string {
+	f, err := cli.Open(filePath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	content, err := io.ReadAll(io.LimitReader(f, 1024))
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+func writeRemoteFile(cli *sftp.Client, filePath string, content string) error {
+	f, err := cli.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write([]byte(content))
+	return err
+}
+
+// 统计写入大小，并每秒最多上报一次进度
+type progressWriter struct {
+	w          io.Writer
+	written    *int64
+	lastReport time.Time
+	report     func()
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	*pw.written += int64(n)
+	if time.Since(pw.lastReport) >= time.Second {
+		pw.lastReport = time.Now()
+		pw.report()
+	}
+	return n, err
+}
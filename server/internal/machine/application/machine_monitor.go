@@ -0,0 +1,138 @@
+package application
+
+import (
+	"context"
+	"mayfly-go/internal/machine/config"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/internal/machine/mcm"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/scheduler"
+	"mayfly-go/pkg/utils/jsonx"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type MachineMonitor interface {
+	base.App[*entity.MachineMonitor]
+
+	// 保存机器状态采样记录，并根据告警规则校验是否需要告警
+	SaveStats(ctx context.Context, mi *mcm.MachineInfo, stats *mcm.Stats) error
+
+	// 获取机器监控记录，按采样时间升序
+	GetMonitors(cond *entity.MachineMonitorQuery) ([]*entity.MachineMonitor, error)
+
+	// 定时删除过期的监控记录
+	TimerDeleteMonitor()
+}
+
+type machineMonitorAppImpl struct {
+	base.AppImpl[*entity.MachineMonitor, repository.MachineMonitor]
+
+	machineAlertRuleApp MachineAlertRule `inject:"MachineAlertRuleApp"`
+
+	// 各机器上次采样的网络流量，用于计算网络速率
+	lastNetSamples sync.Map
+}
+
+var _ (MachineMonitor) = (*machineMonitorAppImpl)(nil)
+
+// 注入MachineMonitorRepo
+func (m *machineMonitorAppImpl) InjectMachineMonitorRepo(repo repository.MachineMonitor) {
+	m.Repo = repo
+}
+
+// 网络流量采样
+type netSample struct {
+	Rx   uint64
+	Tx   uint64
+	Time time.Time
+}
+
+// 文件系统使用信息
+type fsUsage struct {
+	MountPoint string  `json:"mountPoint"`
+	Used       uint64  `json:"used"`
+	Free       uint64  `json:"free"`
+	Rate       float32 `json:"rate"`
+}
+
+func (m *machineMonitorAppImpl) SaveStats(ctx context.Context, mi *mcm.MachineInfo, stats *mcm.Stats) error {
+	// 状态信息获取失败，不保存，避免产生错误的监控记录及告警
+	if stats == nil || stats.MemInfo.Total == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	mm := &entity.MachineMonitor{
+		MachineId:  mi.Id,
+		CpuRate:    100 - stats.CPU.Idle,
+		SysLoad:    strings.Join([]string{stats.Load1, stats.Load5, stats.Load10}, " "),
+		CreateTime: now,
+	}
+
+	if load1, err := strconv.ParseFloat(stats.Load1, 32); err == nil {
+		mm.Load1 = float32(load1)
+	}
+
+	memInfo := stats.MemInfo
+	mm.MemRate = float32(memInfo.Total-memInfo.Available) / float32(memInfo.Total) * 100
+
+	fsUsages := make([]*fsUsage, 0, len(stats.FSInfos))
+	for _, fs := range stats.FSInfos {
+		total := fs.Used + fs.Free
+		if total == 0 {
+			continue
+		}
+		usage := &fsUsage{MountPoint: fs.MountPoint, Used: fs.Used, Free: fs.Free, Rate: float32(fs.Used) / float32(total) * 100}
+		fsUsages = append(fsUsages, usage)
+		if usage.Rate > mm.DiskRate {
+			mm.DiskRate = usage.Rate
+		}
+	}
+	mm.FsInfos = jsonx.ToStr(fsUsages)
+
+	// 根据与上次采样的流量差值计算网络速率，忽略本地回环网卡
+	sample := &netSample{Time: now}
+	for name, netIntf := range stats.NetIntf {
+		if name == "lo" {
+			continue
+		}
+		sample.Rx += netIntf.Rx
+		sample.Tx += netIntf.Tx
+	}
+	if last, ok := m.lastNetSamples.Load(mi.Id); ok {
+		lastSample := last.(*netSample)
+		seconds := uint64(now.Sub(lastSample.Time).Seconds())
+		// 网卡计数器重置（如机器重启）时不计算速率
+		if seconds > 0 && sample.Rx >= lastSample.Rx && sample.Tx >= lastSample.Tx {
+			mm.NetRxRate = (sample.Rx - lastSample.Rx) / seconds
+			mm.NetTxRate = (sample.Tx - lastSample.Tx) / seconds
+		}
+	}
+	m.lastNetSamples.Store(mi.Id, sample)
+
+	if err := m.Insert(ctx, mm); err != nil {
+		return err
+	}
+
+	m.machineAlertRuleApp.CheckAlert(ctx, mi, mm)
+	return nil
+}
+
+func (m *machineMonitorAppImpl) GetMonitors(cond *entity.MachineMonitorQuery) ([]*entity.MachineMonitor, error) {
+	return m.GetRepo().SelectByQuery(cond)
+}
+
+func (m *machineMonitorAppImpl) TimerDeleteMonitor() {
+	logx.Debug("开始定时删除机器监控记录...")
+	scheduler.AddFun("@every 60m", func() {
+		before := time.Now().AddDate(0, 0, -config.GetMachine().MonitorSaveDays)
+		if err := m.GetRepo().DeleteBefore(before); err != nil {
+			logx.Warnf("删除机器监控记录失败: %s", err.Error())
+		}
+	})
+}
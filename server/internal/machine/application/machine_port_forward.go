@@ -0,0 +1,238 @@
+package application
+
+import (
+	"context"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/config"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/internal/machine/mcm"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/collx"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPortForwardDuration = 120
+	maxPortForwardDuration     = 24 * 60
+	// 单个账号最多同时开启的端口转发数
+	maxPortForwardPerAccount = 10
+)
+
+type MachinePortForward interface {
+	base.App[*entity.MachinePortForward]
+
+	// 分页获取端口转发审计记录
+	GetPageList(condition *entity.MachinePortForwardQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+
+	// 开启端口转发会话，将服务端端口的连接经机器转发至目标地址，到期后自动关闭。cli由端口转发独占，会话关闭时一并关闭
+	OpenPortForward(ctx context.Context, cli *mcm.Cli, param *dto.MachinePortForward) (*entity.MachinePortForward, error)
+
+	// 获取活跃的端口转发会话，accountId不为0则只获取该账号开启的会话
+	GetActivePortForwards(accountId uint64) []*mcm.PortForwardInfo
+
+	// 关闭端口转发会话，force为false时只能关闭自己开启的会话
+	ClosePortForward(ctx context.Context, id uint64, force bool) error
+
+	// 关闭指定机器的所有端口转发会话
+	CloseByMachineId(machineId uint64)
+
+	// 将服务重启前未正常关闭的端口转发记录置为异常关闭
+	InitPortForward()
+}
+
+type machinePortForwardAppImpl struct {
+	base.AppImpl[*entity.MachinePortForward, repository.MachinePortForward]
+}
+
+var _ (MachinePortForward) = (*machinePortForwardAppImpl)(nil)
+
+// 注入MachinePortForwardRepo
+func (m *machinePortForwardAppImpl) InjectMachinePortForwardRepo(repo repository.MachinePortForward) {
+	m.Repo = repo
+}
+
+func (m *machinePortForwardAppImpl) GetPageList(condition *entity.MachinePortForwardQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
+	return m.GetRepo().GetPageList(condition, pageParam, toEntity, orderBy...)
+}
+
+func (m *machinePortForwardAppImpl) OpenPortForward(ctx context.Context, cli *mcm.Cli, param *dto.MachinePortForward) (*entity.MachinePortForward, error) {
+	pf, record, err := m.openPortForward(ctx, cli, param)
+	if err != nil {
+		cli.Close()
+		return nil, err
+	}
+	pf.Start()
+	return record, nil
+}
+
+func (m *machinePortForwardAppImpl) openPortForward(ctx context.Context, cli *mcm.Cli, param *dto.MachinePortForward) (*mcm.PortForward, *entity.MachinePortForward, error) {
+	if param.RemoteHost == "" {
+		param.RemoteHost = "127.0.0.1"
+	}
+	if param.RemotePort <= 0 || param.RemotePort > 65535 {
+		return nil, nil, errorx.NewBiz("目标端口错误")
+	}
+	machineConf := config.GetMachine()
+	if !allowPortForwardRemoteHost(param.RemoteHost, machineConf.PortForwardRemoteHosts) {
+		return nil, nil, errorx.NewBiz("不允许转发至目标地址[%s]，请联系管理员配置允许的目标地址", param.RemoteHost)
+	}
+	if param.LocalPort != 0 && (param.LocalPort < 1024 || param.LocalPort > 65535) {
+		return nil, nil, errorx.NewBiz("监听端口需在1024~65535之间")
+	}
+	if param.Duration <= 0 {
+		param.Duration = defaultPortForwardDuration
+	}
+	if param.Duration > maxPortForwardDuration {
+		return nil, nil, errorx.NewBiz("有效时长不能超过%d分钟", maxPortForwardDuration)
+	}
+
+	la := contextx.GetLoginAccount(ctx)
+	if len(m.GetActivePortForwards(la.Id)) >= maxPortForwardPerAccount {
+		return nil, nil, errorx.NewBiz("最多同时开启%d个端口转发", maxPortForwardPerAccount)
+	}
+
+	mi := cli.Info
+	now := time.Now()
+	expireTime := now.Add(time.Duration(param.Duration) * time.Minute)
+	pf, err := mcm.NewPortForward(&mcm.PortForwardInfo{
+		MachineId:   mi.Id,
+		MachineName: mi.Name,
+		AccountId:   la.Id,
+		Username:    la.Username,
+		BindAddr:    machineConf.PortForwardBindAddr,
+		LocalPort:   param.LocalPort,
+		ClientIp:    param.ClientIp,
+		RemoteHost:  param.RemoteHost,
+		RemotePort:  param.RemotePort,
+		StartTime:   now,
+		ExpireTime:  expireTime,
+	}, cli, m.onPortForwardClose)
+	if err != nil {
+		return nil, nil, errorx.NewBiz("端口监听失败: %s", err.Error())
+	}
+
+	record := &entity.MachinePortForward{
+		MachineId:    mi.Id,
+		MachineCode:  mi.Code,
+		MachineName:  mi.Name,
+		AuthCertName: mi.AuthCertName,
+		LocalPort:    pf.Info.LocalPort,
+		RemoteHost:   param.RemoteHost,
+		RemotePort:   param.RemotePort,
+		Duration:     param.Duration,
+		Status:       entity.MachinePortForwardStatusActive,
+		ExpireTime:   &expireTime,
+	}
+	if err := m.Insert(ctx, record); err != nil {
+		pf.Close(mcm.PortForwardCloseError)
+		return nil, nil, err
+	}
+	pf.Info.ID = record.Id
+	return pf, record, nil
+}
+
+func (m *machinePortForwardAppImpl) GetActivePortForwards(accountId uint64) []*mcm.PortForwardInfo {
+	var filter func(pf *mcm.PortForward) bool
+	if accountId != 0 {
+		filter = func(pf *mcm.PortForward) bool {
+			return pf.Info.AccountId == accountId
+		}
+	}
+	return collx.ArrayMap(mcm.GetPortForwards(filter), func(pf *mcm.PortForward) *mcm.PortForwardInfo {
+		return pf.GetInfo()
+	})
+}
+
+func (m *machinePortForwardAppImpl) ClosePortForward(ctx context.Context, id uint64, force bool) error {
+	pf := mcm.GetPortForward(id)
+	if pf == nil {
+		return errorx.NewBiz("端口转发不存在或已关闭")
+	}
+
+	la := contextx.GetLoginAccount(ctx)
+	if !force && pf.Info.AccountId != la.Id {
+		return errorx.NewBiz("只能关闭自己开启的端口转发")
+	}
+
+	pf.Close(mcm.PortForwardCloseManual)
+	record := &entity.MachinePortForward{Closer: la.Username}
+	record.Id = id
+	return m.UpdateById(ctx, record)
+}
+
+func (m *machinePortForwardAppImpl) CloseByMachineId(machineId uint64) {
+	for _, pf := range mcm.GetPortForwards(func(pf *mcm.PortForward) bool { return pf.Info.MachineId == machineId }) {
+		pf.Close(mcm.PortForwardCloseManual)
+	}
+}
+
+func (m *machinePortForwardAppImpl) InitPortForward() {
+	now := time.Now()
+	err := m.UpdateByCond(context.Background(), &entity.MachinePortForward{Status: entity.MachinePortForwardStatusError, CloseTime: &now},
+		&entity.MachinePortForward{Status: entity.MachinePortForwardStatusActive})
+	if err != nil {
+		logx.Errorf("重置端口转发记录状态失败: %s", err.Error())
+	}
+}
+
+// 判断是否允许转发至目标地址，allowHosts为空则只允许机器本地回环地址，支持ip、网段、主机名及*（任意地址）
+func allowPortForwardRemoteHost(remoteHost string, allowHosts []string) bool {
+	if len(allowHosts) == 0 {
+		allowHosts = []string{"127.0.0.1", "::1", "localhost"}
+	}
+
+	remoteIp := net.ParseIP(remoteHost)
+	for _, allowHost := range allowHosts {
+		allowHost = strings.TrimSpace(allowHost)
+		if allowHost == "*" || strings.EqualFold(allowHost, remoteHost) {
+			return true
+		}
+		if remoteIp == nil {
+			continue
+		}
+		if ip := net.ParseIP(allowHost); ip != nil && ip.Equal(remoteIp) {
+			return true
+		}
+		if _, ipNet, err := net.ParseCIDR(allowHost); err == nil && ipNet.Contains(remoteIp) {
+			return true
+		}
+	}
+	return false
+}
+
+// 端口转发会话关闭时更新审计记录
+func (m *machinePortForwardAppImpl) onPortForwardClose(pf *mcm.PortForward, reason string) {
+	// 审计记录保存前即关闭，无需更新
+	if pf.Info.ID == 0 {
+		return
+	}
+
+	status := entity.MachinePortForwardStatusClosed
+	switch reason {
+	case mcm.PortForwardCloseExpired:
+		status = entity.MachinePortForwardStatusExpired
+	case mcm.PortForwardCloseError:
+		status = entity.MachinePortForwardStatusError
+	}
+
+	info := pf.GetInfo()
+	now := time.Now()
+	record := &entity.MachinePortForward{
+		Status:    status,
+		CloseTime: &now,
+		ConnCount: info.ConnCount,
+		BytesIn:   info.BytesIn,
+		BytesOut:  info.BytesOut,
+	}
+	record.Id = info.ID
+	if err := m.UpdateById(context.Background(), record); err != nil {
+		logx.Errorf("更新端口转发[%d]记录失败: %s", info.ID, err.Error())
+	}
+}
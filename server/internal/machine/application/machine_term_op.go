@@ -3,10 +3,16 @@ package application
 import (
 	"context"
 	"fmt"
+	"io"
+	"mayfly-go/internal/machine/application/dto"
 	"mayfly-go/internal/machine/config"
 	"mayfly-go/internal/machine/domain/entity"
 	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/internal/machine/guac"
 	"mayfly-go/internal/machine/mcm"
+	msgapp "mayfly-go/internal/msg/application"
+	sysapp "mayfly-go/internal/sys/application"
+	tagapp "mayfly-go/internal/tag/application"
 	"mayfly-go/pkg/base"
 	"mayfly-go/pkg/contextx"
 	"mayfly-go/pkg/errorx"
@@ -25,19 +31,50 @@ import (
 type MachineTermOp interface {
 	base.App[*entity.MachineTermOp]
 
-	// 终端连接操作
-	TermConn(ctx context.Context, cli *mcm.Cli, wsConn *websocket.Conn, rows, cols int) error
+	// 终端连接操作，target不为nil时通过机器执行docker exec或kubectl exec进入容器
+	TermConn(ctx context.Context, cli *mcm.Cli, wsConn *websocket.Conn, rows, cols int, target *mcm.TerminalTarget) error
+
+	// 图形化(RDP/VNC)会话连接操作，机器开启终端回放时rec为录像写入器，否则为nil。会话结束后保存操作记录
+	GuacConn(ctx context.Context, mi *mcm.MachineInfo, conn func(rec io.Writer) error) error
+
+	// 按录像时间回放图形化会话记录，speed为回放倍速
+	ReplayGuacTermOp(ctx context.Context, termOp *entity.MachineTermOp, speed float64, send func(data []byte) error) error
 
 	GetPageList(condition *entity.MachineTermOp, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
 
 	// 定时删除终端文件回放记录
 	TimerDeleteTermOp()
+
+	// 获取活跃的终端会话，accountId不为0时只获取该账号拥有或受邀协作的会话
+	GetTermSessions(accountId uint64) []*mcm.TerminalSessionInfo
+
+	// 会话拥有者邀请协作者，协作者可附加至会话并写入终端
+	InviteTermSessionCollaborator(ctx context.Context, sessionId string, username string) error
+
+	// 会话拥有者移除协作者
+	RemoveTermSessionCollaborator(ctx context.Context, sessionId string, accountId uint64) error
+
+	// 附加至终端会话，协作者可写入终端，monitor为true时以只读模式附加
+	AttachTermSession(ctx context.Context, sessionId string, wsConn *websocket.Conn, monitor bool) error
+
+	// 强制终止终端会话，并通知会话拥有者
+	TerminateTermSession(ctx context.Context, sessionId string, reason string) error
+
+	// 获取当前登录账号可审批的待审批命令
+	GetPendingCmdApprovals(ctx context.Context) []*dto.MachineCmdApproval
+
+	// 实时审批挂起的终端命令，pass为true则继续执行该命令，否则丢弃
+	ApproveCmd(ctx context.Context, id string, pass bool) error
 }
 
 type machineTermOpAppImpl struct {
 	base.AppImpl[*entity.MachineTermOp, repository.MachineTermOp]
 
-	machineCmdConfApp MachineCmdConf `inject:"MachineCmdConfApp"`
+	machineCmdConfApp     MachineCmdConf     `inject:"MachineCmdConfApp"`
+	machineTermOpIndexApp MachineTermOpIndex `inject:"MachineTermOpIndexApp"`
+	accountApp            sysapp.Account     `inject:"AccountApp"`
+	msgApp                msgapp.Msg         `inject:"MsgApp"`
+	tagApp                tagapp.TagTree     `inject:"TagTreeApp"`
 }
 
 // 注入MachineTermOpRepo
@@ -45,14 +82,20 @@ func (m *machineTermOpAppImpl) InjectMachineTermOpRepo(repo repository.MachineTe
 	m.Repo = repo
 }
 
-func (m *machineTermOpAppImpl) TermConn(ctx context.Context, cli *mcm.Cli, wsConn *websocket.Conn, rows, cols int) error {
+func (m *machineTermOpAppImpl) TermConn(ctx context.Context, cli *mcm.Cli, wsConn *websocket.Conn, rows, cols int, target *mcm.TerminalTarget) error {
+	if target != nil {
+		if err := target.Validate(); err != nil {
+			return err
+		}
+	}
+
 	var recorder *mcm.Recorder
 	var termOpRecord *entity.MachineTermOp
+	la := contextx.GetLoginAccount(ctx)
 
 	// 开启终端操作记录
 	if cli.Info.EnableRecorder == 1 {
 		now := time.Now()
-		la := contextx.GetLoginAccount(ctx)
 
 		termOpRecord = new(entity.MachineTermOp)
 
@@ -61,21 +104,19 @@ func (m *machineTermOpAppImpl) TermConn(ctx context.Context, cli *mcm.Cli, wsCon
 		termOpRecord.CreatorId = la.Id
 
 		termOpRecord.MachineId = cli.Info.Id
+		termOpRecord.Protocol = entity.MachineProtocolSsh
 		termOpRecord.Username = cli.Info.Username
+		if target != nil {
+			termOpRecord.Target = target.String()
+		}
 
-		// 回放文件路径为: 基础配置路径/机器编号/操作日期(202301)/day/hour/randstr.cast
-		recRelPath := path.Join(cli.Info.Code, now.Format("200601"), fmt.Sprintf("%d", now.Day()), fmt.Sprintf("%d", now.Hour()))
-		// 文件绝对路径
-		recAbsPath := path.Join(config.GetMachine().TerminalRecPath, recRelPath)
-		os.MkdirAll(recAbsPath, 0766)
-		filename := fmt.Sprintf("%s.cast", stringx.RandByChars(18, stringx.LowerChars))
-		f, err := os.OpenFile(path.Join(recAbsPath, filename), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0766)
+		f, recFilePath, err := createRecFile(cli.Info.Code, now, "cast")
 		if err != nil {
-			return errorx.NewBiz("创建终端回放记录文件失败: %s", err.Error())
+			return err
 		}
 		defer f.Close()
 
-		termOpRecord.RecordFilePath = path.Join(recRelPath, filename)
+		termOpRecord.RecordFilePath = recFilePath
 		recorder = mcm.NewRecorder(f)
 	}
 
@@ -86,18 +127,16 @@ func (m *machineTermOpAppImpl) TermConn(ctx context.Context, cli *mcm.Cli, wsCon
 		Rows:      rows,
 		Cols:      cols,
 		Recorder:  recorder,
+		Target:    target,
 		LogCmd:    cli.Info.EnableRecorder == 1,
+		AccountId: la.Id,
+		Username:  la.Username,
 	}
 
 	cmdConfs := m.machineCmdConfApp.GetCmdConfsByMachineTags(ctx, cli.Info.CodePath...)
 	if len(cmdConfs) > 0 {
-		createTsParam.CmdFilterFuncs = []mcm.CmdFilterFunc{func(cmd string) error {
-			for _, cmdConf := range cmdConfs {
-				if cmdConf.CmdRegexp.Match([]byte(cmd)) {
-					return errorx.NewBiz("该命令已被禁用...")
-				}
-			}
-			return nil
+		createTsParam.CmdFilterFuncs = []mcm.CmdFilterFunc{func(username string, cmd string) error {
+			return m.filterCmd(createTsParam.SessionId, cli.Info, username, cmd, cmdConfs)
 		}}
 	}
 
@@ -113,11 +152,81 @@ func (m *machineTermOpAppImpl) TermConn(ctx context.Context, cli *mcm.Cli, wsCon
 		now := time.Now()
 		termOpRecord.EndTime = &now
 		termOpRecord.ExecCmds = jsonx.ToStr(mts.GetExecCmds())
-		return m.Insert(ctx, termOpRecord)
+		if err := m.Insert(ctx, termOpRecord); err != nil {
+			return err
+		}
+
+		// 异步建立执行命令及终端输出的检索索引
+		go func() {
+			if err := m.machineTermOpIndexApp.IndexTermOp(context.Background(), termOpRecord); err != nil {
+				logx.Errorf("终端操作记录[%d]建立索引失败: %s", termOpRecord.Id, err.Error())
+			}
+		}()
 	}
 	return nil
 }
 
+func (m *machineTermOpAppImpl) GuacConn(ctx context.Context, mi *mcm.MachineInfo, conn func(rec io.Writer) error) error {
+	if mi.EnableRecorder != 1 {
+		return conn(nil)
+	}
+
+	la := contextx.GetLoginAccount(ctx)
+	now := time.Now()
+	f, recFilePath, err := createRecFile(mi.Code, now, "guac")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := conn(f); err != nil {
+		// 连接失败，无需保存操作记录
+		f.Close()
+		os.Remove(path.Join(config.GetMachine().TerminalRecPath, recFilePath))
+		return err
+	}
+
+	endTime := time.Now()
+	termOpRecord := &entity.MachineTermOp{
+		MachineId:      mi.Id,
+		Protocol:       mi.Protocol,
+		Username:       mi.Username,
+		RecordFilePath: recFilePath,
+		CreateTime:     &now,
+		CreatorId:      la.Id,
+		Creator:        la.Username,
+		EndTime:        &endTime,
+	}
+	return m.Insert(ctx, termOpRecord)
+}
+
+func (m *machineTermOpAppImpl) ReplayGuacTermOp(ctx context.Context, termOp *entity.MachineTermOp, speed float64, send func(data []byte) error) error {
+	if !termOp.IsGraphical() {
+		return errorx.NewBiz("该记录不是图形化会话记录")
+	}
+
+	f, err := os.Open(path.Join(config.GetMachine().TerminalRecPath, termOp.RecordFilePath))
+	if err != nil {
+		return errorx.NewBiz("读取会话录像失败: %s", err.Error())
+	}
+	defer f.Close()
+	return guac.Replay(ctx, f, speed, send)
+}
+
+// 创建回放文件，文件路径为: 基础配置路径/机器编号/操作日期(202301)/day/hour/randstr.ext，返回文件及相对路径
+func createRecFile(machineCode string, now time.Time, ext string) (*os.File, string, error) {
+	recRelPath := path.Join(machineCode, now.Format("200601"), fmt.Sprintf("%d", now.Day()), fmt.Sprintf("%d", now.Hour()))
+	// 文件绝对路径
+	recAbsPath := path.Join(config.GetMachine().TerminalRecPath, recRelPath)
+	os.MkdirAll(recAbsPath, 0766)
+	filename := fmt.Sprintf("%s.%s", stringx.RandByChars(18, stringx.LowerChars), ext)
+	f, err := os.OpenFile(path.Join(recAbsPath, filename), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0766)
+	if err != nil {
+		return nil, "", errorx.NewBiz("创建终端回放记录文件失败: %s", err.Error())
+	}
+	return f, path.Join(recRelPath, filename), nil
+}
+
 func (m *machineTermOpAppImpl) GetPageList(condition *entity.MachineTermOp, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
 	return m.GetRepo().GetPageList(condition, pageParam, toEntity)
 }
@@ -148,6 +257,9 @@ func (m *machineTermOpAppImpl) DeleteTermOp(basePath string, termOp *entity.Mach
 	if err := m.DeleteById(context.Background(), termOp.Id); err != nil {
 		return err
 	}
+	if err := m.machineTermOpIndexApp.DeleteByTermOpId(context.Background(), termOp.Id); err != nil {
+		return err
+	}
 
 	return os.Remove(path.Join(basePath, termOp.RecordFilePath))
 }
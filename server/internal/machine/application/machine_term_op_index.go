@@ -0,0 +1,130 @@
+package application
+
+import (
+	"context"
+	"mayfly-go/internal/machine/config"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/internal/machine/mcm"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/jsonx"
+	"os"
+	"path"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// 索引批量写入条数
+	termOpIndexBatchSize = 500
+	// 索引内容最大字符数，与content字段长度一致
+	maxTermOpIndexContentLen = 1000
+)
+
+type MachineTermOpIndex interface {
+	base.App[*entity.MachineTermOpIndex]
+
+	// 分页检索终端操作记录中执行的命令及终端输出
+	GetPageList(condition *entity.MachineTermOpIndexQuery, pageParam *model.PageParam, toEntity any) (*model.PageResult[any], error)
+
+	// 为终端操作记录的执行命令及回放文件输出内容建立索引
+	IndexTermOp(ctx context.Context, termOp *entity.MachineTermOp) error
+
+	// 删除终端操作记录的索引
+	DeleteByTermOpId(ctx context.Context, termOpId uint64) error
+}
+
+type machineTermOpIndexAppImpl struct {
+	base.AppImpl[*entity.MachineTermOpIndex, repository.MachineTermOpIndex]
+}
+
+var _ (MachineTermOpIndex) = (*machineTermOpIndexAppImpl)(nil)
+
+// 注入MachineTermOpIndexRepo
+func (m *machineTermOpIndexAppImpl) InjectMachineTermOpIndexRepo(repo repository.MachineTermOpIndex) {
+	m.Repo = repo
+}
+
+func (m *machineTermOpIndexAppImpl) GetPageList(condition *entity.MachineTermOpIndexQuery, pageParam *model.PageParam, toEntity any) (*model.PageResult[any], error) {
+	return m.GetRepo().GetPageList(condition, pageParam, toEntity)
+}
+
+func (m *machineTermOpIndexAppImpl) IndexTermOp(ctx context.Context, termOp *entity.MachineTermOp) error {
+	if termOp.IsGraphical() {
+		return errorx.NewBiz("图形化会话记录不支持建立索引")
+	}
+
+	// 在同一事务中清除旧索引并写入新索引，避免失败时索引丢失或重复
+	return m.Tx(ctx, func(ctx context.Context) error {
+		if err := m.DeleteByTermOpId(ctx, termOp.Id); err != nil {
+			return err
+		}
+		return m.indexTermOp(ctx, termOp)
+	})
+}
+
+func (m *machineTermOpIndexAppImpl) indexTermOp(ctx context.Context, termOp *entity.MachineTermOp) error {
+	startTime := *termOp.CreateTime
+	indexes := make([]*entity.MachineTermOpIndex, 0, termOpIndexBatchSize)
+	add := func(typ int8, content string, opTime time.Time) error {
+		// 粘贴的长命令等超出字段长度会导致整个索引写入失败，故截断
+		if utf8.RuneCountInString(content) > maxTermOpIndexContentLen {
+			content = string([]rune(content)[:maxTermOpIndexContentLen])
+		}
+		indexes = append(indexes, &entity.MachineTermOpIndex{
+			TermOpId:  termOp.Id,
+			MachineId: termOp.MachineId,
+			Creator:   termOp.Creator,
+			Type:      typ,
+			Content:   content,
+			RecOffset: max(opTime.Sub(startTime).Seconds(), 0),
+			OpTime:    &opTime,
+		})
+		if len(indexes) < termOpIndexBatchSize {
+			return nil
+		}
+		err := m.BatchInsert(ctx, indexes)
+		indexes = indexes[:0]
+		return err
+	}
+
+	var execCmds []*mcm.ExecutedCmd
+	if termOp.ExecCmds != "" {
+		var err error
+		if execCmds, err = jsonx.To(termOp.ExecCmds, execCmds); err != nil {
+			logx.Warnf("解析终端操作记录[%d]的执行命令失败: %s", termOp.Id, err.Error())
+		}
+	}
+	for _, cmd := range execCmds {
+		if err := add(entity.TermOpIndexTypeCmd, cmd.Cmd, time.Unix(cmd.Time, 0)); err != nil {
+			return err
+		}
+	}
+
+	if termOp.RecordFilePath != "" {
+		f, err := os.Open(path.Join(config.GetMachine().TerminalRecPath, termOp.RecordFilePath))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		err = mcm.ReadRecOutputLines(f, func(line *mcm.RecOutputLine) error {
+			return add(entity.TermOpIndexTypeOutput, line.Line, startTime.Add(time.Duration(line.Offset*float64(time.Second))))
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(indexes) > 0 {
+		return m.BatchInsert(ctx, indexes)
+	}
+	return nil
+}
+
+func (m *machineTermOpIndexAppImpl) DeleteByTermOpId(ctx context.Context, termOpId uint64) error {
+	return m.GetRepo().DeleteByTermOpId(ctx, termOpId)
+}
@@ -0,0 +1,110 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"mayfly-go/internal/machine/mcm"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	sysentity "mayfly-go/internal/sys/domain/entity"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/collx"
+
+	"github.com/gorilla/websocket"
+)
+
+func (m *machineTermOpAppImpl) GetTermSessions(accountId uint64) []*mcm.TerminalSessionInfo {
+	var filter func(ts *mcm.TerminalSession) bool
+	if accountId != 0 {
+		filter = func(ts *mcm.TerminalSession) bool {
+			return ts.Info.AccountId == accountId || ts.IsInvitee(accountId)
+		}
+	}
+	return collx.ArrayMap(mcm.GetTerminalSessions(filter), func(ts *mcm.TerminalSession) *mcm.TerminalSessionInfo {
+		return ts.GetInfo()
+	})
+}
+
+func (m *machineTermOpAppImpl) InviteTermSessionCollaborator(ctx context.Context, sessionId string, username string) error {
+	ts, err := m.getOwnTermSession(ctx, sessionId)
+	if err != nil {
+		return err
+	}
+
+	account := &sysentity.Account{Username: username}
+	if err := m.accountApp.GetByCond(account); err != nil {
+		return errorx.NewBiz("用户[%s]不存在", username)
+	}
+	if account.Id == ts.Info.AccountId {
+		return errorx.NewBiz("不能邀请自己")
+	}
+	if err := m.tagApp.CanAccess(account.Id, ts.Info.CodePath...); err != nil {
+		return errorx.NewBiz("用户[%s]无该机器的访问权限", username)
+	}
+
+	ts.Invite(account.Id, account.Username)
+	m.msgApp.CreateAndSend(&model.LoginAccount{Id: account.Id, Username: "system"},
+		msgdto.InfoSysMsg("终端协作邀请", fmt.Sprintf("[%s]邀请您协作机器[%s]的终端会话[%s]", ts.Info.Username, ts.Info.MachineName, ts.ID)))
+	return nil
+}
+
+func (m *machineTermOpAppImpl) RemoveTermSessionCollaborator(ctx context.Context, sessionId string, accountId uint64) error {
+	ts, err := m.getOwnTermSession(ctx, sessionId)
+	if err != nil {
+		return err
+	}
+	ts.RemoveInvitee(accountId)
+	return nil
+}
+
+func (m *machineTermOpAppImpl) AttachTermSession(ctx context.Context, sessionId string, wsConn *websocket.Conn, monitor bool) error {
+	ts := mcm.GetTerminalSession(sessionId)
+	if ts == nil {
+		return errorx.NewBiz("终端会话不存在或已结束")
+	}
+
+	la := contextx.GetLoginAccount(ctx)
+	if la.Id == ts.Info.AccountId {
+		return errorx.NewBiz("不能附加至自己的终端会话")
+	}
+	// 协作或监控均需具有该机器的访问权限，防止权限被回收后仍可附加
+	if err := m.tagApp.CanAccess(la.Id, ts.Info.CodePath...); err != nil {
+		return err
+	}
+	// 受邀协作者可写，其余具有监控权限的用户只读
+	writable := ts.IsInvitee(la.Id)
+	if !writable && !monitor {
+		return errorx.NewBiz("您没有权限附加至该终端会话")
+	}
+	return ts.Attach(wsConn, la.Id, la.Username, writable)
+}
+
+func (m *machineTermOpAppImpl) TerminateTermSession(ctx context.Context, sessionId string, reason string) error {
+	ts := mcm.GetTerminalSession(sessionId)
+	if ts == nil {
+		return errorx.NewBiz("终端会话不存在或已结束")
+	}
+
+	notice := fmt.Sprintf("已被管理员[%s]强制终止", contextx.GetLoginAccount(ctx).Username)
+	if reason != "" {
+		notice = fmt.Sprintf("%s, 原因: %s", notice, reason)
+	}
+	ts.Terminate("该终端会话" + notice)
+
+	m.msgApp.CreateAndSend(&model.LoginAccount{Id: ts.Info.AccountId, Username: "system"},
+		msgdto.ErrSysMsg("终端会话已终止", fmt.Sprintf("您在机器[%s]上的终端会话%s", ts.Info.MachineName, notice)))
+	return nil
+}
+
+// 获取当前登录账号拥有的终端会话
+func (m *machineTermOpAppImpl) getOwnTermSession(ctx context.Context, sessionId string) (*mcm.TerminalSession, error) {
+	ts := mcm.GetTerminalSession(sessionId)
+	if ts == nil {
+		return nil, errorx.NewBiz("终端会话不存在或已结束")
+	}
+	if ts.Info.AccountId != contextx.GetLoginAccount(ctx).Id {
+		return nil, errorx.NewBiz("只有会话拥有者可进行该操作")
+	}
+	return ts, nil
+}
@@ -4,6 +4,7 @@ import (
 	sysapp "mayfly-go/internal/sys/application"
 	"mayfly-go/pkg/logx"
 	"mayfly-go/pkg/utils/bytex"
+	"strings"
 
 	"github.com/may-fly/cast"
 )
@@ -20,6 +21,10 @@ type Machine struct {
 	GuacdPort         int    // guacd服务端口  默认 4822
 	GuacdFilePath     string // guacd服务文件存储位置，用于挂载RDP文件夹
 	GuacdRecPath      string // guacd服务记录存储位置，用于记录rdp操作记录
+	MonitorSaveDays   int    // 监控数据保存天数
+
+	PortForwardBindAddr    string   // 端口转发监听地址，默认 127.0.0.1
+	PortForwardRemoteHosts []string // 端口转发允许的目标地址（ip、网段或主机名），为空则只允许转发至机器本地
 }
 
 // 获取机器相关配置
@@ -52,6 +57,12 @@ func GetMachine() *Machine {
 	mc.GuacdPort = cast.ToIntD(jm["guacdPort"], 4822)
 	mc.GuacdFilePath = cast.ToStringD(jm["guacdFilePath"], "")
 	mc.GuacdRecPath = cast.ToStringD(jm["guacdRecPath"], "")
+	mc.MonitorSaveDays = cast.ToIntD(jm["monitorSaveDays"], 7)
+	// 端口转发
+	mc.PortForwardBindAddr = cast.ToStringD(jm["portForwardBindAddr"], "127.0.0.1")
+	if remoteHosts := strings.TrimSpace(jm["portForwardRemoteHosts"]); remoteHosts != "" {
+		mc.PortForwardRemoteHosts = strings.Split(remoteHosts, ",")
+	}
 
 	return mc
 }
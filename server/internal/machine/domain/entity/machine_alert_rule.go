@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"mayfly-go/pkg/model"
+)
+
+// 机器监控告警规则，通过标签关联机器
+type MachineAlertRule struct {
+	model.Model
+
+	Name      string              `json:"name"`
+	Metric    AlertMetric         `json:"metric"`    // 告警指标
+	Threshold float32             `json:"threshold"` // 阈值，指标值大于等于该值即为超出
+	Duration  int                 `json:"duration"`  // 持续时间(分钟)，指标持续超出阈值该时长后告警
+	Receivers model.Slice[string] `json:"receivers"` // 告警接收人用户名
+	Status    int8                `json:"status"`    // 状态
+	Remark    string              `json:"remark"`
+}
+
+const (
+	MachineAlertRuleStatusEnable  int8 = 1
+	MachineAlertRuleStatusDisable int8 = -1
+)
+
+// 告警指标
+type AlertMetric string
+
+const (
+	AlertMetricCpu   AlertMetric = "cpu"   // cpu使用率
+	AlertMetricMem   AlertMetric = "mem"   // 内存使用率
+	AlertMetricDisk  AlertMetric = "disk"  // 文件系统使用率
+	AlertMetricLoad1 AlertMetric = "load1" // 1分钟平均负载
+)
+
+var alertMetricNames = map[AlertMetric]string{
+	AlertMetricCpu:   "CPU使用率",
+	AlertMetricMem:   "内存使用率",
+	AlertMetricDisk:  "磁盘使用率",
+	AlertMetricLoad1: "系统负载",
+}
+
+// 获取指标名称，不存在则返回空字符串
+func (am AlertMetric) Name() string {
+	return alertMetricNames[am]
+}
+
+// 获取监控记录中对应指标的值
+func (am AlertMetric) Value(mm *MachineMonitor) float32 {
+	switch am {
+	case AlertMetricCpu:
+		return mm.CpuRate
+	case AlertMetricMem:
+		return mm.MemRate
+	case AlertMetricDisk:
+		return mm.DiskRate
+	case AlertMetricLoad1:
+		return mm.Load1
+	}
+	return 0
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"mayfly-go/pkg/model"
+	"time"
+)
+
+// 按标签批量执行命令任务
+type MachineBatchExec struct {
+	model.Model
+
+	Name         string              `json:"name" form:"name"`
+	Script       string              `json:"script"`      // 执行的命令
+	TagPaths     model.Slice[string] `json:"tagPaths"`    // 执行的机器所属标签
+	Concurrency  int                 `json:"concurrency"` // 并发执行的机器数
+	Timeout      int                 `json:"timeout"`     // 单台机器执行超时时间(秒)
+	Status       int8                `json:"status" form:"status"`
+	MachineCount int                 `json:"machineCount"`
+	SuccessCount int                 `json:"successCount"`
+	FailCount    int                 `json:"failCount"`
+	StartTime    *time.Time          `json:"startTime"`
+	EndTime      *time.Time          `json:"endTime"`
+}
+
+// 批量执行任务中单台机器的执行结果
+type MachineBatchExecRes struct {
+	model.DeletedModel
+
+	BatchExecId uint64     `json:"batchExecId" form:"batchExecId"`
+	MachineId   uint64     `json:"machineId"`
+	MachineCode string     `json:"machineCode"`
+	MachineName string     `json:"machineName"`
+	Ip          string     `json:"ip"`
+	Status      int8       `json:"status" form:"status"` // 执行状态
+	Res         string     `json:"res"`                  // 执行输出
+	StartTime   *time.Time `json:"startTime"`
+	EndTime     *time.Time `json:"endTime"`
+}
+
+const (
+	MachineBatchExecStatusRunning int8 = 1 // 执行中
+	MachineBatchExecStatusSuccess int8 = 2 // 全部成功
+	MachineBatchExecStatusFail    int8 = 3 // 存在失败
+
+	MachineBatchExecResStatusSuccess int8 = 1
+	MachineBatchExecResStatusFail    int8 = -1
+	MachineBatchExecResStatusTimeout int8 = -2
+)
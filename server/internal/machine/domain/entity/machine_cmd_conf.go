@@ -8,9 +8,18 @@ import (
 type MachineCmdConf struct {
 	model.Model
 
-	Name     string              `json:"name"`
-	Cmds     model.Slice[string] `json:"cmds"`     // 命令配置
-	Status   int8                `json:"execCmds"` // 状态
-	Stratege string              `json:"stratege"` // 策略，空禁用
-	Remark   string              `json:"remark"`   // 备注
+	Name      string              `json:"name"`
+	Cmds      model.Slice[string] `json:"cmds"`      // 命令配置
+	Status    int8                `json:"execCmds"`  // 状态
+	Stratege  string              `json:"stratege"`  // 策略，空禁用
+	Receivers model.Slice[string] `json:"receivers"` // 告警接收人或审批人用户名
+	Remark    string              `json:"remark"`    // 备注
 }
+
+// 命令匹配后的处理策略
+const (
+	MachineCmdStrategeDeny     = ""         // 禁止执行
+	MachineCmdStrategeWarn     = "warn"     // 提示警告后继续执行
+	MachineCmdStrategeAlert    = "alert"    // 继续执行，并记录及通知告警接收人
+	MachineCmdStrategeApproval = "approval" // 挂起命令，审批人实时审批通过后执行，拒绝或超时则丢弃
+)
@@ -17,6 +17,13 @@ type MachineCronJob struct {
 	Remark          string     `json:"remark"` // 备注
 	LastExecTime    *time.Time `json:"lastExecTime"`
 	SaveExecResType int        `json:"saveExecResType"` // 记录执行结果类型
+
+	Timeout             int                 `json:"timeout"`             // 单次执行最大时间(秒)，0为不限制
+	RetryCount          int                 `json:"retryCount"`          // 失败重试次数
+	RetryInterval       int                 `json:"retryInterval"`       // 首次重试间隔(秒)，之后每次重试间隔翻倍
+	SkipIfRunning       int8                `json:"skipIfRunning"`       // 上次执行未结束时是否跳过本次执行
+	FailNotifyThreshold int                 `json:"failNotifyThreshold"` // 连续失败达到该次数时发送通知，0为不通知
+	Receivers           model.Slice[string] `json:"receivers"`           // 失败通知接收人用户名，为空则通知创建人
 }
 
 // 机器任务执行记录
@@ -40,4 +47,6 @@ const (
 	SaveExecResTypeNo      = -1 // 不记录执行日志
 	SaveExecResTypeOnError = 1  // 执行错误时记录日志
 	SaveExecResTypeYes     = 2  // 记录日志
+
+	MachineCronJobSkipIfRunningYes int8 = 1
 )
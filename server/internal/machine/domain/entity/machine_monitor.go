@@ -1,14 +1,22 @@
 package entity
 
 import (
+	"mayfly-go/pkg/model"
 	"time"
 )
 
+// 机器监控指标采样记录
 type MachineMonitor struct {
-	Id         uint64    `json:"id"`
+	model.DeletedModel
+
 	MachineId  uint64    `json:"machineId"`
-	CpuRate    float32   `json:"cpuRate"`
-	MemRate    float32   `json:"memRate"`
-	SysLoad    string    `json:"sysLoad"`
+	CpuRate    float32   `json:"cpuRate"`   // cpu使用率
+	MemRate    float32   `json:"memRate"`   // 内存使用率
+	SysLoad    string    `json:"sysLoad"`   // 系统负载，如: 0.10 0.20 0.30
+	Load1      float32   `json:"load1"`     // 1分钟平均负载
+	DiskRate   float32   `json:"diskRate"`  // 使用率最高的文件系统使用率
+	FsInfos    string    `json:"fsInfos"`   // 各文件系统使用信息json
+	NetRxRate  uint64    `json:"netRxRate"` // 网络接收速率(字节/秒)
+	NetTxRate  uint64    `json:"netTxRate"` // 网络发送速率(字节/秒)
 	CreateTime time.Time `json:"createTime"`
 }
@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"mayfly-go/pkg/model"
+	"time"
+)
+
+// 端口转发会话审计记录
+type MachinePortForward struct {
+	model.Model
+
+	MachineId    uint64     `json:"machineId"`
+	MachineCode  string     `json:"machineCode"`
+	MachineName  string     `json:"machineName"`
+	AuthCertName string     `json:"authCertName"`
+	LocalPort    int        `json:"localPort"`  // mayfly-go服务端监听端口
+	RemoteHost   string     `json:"remoteHost"` // 机器所在网络中的目标地址
+	RemotePort   int        `json:"remotePort"`
+	Duration     int        `json:"duration"` // 有效时长(分钟)
+	Status       int8       `json:"status"`
+	ExpireTime   *time.Time `json:"expireTime"`
+	CloseTime    *time.Time `json:"closeTime"`
+	Closer       string     `json:"closer"` // 手动关闭人
+	ConnCount    int64      `json:"connCount"`
+	BytesIn      int64      `json:"bytesIn"`
+	BytesOut     int64      `json:"bytesOut"`
+}
+
+const (
+	MachinePortForwardStatusActive  int8 = 1  // 转发中
+	MachinePortForwardStatusClosed  int8 = 2  // 手动关闭
+	MachinePortForwardStatusExpired int8 = 3  // 到期关闭
+	MachinePortForwardStatusError   int8 = -1 // 异常关闭
+)
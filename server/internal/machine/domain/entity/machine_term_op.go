@@ -9,8 +9,10 @@ type MachineTermOp struct {
 	model.DeletedModel
 
 	MachineId      uint64 `json:"machineId"`
+	Protocol       int    `json:"protocol"` // 会话协议，1ssh 2rdp 3vnc
 	Username       string `json:"username"`
-	RecordFilePath string `json:"recordFilePath"` // 回放文件路径
+	Target         string `json:"target"`         // 终端目标，为空则为机器shell，否则为进入的容器，如docker:nginx
+	RecordFilePath string `json:"recordFilePath"` // 回放文件路径，ssh为asciinema录像，rdp/vnc为guacamole指令流录像
 	ExecCmds       string `json:"execCmds"`       // 执行的命令
 
 	CreateTime *time.Time `json:"createTime"`
@@ -18,3 +20,8 @@ type MachineTermOp struct {
 	Creator    string     `json:"creator"`
 	EndTime    *time.Time `json:"endTime"`
 }
+
+// IsGraphical 是否为图形化(RDP/VNC)会话记录
+func (m *MachineTermOp) IsGraphical() bool {
+	return m.Protocol == MachineProtocolRdp || m.Protocol == MachineProtocolVnc
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"mayfly-go/pkg/model"
+	"time"
+)
+
+// 终端操作记录索引内容类型
+const (
+	TermOpIndexTypeCmd    int8 = 1 // 执行的命令
+	TermOpIndexTypeOutput int8 = 2 // 终端输出
+)
+
+// 终端操作记录全文索引，用于检索执行的命令及终端输出，并定位至回放文件中的时间点
+type MachineTermOpIndex struct {
+	model.DeletedModel
+
+	TermOpId  uint64     `json:"termOpId"`
+	MachineId uint64     `json:"machineId"`
+	Creator   string     `json:"creator"`   // 终端操作人
+	Type      int8       `json:"type"`      // 内容类型
+	Content   string     `json:"content"`   // 命令或输出行内容
+	RecOffset float64    `json:"recOffset"` // 距回放开始的秒数
+	OpTime    *time.Time `json:"opTime"`    // 命令执行或输出时间
+}
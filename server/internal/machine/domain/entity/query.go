@@ -23,3 +23,26 @@ type AuthCertQuery struct {
 type MachineTermOpQuery struct {
 	StartCreateTime *time.Time
 }
+
+type MachineMonitorQuery struct {
+	MachineId uint64     `json:"machineId" form:"machineId"`
+	StartTime *time.Time `json:"startTime" form:"startTime" time_format:"2006-01-02 15:04:05"`
+	EndTime   *time.Time `json:"endTime" form:"endTime" time_format:"2006-01-02 15:04:05"`
+}
+
+type MachineTermOpIndexQuery struct {
+	Keyword   string     `json:"keyword" form:"keyword"`
+	MachineId uint64     `json:"machineId" form:"machineId"`
+	Creator   string     `json:"creator" form:"creator"`
+	Type      int8       `json:"type" form:"type"`
+	StartTime *time.Time `json:"startTime" form:"startTime" time_format:"2006-01-02 15:04:05"`
+	EndTime   *time.Time `json:"endTime" form:"endTime" time_format:"2006-01-02 15:04:05"`
+}
+
+type MachinePortForwardQuery struct {
+	MachineId uint64 `json:"machineId" form:"machineId"`
+	Status    int8   `json:"status" form:"status"`
+	Creator   string `json:"creator" form:"creator"`
+
+	CreatorId uint64
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type MachineBatchExec interface {
+	base.Repo[*entity.MachineBatchExec]
+
+	// 分页获取批量执行任务列表
+	GetPageList(condition *entity.MachineBatchExec, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+}
+
+type MachineBatchExecRes interface {
+	base.Repo[*entity.MachineBatchExecRes]
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/pkg/base"
+	"time"
+)
+
+type MachineMonitor interface {
+	base.Repo[*entity.MachineMonitor]
+
+	// 根据条件获取监控记录列表，按采样时间升序
+	SelectByQuery(cond *entity.MachineMonitorQuery) ([]*entity.MachineMonitor, error)
+
+	// 物理删除指定时间之前的监控记录
+	DeleteBefore(time time.Time) error
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type MachinePortForward interface {
+	base.Repo[*entity.MachinePortForward]
+
+	// 分页获取端口转发审计记录
+	GetPageList(condition *entity.MachinePortForwardQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+}
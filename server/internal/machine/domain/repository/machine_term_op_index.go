@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type MachineTermOpIndex interface {
+	base.Repo[*entity.MachineTermOpIndex]
+
+	// 分页检索终端操作记录索引
+	GetPageList(condition *entity.MachineTermOpIndexQuery, pageParam *model.PageParam, toEntity any) (*model.PageResult[any], error)
+
+	// 物理删除终端操作记录的索引
+	DeleteByTermOpId(ctx context.Context, termOpId uint64) error
+}
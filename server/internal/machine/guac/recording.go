@@ -0,0 +1,150 @@
+package guac
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 回放倍速限制
+const (
+	MinReplaySpeed = 0.5
+	MaxReplaySpeed = 16
+)
+
+// recordingInstructionReader 将guacd发送至客户端的指令同时写入录像文件
+type recordingInstructionReader struct {
+	InstructionReader
+
+	mu  sync.Mutex
+	rec io.Writer
+	err error // 录像写入失败后不再继续写入，不影响会话本身
+}
+
+// NewRecordingReader 包装guacd指令读取器，读取的指令同时写入rec，录像格式与guacd原生录像一致（指令流）
+func NewRecordingReader(reader InstructionReader, rec io.Writer) InstructionReader {
+	return &recordingInstructionReader{InstructionReader: reader, rec: rec}
+}
+
+func (r *recordingInstructionReader) ReadSome() ([]byte, error) {
+	ins, err := r.InstructionReader.ReadSome()
+	if err != nil || bytes.HasPrefix(ins, internalOpcodeIns) {
+		return ins, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		_, r.err = r.rec.Write(ins)
+	}
+	return ins, nil
+}
+
+// ReadRecordingInstruction 从录像中读取一条完整指令，元素长度以unicode字符计
+func ReadRecordingInstruction(br *bufio.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	for {
+		// 读取元素长度
+		length := 0
+		digits := 0
+		for {
+			c, _, err := br.ReadRune()
+			if err != nil {
+				if errors.Is(err, io.EOF) && buf.Len() > 0 {
+					return nil, io.ErrUnexpectedEOF
+				}
+				return nil, err
+			}
+			buf.WriteRune(c)
+			if c == '.' {
+				break
+			}
+			if c < '0' || c > '9' {
+				return nil, errors.New("guac: invalid recording instruction")
+			}
+			length = length*10 + int(c-'0')
+			digits++
+		}
+		if digits == 0 {
+			return nil, errors.New("guac: invalid recording instruction")
+		}
+
+		// 读取元素值及结束符
+		for i := 0; i <= length; i++ {
+			c, _, err := br.ReadRune()
+			if err != nil {
+				return nil, io.ErrUnexpectedEOF
+			}
+			buf.WriteRune(c)
+		}
+		switch buf.Bytes()[buf.Len()-1] {
+		case ';':
+			return buf.Bytes(), nil
+		case ',':
+		default:
+			return nil, errors.New("guac: invalid recording instruction")
+		}
+	}
+}
+
+// Replay 按录像中sync指令的时间戳回放录像，speed为回放倍速，每帧的指令通过send发送
+func Replay(ctx context.Context, r io.Reader, speed float64, send func(data []byte) error) error {
+	if speed < MinReplaySpeed || speed > MaxReplaySpeed {
+		return errors.New("guac: invalid replay speed")
+	}
+
+	br := bufio.NewReaderSize(r, MaxGuacMessage)
+	buf := bytes.NewBuffer(make([]byte, 0, MaxGuacMessage*2))
+	var lastTimestamp int64 = -1
+
+	for {
+		ins, err := ReadRecordingInstruction(br)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+		buf.Write(ins)
+
+		if !bytes.HasPrefix(ins, []byte("4.sync,")) {
+			if buf.Len() >= MaxGuacMessage {
+				if err := send(buf.Bytes()); err != nil {
+					return err
+				}
+				buf.Reset()
+			}
+			continue
+		}
+
+		// sync指令标志一帧结束，按与上一帧的时间差等待后发送
+		instruction, err := Parse(ins)
+		if err == nil && len(instruction.Args) > 0 {
+			if timestamp, err := strconv.ParseInt(instruction.Args[0], 10, 64); err == nil {
+				if lastTimestamp >= 0 && timestamp > lastTimestamp {
+					delay := time.Duration(float64(timestamp-lastTimestamp)/speed) * time.Millisecond
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(delay):
+					}
+				}
+				lastTimestamp = timestamp
+			}
+		}
+		if err := send(buf.Bytes()); err != nil {
+			return err
+		}
+		buf.Reset()
+	}
+
+	if buf.Len() > 0 {
+		return send(buf.Bytes())
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package guac
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplay(t *testing.T) {
+	rec := "4.size,1.0,3.800,3.600;4.sync,13.1700000000000;" +
+		"4.name,4.远程桌面;3.img,1.1;4.sync,13.1700000000100;" +
+		"3.end,1.1;"
+
+	var frames []string
+	err := Replay(context.Background(), strings.NewReader(rec), 2, func(data []byte) error {
+		frames = append(frames, string(data))
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"4.size,1.0,3.800,3.600;4.sync,13.1700000000000;",
+		"4.name,4.远程桌面;3.img,1.1;4.sync,13.1700000000100;",
+		"3.end,1.1;",
+	}, frames)
+
+	err = Replay(context.Background(), strings.NewReader("4.sync,13.170"), 1, func(data []byte) error { return nil })
+	assert.NotNil(t, err)
+}
@@ -1,11 +1,14 @@
 package cache
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"mayfly-go/internal/machine/mcm"
 	global_cache "mayfly-go/pkg/cache"
+	"mayfly-go/pkg/rediscli"
 	"mayfly-go/pkg/utils/jsonx"
+	"sync"
 	"time"
 )
 
@@ -22,3 +25,61 @@ func GetMachineStats(machineId uint64) (*mcm.Stats, error) {
 	}
 	return jsonx.To(cacheStr, new(mcm.Stats))
 }
+
+const MachineAlertStateCacheKey = "mayfly:machine:alert:%d:%d"
+
+// 机器告警规则的触发状态
+type MachineAlertState struct {
+	FirstTime time.Time `json:"firstTime"` // 指标首次超出阈值的时间
+	Alerted   bool      `json:"alerted"`   // 是否已发送告警
+}
+
+func SaveMachineAlertState(ruleId, machineId uint64, state *MachineAlertState, expire time.Duration) error {
+	return global_cache.SetStr(fmt.Sprintf(MachineAlertStateCacheKey, ruleId, machineId), jsonx.ToStr(state), expire)
+}
+
+func GetMachineAlertState(ruleId, machineId uint64) *MachineAlertState {
+	cacheStr := global_cache.GetStr(fmt.Sprintf(MachineAlertStateCacheKey, ruleId, machineId))
+	if cacheStr == "" {
+		return nil
+	}
+	state, err := jsonx.To(cacheStr, new(MachineAlertState))
+	if err != nil {
+		return nil
+	}
+	return state
+}
+
+func DelMachineAlertState(ruleId, machineId uint64) {
+	global_cache.Del(fmt.Sprintf(MachineAlertStateCacheKey, ruleId, machineId))
+}
+
+const MachineCronJobFailsCacheKey = "mayfly:machine:cronjob:fails:%d:%d"
+
+const machineCronJobFailsExpire = 30 * 24 * time.Hour
+
+// 未配置redis时，保证连续失败次数的读取与更新为原子操作
+var machineCronJobFailsMu sync.Mutex
+
+// IncrMachineCronJobFails 原子递增计划任务在机器上的连续失败次数，返回递增后的次数
+func IncrMachineCronJobFails(cronJobId, machineId uint64) (int, error) {
+	key := fmt.Sprintf(MachineCronJobFailsCacheKey, cronJobId, machineId)
+	if !global_cache.UseRedisCache() {
+		machineCronJobFailsMu.Lock()
+		defer machineCronJobFailsMu.Unlock()
+		fails := global_cache.GetInt(key) + 1
+		return fails, global_cache.SetStr(key, fmt.Sprintf("%d", fails), machineCronJobFailsExpire)
+	}
+
+	pipe := rediscli.GetCli().TxPipeline()
+	incr := pipe.Incr(context.Background(), key)
+	pipe.Expire(context.Background(), key, machineCronJobFailsExpire)
+	if _, err := pipe.Exec(context.Background()); err != nil {
+		return 0, err
+	}
+	return int(incr.Val()), nil
+}
+
+func DelMachineCronJobFails(cronJobId, machineId uint64) {
+	global_cache.Del(fmt.Sprintf(MachineCronJobFailsCacheKey, cronJobId, machineId))
+}
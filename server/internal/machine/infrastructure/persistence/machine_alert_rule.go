@@ -0,0 +1,15 @@
+package persistence
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/pkg/base"
+)
+
+type machineAlertRuleRepoImpl struct {
+	base.RepoImpl[*entity.MachineAlertRule]
+}
+
+func newMachineAlertRuleRepo() repository.MachineAlertRule {
+	return &machineAlertRuleRepoImpl{base.RepoImpl[*entity.MachineAlertRule]{M: new(entity.MachineAlertRule)}}
+}
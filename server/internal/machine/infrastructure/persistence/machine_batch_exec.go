@@ -0,0 +1,30 @@
+package persistence
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type machineBatchExecRepoImpl struct {
+	base.RepoImpl[*entity.MachineBatchExec]
+}
+
+func newMachineBatchExecRepo() repository.MachineBatchExec {
+	return &machineBatchExecRepoImpl{base.RepoImpl[*entity.MachineBatchExec]{M: new(entity.MachineBatchExec)}}
+}
+
+// 分页获取批量执行任务列表
+func (m *machineBatchExecRepoImpl) GetPageList(condition *entity.MachineBatchExec, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
+	qd := model.NewCond().Like("name", condition.Name).Eq("status", condition.Status).Eq("creator_id", condition.CreatorId).OrderBy(orderBy...)
+	return m.PageByCondToAny(qd, pageParam, toEntity)
+}
+
+type machineBatchExecResRepoImpl struct {
+	base.RepoImpl[*entity.MachineBatchExecRes]
+}
+
+func newMachineBatchExecResRepo() repository.MachineBatchExecRes {
+	return &machineBatchExecResRepoImpl{base.RepoImpl[*entity.MachineBatchExecRes]{M: new(entity.MachineBatchExecRes)}}
+}
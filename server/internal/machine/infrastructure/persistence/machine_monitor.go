@@ -0,0 +1,30 @@
+package persistence
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+	"time"
+)
+
+type machineMonitorRepoImpl struct {
+	base.RepoImpl[*entity.MachineMonitor]
+}
+
+func newMachineMonitorRepo() repository.MachineMonitor {
+	return &machineMonitorRepoImpl{base.RepoImpl[*entity.MachineMonitor]{M: new(entity.MachineMonitor)}}
+}
+
+func (m *machineMonitorRepoImpl) SelectByQuery(cond *entity.MachineMonitorQuery) ([]*entity.MachineMonitor, error) {
+	qd := model.NewCond().Eq("machine_id", cond.MachineId).
+		Ge("create_time", cond.StartTime).
+		Le("create_time", cond.EndTime).
+		OrderByAsc("create_time")
+	return m.SelectByCond(qd)
+}
+
+func (m *machineMonitorRepoImpl) DeleteBefore(time time.Time) error {
+	// 监控数据量较大，直接物理删除
+	return m.ExecBySql("DELETE FROM t_machine_monitor WHERE create_time < ?", time)
+}
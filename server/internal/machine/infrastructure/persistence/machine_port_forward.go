@@ -0,0 +1,26 @@
+package persistence
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type machinePortForwardRepoImpl struct {
+	base.RepoImpl[*entity.MachinePortForward]
+}
+
+func newMachinePortForwardRepo() repository.MachinePortForward {
+	return &machinePortForwardRepoImpl{base.RepoImpl[*entity.MachinePortForward]{M: new(entity.MachinePortForward)}}
+}
+
+func (m *machinePortForwardRepoImpl) GetPageList(condition *entity.MachinePortForwardQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
+	qd := model.NewCond().
+		Eq("machine_id", condition.MachineId).
+		Eq("status", condition.Status).
+		Eq("creator", condition.Creator).
+		Eq("creator_id", condition.CreatorId).
+		OrderBy(orderBy...)
+	return m.PageByCondToAny(qd, pageParam, toEntity)
+}
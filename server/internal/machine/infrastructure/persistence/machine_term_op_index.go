@@ -0,0 +1,39 @@
+package persistence
+
+import (
+	"context"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/global"
+	"mayfly-go/pkg/model"
+)
+
+type machineTermOpIndexRepoImpl struct {
+	base.RepoImpl[*entity.MachineTermOpIndex]
+}
+
+func newMachineTermOpIndexRepo() repository.MachineTermOpIndex {
+	return &machineTermOpIndexRepoImpl{base.RepoImpl[*entity.MachineTermOpIndex]{M: new(entity.MachineTermOpIndex)}}
+}
+
+func (m *machineTermOpIndexRepoImpl) GetPageList(condition *entity.MachineTermOpIndexQuery, pageParam *model.PageParam, toEntity any) (*model.PageResult[any], error) {
+	qd := model.NewCond().Like("content", condition.Keyword).
+		Eq("machine_id", condition.MachineId).
+		Eq("creator", condition.Creator).
+		Eq("type", condition.Type).
+		Ge("op_time", condition.StartTime).
+		Le("op_time", condition.EndTime).
+		OrderByDesc("op_time")
+	return m.PageByCondToAny(qd, pageParam, toEntity)
+}
+
+func (m *machineTermOpIndexRepoImpl) DeleteByTermOpId(ctx context.Context, termOpId uint64) error {
+	db := contextx.GetDb(ctx)
+	if db == nil {
+		db = global.Db
+	}
+	// 索引数据量较大，直接物理删除
+	return db.Exec("DELETE FROM t_machine_term_op_index WHERE term_op_id = ?", termOpId).Error
+}
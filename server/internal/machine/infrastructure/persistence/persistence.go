@@ -10,6 +10,12 @@ func InitIoc() {
 	ioc.Register(newMachineScriptRepo(), ioc.WithComponentName("MachineScriptRepo"))
 	ioc.Register(newMachineCronJobRepo(), ioc.WithComponentName("MachineCronJobRepo"))
 	ioc.Register(newMachineCronJobExecRepo(), ioc.WithComponentName("MachineCronJobExecRepo"))
+	ioc.Register(newMachineBatchExecRepo(), ioc.WithComponentName("MachineBatchExecRepo"))
+	ioc.Register(newMachineBatchExecResRepo(), ioc.WithComponentName("MachineBatchExecResRepo"))
 	ioc.Register(newMachineTermOpRepoImpl(), ioc.WithComponentName("MachineTermOpRepo"))
+	ioc.Register(newMachineTermOpIndexRepo(), ioc.WithComponentName("MachineTermOpIndexRepo"))
 	ioc.Register(newMachineCmdConfRepo(), ioc.WithComponentName("MachineCmdConfRepo"))
+	ioc.Register(newMachineMonitorRepo(), ioc.WithComponentName("MachineMonitorRepo"))
+	ioc.Register(newMachineAlertRuleRepo(), ioc.WithComponentName("MachineAlertRuleRepo"))
+	ioc.Register(newMachinePortForwardRepo(), ioc.WithComponentName("MachinePortForwardRepo"))
 }
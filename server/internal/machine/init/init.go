@@ -28,6 +28,12 @@ func Init() {
 
 	application.GetMachineTermOpApp().TimerDeleteTermOp()
 
+	application.GetMachineMonitorApp().TimerDeleteMonitor()
+
+	application.GetMachinePortForwardApp().InitPortForward()
+
+	application.GetMachineBatchExecApp().InitJob()
+
 	global.EventBus.Subscribe(event.EventTopicDeleteMachine, "machineFile", func(ctx context.Context, event *eventbus.Event) error {
 		me := event.Val.(*entity.Machine)
 		return application.GetMachineFileApp().DeleteByCond(ctx, &entity.MachineFile{MachineId: me.Id})
@@ -37,4 +43,15 @@ func Init() {
 		me := event.Val.(*entity.Machine)
 		return application.GetMachineScriptApp().DeleteByCond(ctx, &entity.MachineScript{MachineId: me.Id})
 	})
+
+	global.EventBus.Subscribe(event.EventTopicDeleteMachine, "machineMonitor", func(ctx context.Context, event *eventbus.Event) error {
+		me := event.Val.(*entity.Machine)
+		return application.GetMachineMonitorApp().DeleteByCond(ctx, &entity.MachineMonitor{MachineId: me.Id})
+	})
+
+	global.EventBus.Subscribe(event.EventTopicDeleteMachine, "machinePortForward", func(ctx context.Context, event *eventbus.Event) error {
+		me := event.Val.(*entity.Machine)
+		application.GetMachinePortForwardApp().CloseByMachineId(me.Id)
+		return nil
+	})
 }
@@ -1,8 +1,11 @@
 package mcm
 
 import (
+	"context"
+	"io"
 	"mayfly-go/pkg/errorx"
 	"mayfly-go/pkg/logx"
+	"net"
 	"strings"
 
 	"github.com/may-fly/cast"
@@ -50,6 +53,14 @@ func (c *Cli) GetSession() (*ssh.Session, error) {
 	return session, nil
 }
 
+// Dial 通过ssh连接访问机器所在网络中的地址
+func (c *Cli) Dial(network, addr string) (net.Conn, error) {
+	if c.sshClient == nil {
+		return nil, errorx.NewBiz("请先进行机器客户端连接")
+	}
+	return c.sshClient.Dial(network, addr)
+}
+
 // Run 执行shell
 // @param shell shell脚本命令
 // @return 返回执行成功或错误的消息
@@ -67,6 +78,34 @@ func (c *Cli) Run(shell string) (string, error) {
 	return string(buf), nil
 }
 
+// RunWithContext 执行shell并将标准输出及错误输出实时写入output，ctx结束时终止执行
+func (c *Cli) RunWithContext(ctx context.Context, shell string, output io.Writer) error {
+	session, err := c.GetSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	session.Stdout = output
+	session.Stderr = output
+	if err := session.Start(strings.ReplaceAll(shell, "\r\n", "\n")); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		session.Signal(ssh.SIGKILL)
+		return ctx.Err()
+	}
+}
+
 // Close 关闭client并从缓存中移除，如果使用隧道则也关闭
 func (c *Cli) Close() {
 	m := c.Info
@@ -0,0 +1,254 @@
+package mcm
+
+import (
+	"fmt"
+	"io"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/utils/netx"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 端口转发关闭原因
+const (
+	PortForwardCloseManual  = "manual"  // 手动关闭
+	PortForwardCloseExpired = "expired" // 到期自动关闭
+	PortForwardCloseError   = "error"   // 监听异常
+)
+
+// 当前活跃的端口转发会话，id -> *PortForward
+var portForwards sync.Map
+
+// PortForwardInfo 端口转发会话信息
+type PortForwardInfo struct {
+	ID          uint64    `json:"id"` // 对应审计记录id
+	MachineId   uint64    `json:"machineId"`
+	MachineName string    `json:"machineName"`
+	AccountId   uint64    `json:"accountId"`
+	Username    string    `json:"username"`
+	BindAddr    string    `json:"bindAddr"`   // mayfly-go服务端监听的地址
+	LocalPort   int       `json:"localPort"`  // mayfly-go服务端监听的端口
+	ClientIp    string    `json:"clientIp"`   // 开启端口转发的客户端ip，只接受该ip的连接
+	RemoteHost  string    `json:"remoteHost"` // 机器所在网络中的目标地址
+	RemotePort  int       `json:"remotePort"`
+	StartTime   time.Time `json:"startTime"`
+	ExpireTime  time.Time `json:"expireTime"`
+	ConnCount   int64     `json:"connCount"` // 累计连接数
+	BytesIn     int64     `json:"bytesIn"`   // 客户端发送至目标地址的字节数
+	BytesOut    int64     `json:"bytesOut"`  // 目标地址返回至客户端的字节数
+}
+
+// PortForward 端口转发会话，将mayfly-go服务端监听端口的连接通过机器ssh连接转发至目标地址，到期后自动关闭
+type PortForward struct {
+	Info *PortForwardInfo
+
+	cli      *Cli // 端口转发独占的机器客户端，会话关闭时一并关闭
+	listener net.Listener
+	timer    *time.Timer
+	onClose  func(pf *PortForward, reason string)
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+
+	connCount atomic.Int64
+	bytesIn   atomic.Int64
+	bytesOut  atomic.Int64
+}
+
+// NewPortForward 创建端口转发会话并监听端口，info.LocalPort为0则随机选取可用端口，info.BindAddr为空则只监听本地回环地址
+func NewPortForward(info *PortForwardInfo, cli *Cli, onClose func(pf *PortForward, reason string)) (*PortForward, error) {
+	if info.BindAddr == "" {
+		info.BindAddr = "127.0.0.1"
+	}
+	if info.LocalPort == 0 {
+		port, err := netx.GetAvailablePort()
+		if err != nil {
+			return nil, err
+		}
+		info.LocalPort = port
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(info.BindAddr, fmt.Sprintf("%d", info.LocalPort)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &PortForward{
+		Info:     info,
+		cli:      cli,
+		listener: listener,
+		onClose:  onClose,
+		conns:    make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// Start 开始接受连接并转发，需在Info.ID赋值后调用
+func (pf *PortForward) Start() {
+	portForwards.Store(pf.Info.ID, pf)
+	pf.timer = time.AfterFunc(time.Until(pf.Info.ExpireTime), func() {
+		pf.Close(PortForwardCloseExpired)
+	})
+	go pf.serve()
+}
+
+// Close 关闭端口转发会话，断开所有连接
+func (pf *PortForward) Close(reason string) {
+	pf.mu.Lock()
+	if pf.closed {
+		pf.mu.Unlock()
+		return
+	}
+	pf.closed = true
+	if pf.timer != nil {
+		pf.timer.Stop()
+	}
+	_ = pf.listener.Close()
+	for conn := range pf.conns {
+		_ = conn.Close()
+	}
+	pf.conns = nil
+	pf.mu.Unlock()
+
+	pf.cli.Close()
+	portForwards.Delete(pf.Info.ID)
+	logx.Infof("端口转发[%d] %d -> %s 已关闭: %s", pf.Info.ID, pf.Info.LocalPort, pf.remoteAddr(), reason)
+
+	if pf.onClose != nil {
+		pf.onClose(pf, reason)
+	}
+}
+
+// GetInfo 获取端口转发会话信息及当前流量统计
+func (pf *PortForward) GetInfo() *PortForwardInfo {
+	info := *pf.Info
+	info.ConnCount = pf.connCount.Load()
+	info.BytesIn = pf.bytesIn.Load()
+	info.BytesOut = pf.bytesOut.Load()
+	return &info
+}
+
+func (pf *PortForward) serve() {
+	for {
+		localConn, err := pf.listener.Accept()
+		if err != nil {
+			pf.mu.Lock()
+			closed := pf.closed
+			pf.mu.Unlock()
+			if !closed {
+				logx.Errorf("端口转发[%d]接受连接失败: %s", pf.Info.ID, err.Error())
+				pf.Close(PortForwardCloseError)
+			}
+			return
+		}
+		go pf.forward(localConn)
+	}
+}
+
+func (pf *PortForward) forward(localConn net.Conn) {
+	if !pf.allowConn(localConn) {
+		logx.Warnf("端口转发[%d]拒绝非开启者客户端[%s]的连接", pf.Info.ID, localConn.RemoteAddr().String())
+		_ = localConn.Close()
+		return
+	}
+
+	remoteAddr := pf.remoteAddr()
+	remoteConn, err := pf.cli.Dial("tcp", remoteAddr)
+	if err != nil {
+		// 单个连接失败不影响会话，关闭该客户端连接即可
+		logx.Warnf("端口转发[%d]连接目标地址[%s]失败: %s", pf.Info.ID, remoteAddr, err.Error())
+		_ = localConn.Close()
+		return
+	}
+
+	if !pf.trackConn(localConn, remoteConn) {
+		_ = localConn.Close()
+		_ = remoteConn.Close()
+		return
+	}
+	pf.connCount.Add(1)
+	logx.Debugf("端口转发[%d]新增连接 %s -> %s", pf.Info.ID, localConn.RemoteAddr().String(), remoteAddr)
+
+	done := make(chan struct{}, 2)
+	go func() {
+		n, _ := io.Copy(remoteConn, localConn)
+		pf.bytesIn.Add(n)
+		done <- struct{}{}
+	}()
+	go func() {
+		n, _ := io.Copy(localConn, remoteConn)
+		pf.bytesOut.Add(n)
+		done <- struct{}{}
+	}()
+	// 任意一端断开则关闭两端连接
+	<-done
+	_ = localConn.Close()
+	_ = remoteConn.Close()
+	<-done
+
+	pf.untrackConn(localConn, remoteConn)
+}
+
+// 只允许开启端口转发的客户端ip连接，避免其他可访问服务端的主机借此访问机器所在网络
+func (pf *PortForward) allowConn(conn net.Conn) bool {
+	if pf.Info.ClientIp == "" {
+		return true
+	}
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return false
+	}
+	connIp, clientIp := net.ParseIP(host), net.ParseIP(pf.Info.ClientIp)
+	return connIp != nil && clientIp != nil && connIp.Equal(clientIp)
+}
+
+func (pf *PortForward) trackConn(conns ...net.Conn) bool {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	if pf.closed {
+		return false
+	}
+	for _, conn := range conns {
+		pf.conns[conn] = struct{}{}
+	}
+	return true
+}
+
+func (pf *PortForward) untrackConn(conns ...net.Conn) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	for _, conn := range conns {
+		delete(pf.conns, conn)
+	}
+}
+
+func (pf *PortForward) remoteAddr() string {
+	return net.JoinHostPort(pf.Info.RemoteHost, fmt.Sprintf("%d", pf.Info.RemotePort))
+}
+
+// GetPortForward 获取活跃的端口转发会话，不存在则返回nil
+func GetPortForward(id uint64) *PortForward {
+	if pf, ok := portForwards.Load(id); ok {
+		return pf.(*PortForward)
+	}
+	return nil
+}
+
+// GetPortForwards 获取所有满足过滤条件的活跃端口转发会话，按开始时间倒序
+func GetPortForwards(filter func(pf *PortForward) bool) []*PortForward {
+	pfs := make([]*PortForward, 0)
+	portForwards.Range(func(key, value any) bool {
+		pf := value.(*PortForward)
+		if filter == nil || filter(pf) {
+			pfs = append(pfs, pf)
+		}
+		return true
+	})
+	sort.Slice(pfs, func(i, j int) bool {
+		return pfs[i].Info.StartTime.After(pfs[j].Info.StartTime)
+	})
+	return pfs
+}
@@ -0,0 +1,85 @@
+package mcm
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// 单行输出内容最大长度，超出部分截断
+const maxRecLineLen = 500
+
+// 终端控制序列，如颜色、光标移动、窗口标题等
+var ansiEscapeRegexp = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[()][0-9A-Za-z]|\x1b[=>@-Z\\-_]`)
+
+// RecOutputLine 回放文件中的一行终端输出
+type RecOutputLine struct {
+	Offset float64 // 该行输出开始时距录制开始的秒数
+	Line   string  // 去除控制序列后的行内容
+}
+
+// ReadRecOutputLines 读取asciinema v2格式的回放文件，按行解析终端输出内容
+func ReadRecOutputLines(r io.Reader, fn func(line *RecOutputLine) error) error {
+	reader := bufio.NewReader(r)
+	// 跳过头信息
+	if _, err := reader.ReadBytes('\n'); err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+
+	var current strings.Builder
+	var currentOffset float64
+	emit := func() error {
+		line := strings.TrimSpace(current.String())
+		current.Reset()
+		if line == "" {
+			return nil
+		}
+		if utf8.RuneCountInString(line) > maxRecLineLen {
+			line = string([]rune(line)[:maxRecLineLen])
+		}
+		return fn(&RecOutputLine{Offset: currentOffset, Line: line})
+	}
+
+	for {
+		data, err := reader.ReadBytes('\n')
+		if len(data) > 0 {
+			var event []any
+			// 录制异常中断时最后一行可能不完整，忽略无法解析的行
+			if json.Unmarshal(data, &event) == nil && len(event) == 3 && event[1] == string(OutPutType) {
+				offset, _ := event[0].(float64)
+				output, _ := event[2].(string)
+				for _, c := range ansiEscapeRegexp.ReplaceAllString(output, "") {
+					switch {
+					case c == '\n':
+						if err := emit(); err != nil {
+							return err
+						}
+					case c == '\b':
+						if s := current.String(); s != "" {
+							_, size := utf8.DecodeLastRuneInString(s)
+							current.Reset()
+							current.WriteString(s[:len(s)-size])
+						}
+					case c == '\t' || c >= 0x20 && c != 0x7f:
+						if current.Len() == 0 {
+							currentOffset = offset
+						}
+						current.WriteRune(c)
+					}
+				}
+			}
+		}
+		if err != nil {
+			if err == io.EOF {
+				return emit()
+			}
+			return err
+		}
+	}
+}
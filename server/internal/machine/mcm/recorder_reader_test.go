@@ -0,0 +1,30 @@
+package mcm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadRecOutputLines(t *testing.T) {
+	rec := `{"version":2,"width":80,"height":24,"timestamp":1700000000}
+[0.5,"o","\u001b[01;32mroot@prod\u001b[00m:~# "]
+[1.2,"i","s"]
+[1.2,"o","systemctl stop nginz\bx"]
+[2.1,"o","\r\n"]
+[3.4,"o","Stopped nginx.\r\n\r\nroot@prod:~# "]
+`
+	var lines []*RecOutputLine
+	err := ReadRecOutputLines(strings.NewReader(rec), func(line *RecOutputLine) error {
+		lines = append(lines, line)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "root@prod:~# systemctl stop nginx", lines[0].Line)
+	assert.Equal(t, 0.5, lines[0].Offset)
+	assert.Equal(t, "Stopped nginx.", lines[1].Line)
+	assert.Equal(t, 3.4, lines[1].Offset)
+	assert.Equal(t, "root@prod:~#", lines[2].Line)
+}
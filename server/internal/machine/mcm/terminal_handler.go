@@ -2,6 +2,8 @@ package mcm
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"mayfly-go/pkg/errorx"
 	"strings"
@@ -10,8 +12,28 @@ import (
 	"github.com/veops/go-ansiterm"
 )
 
-// 命令过滤函数，若返回error，则不执行该命令
-type CmdFilterFunc func(cmd string) error
+// 命令过滤函数，username为输入该命令的用户，若返回error，则不执行该命令。
+// 返回*CmdWarning则提示后继续执行，返回*CmdHold则挂起命令直至等待结束
+type CmdFilterFunc func(username string, cmd string) error
+
+// CmdWarning 命令警告，提示警告信息后继续执行命令
+type CmdWarning struct {
+	Msg string
+}
+
+func (cw *CmdWarning) Error() string {
+	return cw.Msg
+}
+
+// CmdHold 挂起命令，Wait返回nil则继续执行命令，否则丢弃该命令
+type CmdHold struct {
+	Msg  string // 挂起时的提示信息
+	Wait func(ctx context.Context) error
+}
+
+func (ch *CmdHold) Error() string {
+	return ch.Msg
+}
 
 const (
 	CR  = 0x0d // 单个字节 13 通常表示发送一个 CR（Carriage Return，回车）字符 \r
@@ -31,8 +53,8 @@ type TerminalHandler struct {
 	Parser *Parser
 }
 
-// PreWriteHandle 写入数据至终端前的处理，可进行过滤等操作
-func (tf *TerminalHandler) PreWriteHandle(p []byte) error {
+// PreWriteHandle 写入数据至终端前的处理，可进行过滤等操作，username为输入数据的用户
+func (tf *TerminalHandler) PreWriteHandle(p []byte, username string) error {
 	tf.Parser.AppendInputData(p)
 
 	// 不是回车命令，则表示命令未结束
@@ -50,19 +72,49 @@ func (tf *TerminalHandler) PreWriteHandle(p []byte) error {
 	}
 
 	// 执行命令过滤器
+	var warnings []string
 	for _, filter := range tf.Filters {
-		if err := filter(command); err != nil {
-			msg := fmt.Sprintf("\r\n%s%s", tf.Parser.Ps1, GetErrorContent(err.Error()))
-			return errorx.NewBiz(msg)
+		err := filter(username, command)
+		if err == nil {
+			continue
+		}
+
+		var warning *CmdWarning
+		if errors.As(err, &warning) {
+			warnings = append(warnings, warning.Msg)
+			continue
 		}
+
+		var hold *CmdHold
+		if errors.As(err, &hold) {
+			// 等待结束且允许执行后再记录命令
+			wait := hold.Wait
+			return &CmdHold{Msg: hold.Msg, Wait: func(ctx context.Context) error {
+				if err := wait(ctx); err != nil {
+					return err
+				}
+				tf.recordCmd(command)
+				return nil
+			}}
+		}
+
+		msg := fmt.Sprintf("\r\n%s%s", tf.Parser.Ps1, GetErrorContent(err.Error()))
+		return errorx.NewBiz(msg)
+	}
+
+	tf.recordCmd(command)
+	if len(warnings) > 0 {
+		return &CmdWarning{Msg: strings.Join(warnings, "\r\n")}
 	}
+	return nil
+}
 
-	// 记录执行命令
+// 记录执行命令
+func (tf *TerminalHandler) recordCmd(command string) {
 	tf.ExecutedCmds = append(tf.ExecutedCmds, &ExecutedCmd{
 		Cmd:  command,
 		Time: time.Now().Unix(),
 	})
-	return nil
 }
 
 // HandleRead 处理从终端读取的数据进行操作
@@ -213,3 +265,8 @@ func GetErrorContent(msg string) string {
 func GetErrorContentRn(msg string) string {
 	return fmt.Sprintf("\r\n%s", GetErrorContent(msg))
 }
+
+// GetWarnContentRn 包装返回终端提示消息, 并自动回车换行
+func GetWarnContentRn(msg string) string {
+	return fmt.Sprintf("\r\n\033[1;33m%s\033[0m\r\n", msg)
+}
@@ -2,6 +2,7 @@ package mcm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mayfly-go/pkg/errorx"
@@ -10,6 +11,8 @@ import (
 	"github.com/may-fly/cast"
 
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 
@@ -26,7 +29,8 @@ const (
 
 type TerminalSession struct {
 	ID       string
-	wsConn   *websocket.Conn
+	Info     *TerminalSessionInfo
+	wsConn   *TerminalWsConn
 	terminal *Terminal
 	handler  *TerminalHandler
 	recorder *Recorder
@@ -34,6 +38,63 @@ type TerminalSession struct {
 	cancel   context.CancelFunc
 	dataChan chan rune
 	tick     *time.Ticker
+	stopOnce sync.Once
+	inputMu  sync.Mutex  // 会话拥有者与协作者的输入需串行写入终端
+	holding  atomic.Bool // 是否存在挂起等待中的命令，等待期间丢弃输入
+	// 取消挂起等待中的命令，受inputMu保护
+	holdCancel context.CancelFunc
+
+	mu          sync.RWMutex
+	attachments map[string]*terminalAttachment // 附加至该会话的其他连接
+	invitees    map[uint64]string              // 会话拥有者邀请的协作者，accountId -> username
+}
+
+// 终端会话信息
+type TerminalSessionInfo struct {
+	SessionId   string    `json:"sessionId"`
+	MachineId   uint64    `json:"machineId"`
+	MachineCode string    `json:"machineCode"`
+	MachineName string    `json:"machineName"`
+	Ip          string    `json:"ip"`
+	Target      string    `json:"target"`    // 终端目标，为空则为机器shell，否则为进入的容器
+	AccountId   uint64    `json:"accountId"` // 会话拥有者
+	Username    string    `json:"username"`
+	StartTime   time.Time `json:"startTime"`
+	CodePath    []string  `json:"-"` // 机器关联的标签路径，用于校验协作者及监控者的访问权限
+
+	Attachments []*TerminalAttachmentInfo `json:"attachments"`
+	Invitees    []string                  `json:"invitees"`
+}
+
+// 附加至终端会话的连接信息
+type TerminalAttachmentInfo struct {
+	Id         string    `json:"id"`
+	AccountId  uint64    `json:"accountId"`
+	Username   string    `json:"username"`
+	Writable   bool      `json:"writable"` // 是否可写入终端
+	AttachTime time.Time `json:"attachTime"`
+}
+
+type terminalAttachment struct {
+	*TerminalAttachmentInfo
+	wsConn *TerminalWsConn
+}
+
+// TerminalWsConn websocket连接不支持并发写，故加锁写入
+type TerminalWsConn struct {
+	*websocket.Conn
+	mu sync.Mutex
+}
+
+func NewTerminalWsConn(conn *websocket.Conn) *TerminalWsConn {
+	return &TerminalWsConn{Conn: conn}
+}
+
+// WriteText 写入文本消息
+func (c *TerminalWsConn) WriteText(msg string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.WriteMessage(websocket.TextMessage, []byte(msg))
 }
 
 type CreateTerminalSessionParam struct {
@@ -43,8 +104,11 @@ type CreateTerminalSessionParam struct {
 	Rows           int
 	Cols           int
 	Recorder       *Recorder
+	Target         *TerminalTarget // 终端目标，为nil则启动机器shell
 	LogCmd         bool            // 是否记录命令
 	CmdFilterFuncs []CmdFilterFunc // 命令过滤器
+	AccountId      uint64          // 会话拥有者账号id
+	Username       string          // 会话拥有者用户名
 }
 
 func NewTerminalSession(param *CreateTerminalSessionParam) (*TerminalSession, error) {
@@ -59,7 +123,11 @@ func NewTerminalSession(param *CreateTerminalSessionParam) (*TerminalSession, er
 	if err != nil {
 		return nil, err
 	}
-	err = terminal.Shell()
+	if param.Target != nil {
+		err = terminal.Exec(param.Target.Command())
+	} else {
+		err = terminal.Shell()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -76,16 +144,35 @@ func NewTerminalSession(param *CreateTerminalSessionParam) (*TerminalSession, er
 
 	ctx, cancel := context.WithCancel(context.Background())
 	tick := time.NewTicker(time.Millisecond * time.Duration(60))
+	mi := cli.Info
+	var target string
+	if param.Target != nil {
+		target = param.Target.String()
+	}
 	ts := &TerminalSession{
-		ID:       sessionId,
-		wsConn:   ws,
-		terminal: terminal,
-		handler:  handler,
-		recorder: recorder,
-		ctx:      ctx,
-		cancel:   cancel,
-		dataChan: make(chan rune),
-		tick:     tick,
+		ID: sessionId,
+		Info: &TerminalSessionInfo{
+			SessionId:   sessionId,
+			MachineId:   mi.Id,
+			MachineCode: mi.Code,
+			MachineName: mi.Name,
+			Ip:          mi.Ip,
+			Target:      target,
+			AccountId:   param.AccountId,
+			Username:    param.Username,
+			StartTime:   time.Now(),
+			CodePath:    mi.CodePath,
+		},
+		wsConn:      NewTerminalWsConn(ws),
+		terminal:    terminal,
+		handler:     handler,
+		recorder:    recorder,
+		ctx:         ctx,
+		cancel:      cancel,
+		dataChan:    make(chan rune),
+		tick:        tick,
+		attachments: make(map[string]*terminalAttachment),
+		invitees:    make(map[uint64]string),
 	}
 
 	// 清除终端内容
@@ -93,34 +180,153 @@ func NewTerminalSession(param *CreateTerminalSessionParam) (*TerminalSession, er
 	return ts, nil
 }
 
-func (r TerminalSession) Start() {
+func (r *TerminalSession) Start() {
+	registerTerminalSession(r)
 	go r.readFromTerminal()
 	go r.writeToWebsocket()
 	r.receiveWsMsg()
 }
 
-func (r TerminalSession) Stop() {
-	logx.Debug("close machine ssh terminal session")
-	r.tick.Stop()
-	r.cancel()
-	if r.terminal != nil {
-		if err := r.terminal.Close(); err != nil {
-			if err != io.EOF {
-				logx.Errorf("关闭机器ssh终端失败: %s", err.Error())
+func (r *TerminalSession) Stop() {
+	r.stopOnce.Do(func() {
+		logx.Debug("close machine ssh terminal session")
+		removeTerminalSession(r.ID)
+		r.tick.Stop()
+		r.cancel()
+		if r.terminal != nil {
+			if err := r.terminal.Close(); err != nil {
+				if err != io.EOF {
+					logx.Errorf("关闭机器ssh终端失败: %s", err.Error())
+				}
 			}
 		}
+
+		// 关闭所有附加连接，使其结束读取
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, attachment := range r.attachments {
+			attachment.wsConn.Close()
+		}
+		r.attachments = make(map[string]*terminalAttachment)
+	})
+}
+
+// Terminate 强制终止会话，并通知会话拥有者及所有附加的连接
+func (r *TerminalSession) Terminate(notice string) {
+	r.broadcast(GetErrorContentRn(notice))
+	r.Stop()
+	// 关闭拥有者连接，使其结束读取
+	r.wsConn.Close()
+}
+
+// Invite 邀请协作者，被邀请的协作者可附加至会话并写入终端
+func (r *TerminalSession) Invite(accountId uint64, username string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.invitees[accountId] = username
+}
+
+// RemoveInvitee 移除协作者，并断开其已附加的可写连接
+func (r *TerminalSession) RemoveInvitee(accountId uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.invitees, accountId)
+	for id, attachment := range r.attachments {
+		if attachment.AccountId == accountId && attachment.Writable {
+			attachment.wsConn.Close()
+			delete(r.attachments, id)
+		}
 	}
 }
 
+// IsInvitee 判断账号是否为会话协作者
+func (r *TerminalSession) IsInvitee(accountId uint64) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.invitees[accountId]
+	return ok
+}
+
+// Attach 将websocket连接附加至会话，阻塞直至连接断开或会话结束。
+// writable为false时仅可查看终端输出
+func (r *TerminalSession) Attach(wsConn *websocket.Conn, accountId uint64, username string, writable bool) error {
+	if r.ctx.Err() != nil {
+		return errorx.NewBiz("终端会话已结束")
+	}
+
+	attachment := &terminalAttachment{
+		TerminalAttachmentInfo: &TerminalAttachmentInfo{
+			Id:         fmt.Sprintf("%d-%d", accountId, time.Now().UnixNano()),
+			AccountId:  accountId,
+			Username:   username,
+			Writable:   writable,
+			AttachTime: time.Now(),
+		},
+		wsConn: NewTerminalWsConn(wsConn),
+	}
+
+	r.mu.Lock()
+	r.attachments[attachment.Id] = attachment
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.attachments, attachment.Id)
+		r.mu.Unlock()
+	}()
+
+	mode := "只读"
+	if writable {
+		mode = "协作"
+	}
+	r.wsConn.WriteText(GetWarnContentRn(fmt.Sprintf("[%s] 以%s模式加入了终端会话", username, mode)))
+	attachment.wsConn.WriteText(GetWarnContentRn(fmt.Sprintf("已以%s模式加入[%s]的终端会话", mode, r.Info.Username)))
+
+	for {
+		_, wsData, err := attachment.wsConn.ReadMessage()
+		if err != nil {
+			logx.Debugf("附加的终端连接读取websocket消息失败: %s", err.Error())
+			return nil
+		}
+		if r.ctx.Err() != nil {
+			return nil
+		}
+		msgObj, err := parseMsg(wsData)
+		if err != nil {
+			continue
+		}
+		// 终端窗口大小由会话拥有者控制，只处理可写连接的输入数据
+		if msgObj.Type == Data && writable {
+			r.writeToTerminal(msgObj.Msg, username)
+		}
+	}
+}
+
+// GetInfo 获取会话信息及当前附加的连接
+func (r *TerminalSession) GetInfo() *TerminalSessionInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	info := *r.Info
+	info.Attachments = make([]*TerminalAttachmentInfo, 0, len(r.attachments))
+	for _, attachment := range r.attachments {
+		info.Attachments = append(info.Attachments, attachment.TerminalAttachmentInfo)
+	}
+	info.Invitees = make([]string, 0, len(r.invitees))
+	for _, username := range r.invitees {
+		info.Invitees = append(info.Invitees, username)
+	}
+	return &info
+}
+
 // 获取终端会话执行的所有命令
-func (r TerminalSession) GetExecCmds() []*ExecutedCmd {
+func (r *TerminalSession) GetExecCmds() []*ExecutedCmd {
 	if r.handler != nil {
 		return r.handler.ExecutedCmds
 	}
 	return []*ExecutedCmd{}
 }
 
-func (ts TerminalSession) readFromTerminal() {
+func (ts *TerminalSession) readFromTerminal() {
 	for {
 		select {
 		case <-ts.ctx.Done():
@@ -140,7 +346,7 @@ func (ts TerminalSession) readFromTerminal() {
 	}
 }
 
-func (ts TerminalSession) writeToWebsocket() {
+func (ts *TerminalSession) writeToWebsocket() {
 	var buf []byte
 	for {
 		select {
@@ -159,6 +365,7 @@ func (ts TerminalSession) writeToWebsocket() {
 				logx.Error("机器ssh终端发送消息至websocket失败: ", err)
 				return
 			}
+			ts.writeToAttachments(s)
 
 			// 如果记录器存在，则记录操作回放信息
 			if ts.recorder != nil {
@@ -216,21 +423,7 @@ func (ts *TerminalSession) receiveWsMsg() {
 					}
 				}
 			case Data:
-				data := []byte(msgObj.Msg)
-				if ts.handler != nil {
-					if err := ts.handler.PreWriteHandle(data); err != nil {
-						ts.WriteToWs(err.Error())
-						// 发送命令终止指令
-						ts.terminal.Write([]byte{EOT})
-						continue
-					}
-				}
-
-				_, err := ts.terminal.Write([]byte(msgObj.Msg))
-				if err != nil {
-					logx.Errorf("写入数据至ssh终端失败: %s", err)
-					ts.WriteToWs(GetErrorContentRn(fmt.Sprintf("写入数据至ssh终端失败: %s", err.Error())))
-				}
+				ts.writeToTerminal(msgObj.Msg, ts.Info.Username)
 			case Ping:
 				_, err := ts.terminal.SshSession.SendRequest("ping", true, nil)
 				if err != nil {
@@ -242,9 +435,92 @@ func (ts *TerminalSession) receiveWsMsg() {
 	}
 }
 
+// 将输入数据写入终端，会话拥有者与协作者的输入共用同一命令处理器，username为输入数据的用户
+func (ts *TerminalSession) writeToTerminal(msg string, username string) {
+	ts.inputMu.Lock()
+	defer ts.inputMu.Unlock()
+
+	// 挂起等待期间丢弃输入，避免在命令审批结果前执行其他命令，Ctrl+C则取消挂起的命令
+	if ts.holding.Load() {
+		if strings.IndexByte(msg, EOT) >= 0 && ts.holdCancel != nil {
+			ts.holdCancel()
+		}
+		return
+	}
+
+	data := []byte(msg)
+	if ts.handler != nil {
+		if err := ts.handler.PreWriteHandle(data, username); err != nil {
+			var warning *CmdWarning
+			var hold *CmdHold
+			switch {
+			case errors.As(err, &warning):
+				ts.broadcast(GetWarnContentRn(warning.Msg))
+			case errors.As(err, &hold):
+				ts.broadcast(GetWarnContentRn(hold.Msg))
+				// 异步等待，避免阻塞websocket消息的读取
+				holdCtx, cancel := context.WithCancel(ts.ctx)
+				ts.holdCancel = cancel
+				ts.holding.Store(true)
+				go ts.waitCmdHold(holdCtx, hold, data)
+				return
+			default:
+				ts.broadcast(err.Error())
+				// 发送命令终止指令
+				ts.terminal.Write([]byte{EOT})
+				return
+			}
+		}
+	}
+
+	ts.writeData(data)
+}
+
+// 等待挂起的命令，等待通过则写入终端，否则终止该命令
+func (ts *TerminalSession) waitCmdHold(ctx context.Context, hold *CmdHold, data []byte) {
+	defer ts.holding.Store(false)
+	err := hold.Wait(ctx)
+
+	ts.inputMu.Lock()
+	defer ts.inputMu.Unlock()
+	ts.holdCancel()
+	ts.holdCancel = nil
+	if err != nil {
+		ts.broadcast(GetErrorContentRn(err.Error()))
+		ts.terminal.Write([]byte{EOT})
+		return
+	}
+	ts.writeData(data)
+}
+
+func (ts *TerminalSession) writeData(data []byte) {
+	_, err := ts.terminal.Write(data)
+	if err != nil {
+		logx.Errorf("写入数据至ssh终端失败: %s", err)
+		ts.WriteToWs(GetErrorContentRn(fmt.Sprintf("写入数据至ssh终端失败: %s", err.Error())))
+	}
+}
+
 // WriteToWs 将消息写入websocket连接
 func (ts *TerminalSession) WriteToWs(msg string) error {
-	return ts.wsConn.WriteMessage(websocket.TextMessage, []byte(msg))
+	return ts.wsConn.WriteText(msg)
+}
+
+// 将终端输出写入所有附加的连接
+func (ts *TerminalSession) writeToAttachments(msg string) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	for _, attachment := range ts.attachments {
+		if err := attachment.wsConn.WriteText(msg); err != nil {
+			logx.Debugf("终端输出发送至附加连接[%s]失败: %s", attachment.Username, err.Error())
+		}
+	}
+}
+
+// 将消息写入会话拥有者及所有附加的连接
+func (ts *TerminalSession) broadcast(msg string) {
+	ts.WriteToWs(msg)
+	ts.writeToAttachments(msg)
 }
 
 // 解析消息
@@ -0,0 +1,41 @@
+package mcm
+
+import (
+	"sort"
+	"sync"
+)
+
+// 当前活跃的终端会话，sessionId -> *TerminalSession
+var terminalSessions sync.Map
+
+func registerTerminalSession(ts *TerminalSession) {
+	terminalSessions.Store(ts.ID, ts)
+}
+
+func removeTerminalSession(sessionId string) {
+	terminalSessions.Delete(sessionId)
+}
+
+// GetTerminalSession 获取活跃的终端会话，不存在则返回nil
+func GetTerminalSession(sessionId string) *TerminalSession {
+	if ts, ok := terminalSessions.Load(sessionId); ok {
+		return ts.(*TerminalSession)
+	}
+	return nil
+}
+
+// GetTerminalSessions 获取所有满足过滤条件的活跃终端会话，按开始时间倒序
+func GetTerminalSessions(filter func(ts *TerminalSession) bool) []*TerminalSession {
+	sessions := make([]*TerminalSession, 0)
+	terminalSessions.Range(func(key, value any) bool {
+		ts := value.(*TerminalSession)
+		if filter == nil || filter(ts) {
+			sessions = append(sessions, ts)
+		}
+		return true
+	})
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Info.StartTime.After(sessions[j].Info.StartTime)
+	})
+	return sessions
+}
@@ -0,0 +1,91 @@
+package mcm
+
+import (
+	"fmt"
+	"mayfly-go/pkg/errorx"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// 终端目标类型
+const (
+	TerminalTargetDocker  = "docker"  // 通过docker exec进入机器上的容器
+	TerminalTargetKubectl = "kubectl" // 通过kubectl exec进入pod中的容器
+)
+
+var (
+	// 容器、pod及命名空间名称校验，避免拼接命令时注入
+	terminalTargetNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+	// 可在容器内启动的shell
+	terminalTargetShells = []string{"sh", "bash", "ash", "zsh", "/bin/sh", "/bin/bash", "/bin/ash", "/bin/zsh"}
+)
+
+// TerminalTarget 终端会话目标，为nil时为机器ssh shell，否则通过机器ssh连接执行docker exec或kubectl exec进入容器
+type TerminalTarget struct {
+	Type      string `json:"type"`
+	Container string `json:"container"` // docker容器名或id，kubectl时为pod中的容器名(可选)
+	Pod       string `json:"pod"`
+	Namespace string `json:"namespace"`
+	Shell     string `json:"shell"` // 容器内启动的shell，默认/bin/sh
+}
+
+// Validate 校验终端目标参数并填充默认值
+func (t *TerminalTarget) Validate() error {
+	if t.Shell == "" {
+		t.Shell = "/bin/sh"
+	}
+	if !slices.Contains(terminalTargetShells, t.Shell) {
+		return errorx.NewBiz("不支持的shell: %s", t.Shell)
+	}
+
+	switch t.Type {
+	case TerminalTargetDocker:
+		if !terminalTargetNameRegexp.MatchString(t.Container) {
+			return errorx.NewBiz("容器名错误")
+		}
+	case TerminalTargetKubectl:
+		if !terminalTargetNameRegexp.MatchString(t.Pod) {
+			return errorx.NewBiz("pod名错误")
+		}
+		if t.Namespace == "" {
+			t.Namespace = "default"
+		}
+		if !terminalTargetNameRegexp.MatchString(t.Namespace) {
+			return errorx.NewBiz("命名空间错误")
+		}
+		if t.Container != "" && !terminalTargetNameRegexp.MatchString(t.Container) {
+			return errorx.NewBiz("容器名错误")
+		}
+	default:
+		return errorx.NewBiz("不支持的终端目标类型: %s", t.Type)
+	}
+	return nil
+}
+
+// Command 在机器上执行以进入容器的命令，需先调用Validate
+func (t *TerminalTarget) Command() string {
+	if t.Type == TerminalTargetDocker {
+		return fmt.Sprintf("docker exec -it %s %s", t.Container, t.Shell)
+	}
+
+	args := []string{"kubectl", "exec", "-it", "-n", t.Namespace, t.Pod}
+	if t.Container != "" {
+		args = append(args, "-c", t.Container)
+	}
+	args = append(args, "--", t.Shell)
+	return strings.Join(args, " ")
+}
+
+// String 终端目标描述，用于审计记录，如docker:nginx、kubectl:default/web-0/app
+func (t *TerminalTarget) String() string {
+	if t.Type == TerminalTargetDocker {
+		return fmt.Sprintf("%s:%s", t.Type, t.Container)
+	}
+	s := fmt.Sprintf("%s:%s/%s", t.Type, t.Namespace, t.Pod)
+	if t.Container != "" {
+		s += "/" + t.Container
+	}
+	return s
+}
@@ -0,0 +1,22 @@
+package mcm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerminalTargetCommand(t *testing.T) {
+	docker := &TerminalTarget{Type: TerminalTargetDocker, Container: "nginx"}
+	assert.Nil(t, docker.Validate())
+	assert.Equal(t, "docker exec -it nginx /bin/sh", docker.Command())
+	assert.Equal(t, "docker:nginx", docker.String())
+
+	kubectl := &TerminalTarget{Type: TerminalTargetKubectl, Pod: "web-0", Container: "app", Shell: "bash"}
+	assert.Nil(t, kubectl.Validate())
+	assert.Equal(t, "kubectl exec -it -n default web-0 -c app -- bash", kubectl.Command())
+	assert.Equal(t, "kubectl:default/web-0/app", kubectl.String())
+
+	assert.NotNil(t, (&TerminalTarget{Type: TerminalTargetDocker, Container: "nginx;rm -rf /"}).Validate())
+	assert.NotNil(t, (&TerminalTarget{Type: TerminalTargetDocker, Container: "nginx", Shell: "python"}).Validate())
+}
@@ -19,6 +19,8 @@ func InitMachineRouter(router *gin.RouterGroup) {
 	machines := router.Group("machines")
 	{
 		saveMachineP := req.NewPermission("machine:update")
+		termSessionMonitorP := req.NewPermission("machine:terminal:monitor")
+		termRecSearchP := req.NewPermission("machine:termrec:search")
 
 		reqs := [...]*req.Conf{
 			req.NewGet("dashbord", dashbord.Dashbord),
@@ -27,6 +29,9 @@ func InitMachineRouter(router *gin.RouterGroup) {
 
 			req.NewGet(":machineId/stats", m.MachineStats),
 
+			// 获取机器监控历史记录
+			req.NewGet(":machineId/monitors", m.MachineMonitors),
+
 			req.NewGet(":machineId/process", m.GetProcess),
 
 			req.NewGet(":machineId/users", m.GetUsers),
@@ -48,6 +53,30 @@ func InitMachineRouter(router *gin.RouterGroup) {
 
 			// 获取机器终端回放记录
 			req.NewGet(":machineId/term-recs/:recId", m.MachineTermOpRecord).RequiredPermission(saveMachineP),
+
+			// 检索所有机器终端回放记录中执行的命令及终端输出
+			req.NewGet("term-recs/search", m.SearchTermOpRecords).RequiredPermission(termRecSearchP),
+
+			req.NewPost(":machineId/term-recs/:recId/index", m.IndexTermOpRecord).Log(req.NewLogSave("机器-重建终端回放记录索引")).RequiredPermission(termRecSearchP),
+
+			// 获取所有活跃的终端会话
+			req.NewGet("terminal-sessions", m.TermSessions).RequiredPermission(termSessionMonitorP),
+
+			// 获取当前账号拥有或受邀协作的终端会话
+			req.NewGet("terminal-sessions/mine", m.MyTermSessions),
+
+			req.NewPost("terminal-sessions/:sessionId/collaborators", m.InviteTermSessionCollaborator).Log(req.NewLogSave("机器-邀请终端会话协作者")),
+
+			req.NewDelete("terminal-sessions/:sessionId/collaborators/:accountId", m.RemoveTermSessionCollaborator).Log(req.NewLogSave("机器-移除终端会话协作者")),
+
+			req.NewDelete("terminal-sessions/:sessionId", m.TerminateTermSession).Log(req.NewLogSave("机器-强制终止终端会话")).RequiredPermission(termSessionMonitorP),
+
+			// 获取当前账号可审批的待审批终端命令
+			req.NewGet("cmd-approvals", m.PendingCmdApprovals),
+
+			req.NewPost("cmd-approvals/:id/approve", m.ApproveCmd).Log(req.NewLogSave("机器-审批通过终端命令")),
+
+			req.NewPost("cmd-approvals/:id/reject", m.RejectCmd).Log(req.NewLogSave("机器-审批拒绝终端命令")),
 		}
 
 		req.BatchSetGroup(machines, reqs[:])
@@ -57,5 +86,11 @@ func InitMachineRouter(router *gin.RouterGroup) {
 
 		// 终端连接
 		machines.GET("rdp/:ac", m.WsGuacamole)
+
+		// 回放RDP/VNC会话记录
+		machines.GET(":machineId/term-recs/:recId/replay", m.WsReplayGuacTermOpRecord)
+
+		// 附加至终端会话
+		machines.GET("terminal-sessions/:sessionId/attach", m.WsAttachTermSession)
 	}
 }
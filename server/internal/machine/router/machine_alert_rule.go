@@ -0,0 +1,27 @@
+package router
+
+import (
+	"mayfly-go/internal/machine/api"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/ioc"
+	"mayfly-go/pkg/req"
+
+	"github.com/gin-gonic/gin"
+)
+
+func InitMachineAlertRuleRouter(router *gin.RouterGroup) {
+	mars := router.Group("machine/security/alert-rules")
+
+	mar := new(api.MachineAlertRule)
+	biz.ErrIsNil(ioc.Inject(mar))
+
+	reqs := [...]*req.Conf{
+		req.NewGet("", mar.MachineAlertRules),
+
+		req.NewPost("", mar.Save).Log(req.NewLogSave("机器告警规则-保存")).RequiredPermissionCode("alertrule:save"),
+
+		req.NewDelete(":id", mar.Delete).Log(req.NewLogSave("机器告警规则-删除")).RequiredPermissionCode("alertrule:del"),
+	}
+
+	req.BatchSetGroup(mars, reqs[:])
+}
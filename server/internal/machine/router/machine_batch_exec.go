@@ -0,0 +1,29 @@
+package router
+
+import (
+	"mayfly-go/internal/machine/api"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/ioc"
+	"mayfly-go/pkg/req"
+
+	"github.com/gin-gonic/gin"
+)
+
+func InitMachineBatchExecRouter(router *gin.RouterGroup) {
+	batchExecs := router.Group("machine-batch-execs")
+
+	be := new(api.MachineBatchExec)
+	biz.ErrIsNil(ioc.Inject(be))
+
+	reqs := [...]*req.Conf{
+		// 获取批量执行任务列表
+		req.NewGet("", be.BatchExecs),
+
+		// 获取批量执行任务各机器的执行结果
+		req.NewGet(":id/results", be.BatchExecResults),
+
+		req.NewPost("", be.Run).Log(req.NewLogSave("机器-按标签批量执行命令")).RequiredPermissionCode("machine:batchexec:run"),
+	}
+
+	req.BatchSetGroup(batchExecs, reqs[:])
+}
@@ -41,6 +41,17 @@ func InitMachineFileRouter(router *gin.RouterGroup) {
 
 		req.NewPost(":machineId/files/:fileId/upload-folder", mf.UploadFolder).Log(req.NewLogSave("机器-文件夹上传")).RequiredPermissionCode("machine:file:upload"),
 
+		// 获取分片上传已上传的大小，用于断点续传
+		req.NewGet(":machineId/files/:fileId/upload-chunk/offset", mf.ChunkUploadOffset).RequiredPermissionCode("machine:file:upload"),
+
+		req.NewPost(":machineId/files/:fileId/upload-chunk", mf.UploadChunk).RequiredPermissionCode("machine:file:upload"),
+
+		req.NewPost(":machineId/files/:fileId/upload-chunk/complete", mf.CompleteChunkUpload).Log(req.NewLogSave("机器-文件分片上传")).RequiredPermissionCode("machine:file:upload"),
+
+		req.NewGet(":machineId/files/:fileId/download-dir", mf.DownloadDir).NoRes().Log(req.NewLogSave("机器-目录打包下载")),
+
+		req.NewPost(":machineId/files/:fileId/transfer", mf.TransferFile).Log(req.NewLogSave("机器-机器间文件传输")).RequiredPermissionCode("machine:file:upload"),
+
 		req.NewPost(":machineId/files/:fileId/remove", mf.RemoveFile).Log(req.NewLogSave("机器-删除文件or文件夹")).RequiredPermissionCode("machine:file:rm"),
 
 		req.NewPost(":machineId/files/:fileId/cp", mf.CopyFile).Log(req.NewLogSave("机器-拷贝文件")).RequiredPermissionCode("machine:file:rm"),
@@ -0,0 +1,41 @@
+package router
+
+import (
+	"mayfly-go/internal/machine/api"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/ioc"
+	"mayfly-go/pkg/req"
+
+	"github.com/gin-gonic/gin"
+)
+
+func InitMachinePortForwardRouter(router *gin.RouterGroup) {
+	portForwards := router.Group("machine-port-forwards")
+
+	pf := new(api.MachinePortForward)
+	biz.ErrIsNil(ioc.Inject(pf))
+
+	manageP := req.NewPermission("machine:portforward:manage")
+
+	reqs := [...]*req.Conf{
+		// 获取所有端口转发审计记录
+		req.NewGet("", pf.PortForwards).RequiredPermission(manageP),
+
+		// 获取当前账号的端口转发审计记录
+		req.NewGet("mine", pf.MyPortForwards),
+
+		// 获取所有活跃的端口转发会话
+		req.NewGet("active", pf.ActivePortForwards).RequiredPermission(manageP),
+
+		// 获取当前账号活跃的端口转发会话
+		req.NewGet("active/mine", pf.MyActivePortForwards),
+
+		req.NewPost("", pf.OpenPortForward).Log(req.NewLogSave("机器-开启端口转发")).RequiredPermissionCode("machine:portforward"),
+
+		req.NewDelete(":id", pf.ClosePortForward).Log(req.NewLogSave("机器-关闭端口转发")),
+
+		req.NewDelete(":id/terminate", pf.TerminatePortForward).Log(req.NewLogSave("机器-强制关闭端口转发")).RequiredPermission(manageP),
+	}
+
+	req.BatchSetGroup(portForwards, reqs[:])
+}
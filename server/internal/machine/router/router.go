@@ -7,5 +7,8 @@ func Init(router *gin.RouterGroup) {
 	InitMachineFileRouter(router)
 	InitMachineScriptRouter(router)
 	InitMachineCronJobRouter(router)
+	InitMachineBatchExecRouter(router)
 	InitMachineCmdConfRouter(router)
+	InitMachineAlertRuleRouter(router)
+	InitMachinePortForwardRouter(router)
 }
@@ -1,5 +1,7 @@
 package form
 
+import "encoding/json"
+
 type Mongo struct {
 	Id                 uint64   `json:"id"`
 	Code               string   `json:"code" binding:"required"`
@@ -18,6 +20,7 @@ type MongoCommand struct {
 type MongoRunCommand struct {
 	Database string           `binding:"required" json:"database"`
 	Command  []map[string]any `json:"command"`
+	Remark   string           `json:"remark"` // 开启审批流程时的备注
 }
 
 type MongoFindCommand struct {
@@ -31,9 +34,33 @@ type MongoUpdateByIdCommand struct {
 	MongoCommand
 	DocId  any            `binding:"required" json:"docId"`
 	Update map[string]any `json:"update"`
+	Remark string         `json:"remark"`
 }
 
 type MongoInsertCommand struct {
 	MongoCommand
-	Doc map[string]any `json:"doc"`
+	Doc    map[string]any `json:"doc"`
+	Remark string         `json:"remark"`
+}
+
+type MongoAggregateCommand struct {
+	MongoCommand
+	Pipeline json.RawMessage `binding:"required" json:"pipeline"` // 聚合管道，支持扩展json，保留字段顺序
+	Limit    int64           `json:"limit"`
+}
+
+type MongoExplainCommand struct {
+	MongoFindCommand
+	Type      string          `binding:"required" json:"type"` // find、aggregate
+	Pipeline  json.RawMessage `json:"pipeline"`
+	Verbosity string          `json:"verbosity"` // queryPlanner、executionStats、allPlansExecution
+}
+
+type MongoCreateIndex struct {
+	MongoCommand
+	Keys               json.RawMessage `binding:"required" json:"keys"` // 索引字段，如: {"name": 1, "age": -1}
+	Name               string          `json:"name"`
+	Unique             bool            `json:"unique"`
+	Sparse             bool            `json:"sparse"`
+	ExpireAfterSeconds *int32          `json:"expireAfterSeconds"`
 }
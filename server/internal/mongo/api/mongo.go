@@ -7,6 +7,7 @@ import (
 	"mayfly-go/internal/mongo/api/form"
 	"mayfly-go/internal/mongo/api/vo"
 	"mayfly-go/internal/mongo/application"
+	"mayfly-go/internal/mongo/application/dto"
 	"mayfly-go/internal/mongo/domain/entity"
 	tagapp "mayfly-go/internal/tag/application"
 	tagentity "mayfly-go/internal/tag/domain/entity"
@@ -114,26 +115,15 @@ func (m *Mongo) RunCommand(rc *req.Ctx) {
 	biz.ErrIsNil(err)
 	rc.ReqParam = collx.Kvs("mongo", conn.Info, "cmd", commandForm)
 
-	// 顺序执行
-	commands := bson.D{}
-	for _, cmd := range commandForm.Command {
-		e := bson.E{}
-		for k, v := range cmd {
-			e.Key = k
-			e.Value = v
-		}
-		commands = append(commands, e)
-	}
-
-	ctx := context.TODO()
-	var bm bson.M
-	err = conn.Cli.Database(commandForm.Database).RunCommand(
-		ctx,
-		commands,
-	).Decode(&bm)
-
+	res, err := m.MongoApp.RunWriteCmd(rc.MetaCtx, conn, &dto.MongoWriteCmd{
+		Id:       conn.Info.Id,
+		Type:     dto.MongoWriteCmdTypeRunCommand,
+		Database: commandForm.Database,
+		Command:  commandForm.Command,
+		Remark:   commandForm.Remark,
+	})
 	biz.ErrIsNilAppendErr(err, "执行命令失败: %s")
-	rc.ResData = bm
+	rc.ResData = res
 }
 
 func (m *Mongo) FindCommand(rc *req.Ctx) {
@@ -177,19 +167,16 @@ func (m *Mongo) UpdateByIdCommand(rc *req.Ctx) {
 	biz.ErrIsNil(err)
 	rc.ReqParam = collx.Kvs("mongo", conn.Info, "cmd", commandForm)
 
-	// 解析docId文档id，如果为string类型则使用ObjectId解析，解析失败则为普通字符串
-	docId := commandForm.DocId
-	docIdVal, ok := docId.(string)
-	if ok {
-		objId, err := primitive.ObjectIDFromHex(docIdVal)
-		if err == nil {
-			docId = objId
-		}
-	}
-
-	res, err := conn.Cli.Database(commandForm.Database).Collection(commandForm.Collection).UpdateByID(context.TODO(), docId, commandForm.Update)
+	res, err := m.MongoApp.RunWriteCmd(rc.MetaCtx, conn, &dto.MongoWriteCmd{
+		Id:         conn.Info.Id,
+		Type:       dto.MongoWriteCmdTypeUpdateById,
+		Database:   commandForm.Database,
+		Collection: commandForm.Collection,
+		DocId:      commandForm.DocId,
+		Update:     commandForm.Update,
+		Remark:     commandForm.Remark,
+	})
 	biz.ErrIsNilAppendErr(err, "命令执行失败: %s")
-
 	rc.ResData = res
 }
 
@@ -200,17 +187,14 @@ func (m *Mongo) DeleteByIdCommand(rc *req.Ctx) {
 	biz.ErrIsNil(err)
 	rc.ReqParam = collx.Kvs("mongo", conn.Info, "cmd", commandForm)
 
-	// 解析docId文档id，如果为string类型则使用ObjectId解析，解析失败则为普通字符串
-	docId := commandForm.DocId
-	docIdVal, ok := docId.(string)
-	if ok {
-		objId, err := primitive.ObjectIDFromHex(docIdVal)
-		if err == nil {
-			docId = objId
-		}
-	}
-
-	res, err := conn.Cli.Database(commandForm.Database).Collection(commandForm.Collection).DeleteOne(context.TODO(), bson.D{{Key: "_id", Value: docId}})
+	res, err := m.MongoApp.RunWriteCmd(rc.MetaCtx, conn, &dto.MongoWriteCmd{
+		Id:         conn.Info.Id,
+		Type:       dto.MongoWriteCmdTypeDeleteById,
+		Database:   commandForm.Database,
+		Collection: commandForm.Collection,
+		DocId:      commandForm.DocId,
+		Remark:     commandForm.Remark,
+	})
 	biz.ErrIsNilAppendErr(err, "命令执行失败: %s")
 	rc.ResData = res
 }
@@ -222,7 +206,14 @@ func (m *Mongo) InsertOneCommand(rc *req.Ctx) {
 	biz.ErrIsNil(err)
 	rc.ReqParam = collx.Kvs("mongo", conn.Info, "cmd", commandForm)
 
-	res, err := conn.Cli.Database(commandForm.Database).Collection(commandForm.Collection).InsertOne(context.TODO(), commandForm.Doc)
+	res, err := m.MongoApp.RunWriteCmd(rc.MetaCtx, conn, &dto.MongoWriteCmd{
+		Id:         conn.Info.Id,
+		Type:       dto.MongoWriteCmdTypeInsert,
+		Database:   commandForm.Database,
+		Collection: commandForm.Collection,
+		Doc:        commandForm.Doc,
+		Remark:     commandForm.Remark,
+	})
 	biz.ErrIsNilAppendErr(err, "命令执行失败: %s")
 	rc.ResData = res
 }
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"mayfly-go/internal/mongo/api/form"
+	"mayfly-go/internal/mongo/application/dto"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 聚合查询最大返回条数
+const maxAggregateLimit = 1000
+
+func (m *Mongo) AggregateCommand(rc *req.Ctx) {
+	commandForm := req.BindJsonAndValid(rc, new(form.MongoAggregateCommand))
+
+	conn, err := m.MongoApp.GetMongoConn(m.GetMongoId(rc))
+	biz.ErrIsNil(err)
+
+	pipeline, err := parsePipeline(commandForm.Pipeline)
+	biz.ErrIsNil(err)
+	// 聚合查询仅用于查询，不允许通过$out、$merge写入数据
+	for _, stage := range pipeline {
+		for _, e := range stage {
+			biz.IsTrue(e.Key != "$out" && e.Key != "$merge", "聚合管道不支持%s阶段", e.Key)
+		}
+	}
+
+	limit := commandForm.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	biz.IsTrue(limit <= maxAggregateLimit, "limit不能超过%d", maxAggregateLimit)
+	// 追加$limit限制返回条数
+	pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+
+	ctx := context.TODO()
+	cur, err := conn.Cli.Database(commandForm.Database).Collection(commandForm.Collection).Aggregate(ctx, pipeline)
+	biz.ErrIsNilAppendErr(err, "命令执行失败: %s")
+
+	var res []bson.M
+	biz.ErrIsNilAppendErr(cur.All(ctx, &res), "读取结果失败: %s")
+	rc.ResData = res
+}
+
+func (m *Mongo) ExplainCommand(rc *req.Ctx) {
+	commandForm := req.BindJsonAndValid(rc, new(form.MongoExplainCommand))
+
+	conn, err := m.MongoApp.GetMongoConn(m.GetMongoId(rc))
+	biz.ErrIsNil(err)
+
+	var explainCmd bson.D
+	switch commandForm.Type {
+	case "find":
+		explainCmd = bson.D{{Key: "find", Value: commandForm.Collection}, {Key: "filter", Value: wrapObjectId(commandForm.Filter)}}
+		if len(commandForm.Sort) > 0 {
+			explainCmd = append(explainCmd, bson.E{Key: "sort", Value: commandForm.Sort})
+		}
+		if commandForm.Skip > 0 {
+			explainCmd = append(explainCmd, bson.E{Key: "skip", Value: commandForm.Skip})
+		}
+		if commandForm.Limit > 0 {
+			explainCmd = append(explainCmd, bson.E{Key: "limit", Value: commandForm.Limit})
+		}
+	case "aggregate":
+		pipeline, err := parsePipeline(commandForm.Pipeline)
+		biz.ErrIsNil(err)
+		explainCmd = bson.D{{Key: "aggregate", Value: commandForm.Collection}, {Key: "pipeline", Value: pipeline}, {Key: "cursor", Value: bson.D{}}}
+	default:
+		panic(errorx.NewBiz("不支持的explain类型: %s", commandForm.Type))
+	}
+
+	verbosity := commandForm.Verbosity
+	if verbosity == "" {
+		verbosity = "queryPlanner"
+	}
+
+	var res bson.M
+	err = conn.Cli.Database(commandForm.Database).RunCommand(context.TODO(), bson.D{
+		{Key: "explain", Value: explainCmd},
+		{Key: "verbosity", Value: verbosity},
+	}).Decode(&res)
+	biz.ErrIsNilAppendErr(err, "命令执行失败: %s")
+	rc.ResData = res
+}
+
+func (m *Mongo) Indexes(rc *req.Ctx) {
+	conn, err := m.MongoApp.GetMongoConn(m.GetMongoId(rc))
+	biz.ErrIsNil(err)
+
+	db, coll := getDbAndCollection(rc)
+	ctx := context.TODO()
+	cur, err := conn.Cli.Database(db).Collection(coll).Indexes().List(ctx)
+	biz.ErrIsNilAppendErr(err, "获取索引信息失败: %s")
+
+	var res []bson.M
+	biz.ErrIsNilAppendErr(cur.All(ctx, &res), "读取索引信息失败: %s")
+	rc.ResData = res
+}
+
+func (m *Mongo) CreateIndex(rc *req.Ctx) {
+	indexForm := req.BindJsonAndValid(rc, new(form.MongoCreateIndex))
+
+	conn, err := m.MongoApp.GetMongoConn(m.GetMongoId(rc))
+	biz.ErrIsNil(err)
+	rc.ReqParam = collx.Kvs("mongo", conn.Info, "index", indexForm)
+
+	index := &dto.MongoIndex{
+		Keys:               string(indexForm.Keys),
+		Name:               indexForm.Name,
+		Unique:             indexForm.Unique,
+		Sparse:             indexForm.Sparse,
+		ExpireAfterSeconds: indexForm.ExpireAfterSeconds,
+	}
+	// 提前校验索引字段，避免审批通过后才发现格式错误
+	_, err = index.ToIndexModel()
+	biz.ErrIsNil(err)
+
+	res, err := m.MongoApp.RunWriteCmd(rc.MetaCtx, conn, &dto.MongoWriteCmd{
+		Id:         conn.Info.Id,
+		Type:       dto.MongoWriteCmdTypeCreateIndex,
+		Database:   indexForm.Database,
+		Collection: indexForm.Collection,
+		Index:      index,
+	})
+	biz.ErrIsNilAppendErr(err, "创建索引失败: %s")
+	rc.ResData = res
+}
+
+func (m *Mongo) DropIndex(rc *req.Ctx) {
+	conn, err := m.MongoApp.GetMongoConn(m.GetMongoId(rc))
+	biz.ErrIsNil(err)
+
+	db, coll := getDbAndCollection(rc)
+	name := rc.Query("name")
+	biz.NotEmpty(name, "索引名不能为空")
+	biz.IsTrue(name != "_id_", "不能删除_id索引")
+	rc.ReqParam = collx.Kvs("mongo", conn.Info, "database", db, "collection", coll, "index", name)
+
+	_, err = m.MongoApp.RunWriteCmd(rc.MetaCtx, conn, &dto.MongoWriteCmd{
+		Id:         conn.Info.Id,
+		Type:       dto.MongoWriteCmdTypeDropIndex,
+		Database:   db,
+		Collection: coll,
+		Index:      &dto.MongoIndex{Name: name},
+	})
+	biz.ErrIsNilAppendErr(err, "删除索引失败: %s")
+}
+
+func (m *Mongo) CollStats(rc *req.Ctx) {
+	conn, err := m.MongoApp.GetMongoConn(m.GetMongoId(rc))
+	biz.ErrIsNil(err)
+
+	db, coll := getDbAndCollection(rc)
+	var res bson.M
+	err = conn.Cli.Database(db).RunCommand(context.TODO(), bson.D{{Key: "collStats", Value: coll}}).Decode(&res)
+	biz.ErrIsNilAppendErr(err, "获取集合统计信息失败: %s")
+	rc.ResData = res
+}
+
+func (m *Mongo) DbStats(rc *req.Ctx) {
+	conn, err := m.MongoApp.GetMongoConn(m.GetMongoId(rc))
+	biz.ErrIsNil(err)
+
+	db := rc.Query("database")
+	biz.NotEmpty(db, "database不能为空")
+	var res bson.M
+	err = conn.Cli.Database(db).RunCommand(context.TODO(), bson.D{{Key: "dbStats", Value: 1}}).Decode(&res)
+	biz.ErrIsNilAppendErr(err, "获取库统计信息失败: %s")
+	rc.ResData = res
+}
+
+// 获取查询参数中的库名与集合名
+func getDbAndCollection(rc *req.Ctx) (string, string) {
+	db := rc.Query("database")
+	biz.NotEmpty(db, "database不能为空")
+	coll := rc.Query("collection")
+	biz.NotEmpty(coll, "collection不能为空")
+	return db, coll
+}
+
+// 解析聚合管道，使用扩展json解析以保留各阶段中字段的顺序（如$sort）
+func parsePipeline(raw json.RawMessage) ([]bson.D, error) {
+	wrapper := struct {
+		Pipeline []bson.D `bson:"pipeline"`
+	}{}
+	if err := bson.UnmarshalExtJSON([]byte(`{"pipeline":`+string(raw)+`}`), false, &wrapper); err != nil {
+		return nil, errorx.NewBiz("聚合管道格式错误: %s", err.Error())
+	}
+	if len(wrapper.Pipeline) == 0 {
+		return nil, errorx.NewBiz("聚合管道不能为空")
+	}
+	return wrapper.Pipeline, nil
+}
+
+// 处理_id查询字段,使用ObjectId函数包装
+func wrapObjectId(filter map[string]any) map[string]any {
+	if filter == nil {
+		return map[string]any{}
+	}
+	if id, ok := filter["_id"].(string); ok && id != "" {
+		if objId, err := primitive.ObjectIDFromHex(id); err == nil {
+			filter["_id"] = objId
+		}
+	}
+	return filter
+}
@@ -7,3 +7,7 @@ import (
 func InitIoc() {
 	ioc.Register(new(mongoAppImpl), ioc.WithComponentName("MongoApp"))
 }
+
+func Init() {
+	InitMongoFlowHandler()
+}
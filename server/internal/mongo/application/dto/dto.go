@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"mayfly-go/pkg/errorx"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongo写操作类型
+type MongoWriteCmdType string
+
+const (
+	MongoWriteCmdTypeInsert      MongoWriteCmdType = "insert"
+	MongoWriteCmdTypeUpdateById  MongoWriteCmdType = "update_by_id"
+	MongoWriteCmdTypeDeleteById  MongoWriteCmdType = "delete_by_id"
+	MongoWriteCmdTypeRunCommand  MongoWriteCmdType = "run_command"
+	MongoWriteCmdTypeCreateIndex MongoWriteCmdType = "create_index"
+	MongoWriteCmdTypeDropIndex   MongoWriteCmdType = "drop_index"
+)
+
+// mongo写操作，开启审批流程时会序列化为流程业务表单
+type MongoWriteCmd struct {
+	Id         uint64            `json:"id"`
+	Type       MongoWriteCmdType `json:"type"`
+	Database   string            `json:"database"`
+	Collection string            `json:"collection"`
+	DocId      any               `json:"docId"`
+	Update     map[string]any    `json:"update"`
+	Doc        map[string]any    `json:"doc"`
+	Command    []map[string]any  `json:"command"`
+	Index      *MongoIndex       `json:"index,omitempty"` // 创建或删除的索引
+	Remark     string            `json:"remark"`
+}
+
+// mongo索引信息
+type MongoIndex struct {
+	Keys               string `json:"keys,omitempty"` // 索引字段扩展json，如: {"name": 1, "age": -1}
+	Name               string `json:"name"`
+	Unique             bool   `json:"unique,omitempty"`
+	Sparse             bool   `json:"sparse,omitempty"`
+	ExpireAfterSeconds *int32 `json:"expireAfterSeconds,omitempty"`
+}
+
+// ToIndexModel 转为驱动的索引模型，索引字段使用扩展json解析以保留字段顺序
+func (mi *MongoIndex) ToIndexModel() (mongo.IndexModel, error) {
+	var keys bson.D
+	if err := bson.UnmarshalExtJSON([]byte(mi.Keys), false, &keys); err != nil {
+		return mongo.IndexModel{}, errorx.NewBiz("索引字段格式错误: %s", err.Error())
+	}
+	if len(keys) == 0 {
+		return mongo.IndexModel{}, errorx.NewBiz("索引字段不能为空")
+	}
+
+	opts := options.Index()
+	if mi.Name != "" {
+		opts.SetName(mi.Name)
+	}
+	if mi.Unique {
+		opts.SetUnique(true)
+	}
+	if mi.Sparse {
+		opts.SetSparse(true)
+	}
+	if mi.ExpireAfterSeconds != nil {
+		opts.SetExpireAfterSeconds(*mi.ExpireAfterSeconds)
+	}
+	return mongo.IndexModel{Keys: keys, Options: opts}, nil
+}
@@ -0,0 +1,14 @@
+package application
+
+import (
+	flowapp "mayfly-go/internal/flow/application"
+	"mayfly-go/pkg/ioc"
+)
+
+const (
+	MongoRunWriteCmdFlowBizType = "mongo_run_write_cmd_flow"
+)
+
+func InitMongoFlowHandler() {
+	flowapp.RegisterBizHandler(MongoRunWriteCmdFlowBizType, ioc.Get[Mongo]("MongoApp"))
+}
@@ -3,6 +3,10 @@ package application
 import (
 	"context"
 	"mayfly-go/internal/common/consts"
+	flowapp "mayfly-go/internal/flow/application"
+	flowdto "mayfly-go/internal/flow/application/dto"
+	flowentity "mayfly-go/internal/flow/domain/entity"
+	"mayfly-go/internal/mongo/application/dto"
 	"mayfly-go/internal/mongo/domain/entity"
 	"mayfly-go/internal/mongo/domain/repository"
 	"mayfly-go/internal/mongo/mgm"
@@ -11,11 +15,19 @@ import (
 	tagentity "mayfly-go/internal/tag/domain/entity"
 	"mayfly-go/pkg/base"
 	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
 	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/jsonx"
+	"mayfly-go/pkg/utils/stringx"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Mongo interface {
 	base.App[*entity.Mongo]
+	flowapp.FlowBizHandler
 
 	// 分页获取机器脚本信息列表
 	GetPageList(condition *entity.MongoQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
@@ -30,12 +42,17 @@ type Mongo interface {
 	// 获取mongo连接实例
 	// @param id mongo id
 	GetMongoConn(id uint64) (*mgm.MongoConn, error)
+
+	// 执行mongo写操作，若关联了审批流程则开启流程，审批通过后再执行
+	RunWriteCmd(ctx context.Context, conn *mgm.MongoConn, cmdParam *dto.MongoWriteCmd) (any, error)
 }
 
 type mongoAppImpl struct {
 	base.AppImpl[*entity.Mongo, repository.Mongo]
 
-	tagApp tagapp.TagTree `inject:"TagTreeApp"`
+	tagApp      tagapp.TagTree   `inject:"TagTreeApp"`
+	procdefApp  flowapp.Procdef  `inject:"ProcdefApp"`
+	procinstApp flowapp.Procinst `inject:"ProcinstApp"`
 }
 
 // 注入MongoRepo
@@ -141,3 +158,144 @@ func (d *mongoAppImpl) GetMongoConn(id uint64) (*mgm.MongoConn, error) {
 		return me.ToMongoInfo(d.tagApp.ListTagPathByTypeAndCode(consts.ResourceTypeMongo, me.Code)...), nil
 	})
 }
+
+func (d *mongoAppImpl) RunWriteCmd(ctx context.Context, conn *mgm.MongoConn, cmdParam *dto.MongoWriteCmd) (any, error) {
+	if conn == nil {
+		return nil, errorx.NewBiz("mongo连接不存在")
+	}
+
+	// 执行的命令为非写命令，则直接执行
+	if cmdParam.Type == dto.MongoWriteCmdTypeRunCommand && !isWriteCommand(cmdParam.Command) {
+		return d.execWriteCmd(ctx, conn, cmdParam)
+	}
+
+	// 开启工单流程，则开启对应审批流程
+	if procdefId := d.procdefApp.GetProcdefIdByCodePath(ctx, conn.Info.CodePath...); procdefId != 0 {
+		_, err := d.procinstApp.StartProc(ctx, procdefId, &flowdto.StarProc{
+			BizType: MongoRunWriteCmdFlowBizType,
+			BizKey:  stringx.Rand(24),
+			BizForm: jsonx.ToStr(cmdParam),
+			Remark:  cmdParam.Remark,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	return d.execWriteCmd(ctx, conn, cmdParam)
+}
+
+func (d *mongoAppImpl) FlowBizHandle(ctx context.Context, bizHandleParam *flowapp.BizHandleParam) (any, error) {
+	bizKey := bizHandleParam.BizKey
+	procinstStatus := bizHandleParam.ProcinstStatus
+
+	logx.Debugf("MongoRunWriteCmd FlowBizHandle -> bizKey: %s, procinstStatus: %s", bizKey, flowentity.ProcinstStatusEnum.GetDesc(procinstStatus))
+	// 流程非完成状态，不处理
+	if procinstStatus != flowentity.ProcinstStatusCompleted {
+		return nil, nil
+	}
+
+	cmdParam, err := jsonx.To(bizHandleParam.BizForm, new(dto.MongoWriteCmd))
+	if err != nil {
+		return nil, errorx.NewBiz("业务表单信息解析失败: %s", err.Error())
+	}
+
+	conn, err := d.GetMongoConn(cmdParam.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.execWriteCmd(ctx, conn, cmdParam)
+}
+
+func (d *mongoAppImpl) execWriteCmd(ctx context.Context, conn *mgm.MongoConn, cmdParam *dto.MongoWriteCmd) (any, error) {
+	db := conn.Cli.Database(cmdParam.Database)
+	switch cmdParam.Type {
+	case dto.MongoWriteCmdTypeInsert:
+		return db.Collection(cmdParam.Collection).InsertOne(ctx, cmdParam.Doc)
+	case dto.MongoWriteCmdTypeUpdateById:
+		return db.Collection(cmdParam.Collection).UpdateByID(ctx, parseDocId(cmdParam.DocId), cmdParam.Update)
+	case dto.MongoWriteCmdTypeDeleteById:
+		return db.Collection(cmdParam.Collection).DeleteOne(ctx, bson.D{{Key: "_id", Value: parseDocId(cmdParam.DocId)}})
+	case dto.MongoWriteCmdTypeRunCommand:
+		// 顺序执行
+		commands := bson.D{}
+		for _, cmd := range cmdParam.Command {
+			e := bson.E{}
+			for k, v := range cmd {
+				e.Key = k
+				e.Value = v
+			}
+			commands = append(commands, e)
+		}
+
+		var bm bson.M
+		if err := db.RunCommand(ctx, commands).Decode(&bm); err != nil {
+			return nil, err
+		}
+		return bm, nil
+	case dto.MongoWriteCmdTypeCreateIndex:
+		index := cmdParam.Index
+		if index == nil {
+			return nil, errorx.NewBiz("索引信息不能为空")
+		}
+		indexModel, err := index.ToIndexModel()
+		if err != nil {
+			return nil, err
+		}
+		return db.Collection(cmdParam.Collection).Indexes().CreateOne(ctx, indexModel)
+	case dto.MongoWriteCmdTypeDropIndex:
+		if cmdParam.Index == nil || cmdParam.Index.Name == "" {
+			return nil, errorx.NewBiz("索引名不能为空")
+		}
+		return db.Collection(cmdParam.Collection).Indexes().DropOne(ctx, cmdParam.Index.Name)
+	}
+	return nil, errorx.NewBiz("不支持的操作类型: %s", cmdParam.Type)
+}
+
+// 判断命令是否为写命令，命令名为第一个字段。无法确定命令名时视为写命令
+func isWriteCommand(commands []map[string]any) bool {
+	if len(commands) == 0 || len(commands[0]) != 1 {
+		return true
+	}
+	var cmdName string
+	for k := range commands[0] {
+		cmdName = k
+	}
+	if mgm.IsWriteCmd(cmdName) {
+		return true
+	}
+	if !strings.EqualFold(cmdName, "aggregate") {
+		return false
+	}
+
+	// 聚合命令包含$out、$merge阶段时会写入数据
+	for _, cmd := range commands[1:] {
+		pipeline, ok := cmd["pipeline"]
+		if !ok {
+			continue
+		}
+		stages, ok := pipeline.([]any)
+		if !ok {
+			return true
+		}
+		for _, stage := range stages {
+			stageMap, ok := stage.(map[string]any)
+			if !ok || mgm.IsWritePipeline([]map[string]any{stageMap}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 解析docId文档id，如果为string类型则使用ObjectId解析，解析失败则为普通字符串
+func parseDocId(docId any) any {
+	if docIdVal, ok := docId.(string); ok {
+		if objId, err := primitive.ObjectIDFromHex(docIdVal); err == nil {
+			return objId
+		}
+	}
+	return docId
+}
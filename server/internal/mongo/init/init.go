@@ -13,4 +13,5 @@ func init() {
 		application.InitIoc()
 	})
 	initialize.AddInitRouterFunc(router.Init)
+	initialize.AddInitFunc(application.Init)
 }
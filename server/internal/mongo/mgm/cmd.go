@@ -0,0 +1,47 @@
+package mgm
+
+import "strings"
+
+// 只读命令，不在其中的命令均视为写命令（需审批），避免遗漏会修改数据、结构或权限的命令
+var readCmd = map[string]struct{}{
+	"find":             {},
+	"count":            {},
+	"distinct":         {},
+	"aggregate":        {}, // 包含$out、$merge阶段时为写命令，需额外判断
+	"getmore":          {},
+	"explain":          {},
+	"listcollections":  {},
+	"listindexes":      {},
+	"listdatabases":    {},
+	"dbstats":          {},
+	"collstats":        {},
+	"datasize":         {},
+	"serverstatus":     {},
+	"buildinfo":        {},
+	"hostinfo":         {},
+	"ping":             {},
+	"hello":            {},
+	"ismaster":         {},
+	"connectionstatus": {},
+	"getparameter":     {},
+	"replsetgetstatus": {},
+}
+
+// 判断命令是否为写命令，非只读命令均视为写命令
+func IsWriteCmd(cmd string) bool {
+	_, ok := readCmd[strings.ToLower(cmd)]
+	return !ok
+}
+
+// 判断聚合管道是否包含写入阶段（$out、$merge）
+func IsWritePipeline(pipeline []map[string]any) bool {
+	for _, stage := range pipeline {
+		if _, ok := stage["$out"]; ok {
+			return true
+		}
+		if _, ok := stage["$merge"]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -51,6 +51,25 @@ func InitMongoRouter(router *gin.RouterGroup) {
 
 		// 执行mongo insert 命令
 		req.NewPost(":id/command/insert", ma.InsertOneCommand).RequiredPermission(saveDataPerm).Log(req.NewLogSave("mogno-插入文档")),
+
+		// 执行mongo aggregate命令
+		req.NewPost(":id/command/aggregate", ma.AggregateCommand),
+
+		// 获取find或aggregate的执行计划
+		req.NewPost(":id/command/explain", ma.ExplainCommand),
+
+		// 获取集合索引列表
+		req.NewGet(":id/indexes", ma.Indexes),
+
+		req.NewPost(":id/indexes", ma.CreateIndex).RequiredPermission(saveDataPerm).Log(req.NewLogSave("mongo-创建索引")),
+
+		req.NewDelete(":id/indexes", ma.DropIndex).RequiredPermission(saveDataPerm).Log(req.NewLogSave("mongo-删除索引")),
+
+		// 获取集合统计信息
+		req.NewGet(":id/coll-stats", ma.CollStats),
+
+		// 获取库统计信息
+		req.NewGet(":id/db-stats", ma.DbStats),
 	}
 
 	req.BatchSetGroup(m, reqs[:])
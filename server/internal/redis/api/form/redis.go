@@ -39,3 +39,38 @@ type RunCmdForm struct {
 	Cmd    []any  `json:"cmd"`
 	Remark string `json:"remark"`
 }
+
+// 复制key至其他redis
+type CopyKeysForm struct {
+	TargetId         uint64 `json:"targetId" binding:"required"`
+	TargetDb         int    `json:"targetDb"`
+	Match            string `json:"match" binding:"required"`
+	ConflictStrategy string `json:"conflictStrategy" binding:"required"` // key冲突处理策略: skip、replace、rename
+	RenameSuffix     string `json:"renameSuffix"`
+}
+
+// key分析
+type KeyAnalysisForm struct {
+	Match       string `json:"match"`
+	TopN        int    `json:"topN"`
+	Separator   string `json:"separator"`   // key前缀分隔符，默认为:
+	PrefixDepth int    `json:"prefixDepth"` // 前缀层级，默认为1
+}
+
+// stream消息确认
+type StreamAckForm struct {
+	Key    string   `json:"key" binding:"required"`
+	Group  string   `json:"group" binding:"required"`
+	Ids    []string `json:"ids" binding:"required"`
+	Remark string   `json:"remark"`
+}
+
+// stream消息转移至其他消费者
+type StreamClaimForm struct {
+	Key      string   `json:"key" binding:"required"`
+	Group    string   `json:"group" binding:"required"`
+	Consumer string   `json:"consumer" binding:"required"`
+	MinIdle  int64    `json:"minIdle"` // 最小空闲时间(毫秒)
+	Ids      []string `json:"ids" binding:"required"`
+	Remark   string   `json:"remark"`
+}
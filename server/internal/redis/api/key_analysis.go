@@ -0,0 +1,72 @@
+package api
+
+import (
+	"mayfly-go/internal/redis/api/form"
+	"mayfly-go/internal/redis/application/dto"
+	"mayfly-go/internal/redis/domain/entity"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+)
+
+// 开启后台key分析任务
+func (r *Redis) StartKeyAnalysis(rc *req.Ctx) {
+	form := req.BindJsonAndValid(rc, new(form.KeyAnalysisForm))
+	ri := r.getRedisConn(rc)
+	id, db := getIdAndDbNum(rc)
+	rc.ReqParam = collx.Kvs("redis", ri.Info, "form", form)
+
+	analysis, err := r.RedisKeyAnalysisApp.StartAnalysis(rc.MetaCtx, &dto.KeyAnalysis{
+		RedisId:     id,
+		Db:          db,
+		Match:       form.Match,
+		TopN:        form.TopN,
+		Separator:   form.Separator,
+		PrefixDepth: form.PrefixDepth,
+	})
+	biz.ErrIsNil(err)
+	rc.ResData = analysis.Id
+}
+
+func (r *Redis) KeyAnalysisList(rc *req.Ctx) {
+	redisId := r.checkRedisAccess(rc)
+	cond, page := req.BindQueryAndPage[*entity.RedisKeyAnalysisQuery](rc, new(entity.RedisKeyAnalysisQuery))
+	cond.RedisId = redisId
+
+	var analyses []*entity.RedisKeyAnalysis
+	res, err := r.RedisKeyAnalysisApp.GetPageList(cond, page, &analyses, "id DESC")
+	biz.ErrIsNil(err)
+	// 列表不返回分析结果详情
+	for _, analysis := range analyses {
+		analysis.Result = ""
+	}
+	rc.ResData = res
+}
+
+func (r *Redis) KeyAnalysisDetail(rc *req.Ctx) {
+	rc.ResData = r.getKeyAnalysis(rc)
+}
+
+func (r *Redis) DeleteKeyAnalysis(rc *req.Ctx) {
+	analysis := r.getKeyAnalysis(rc)
+	rc.ReqParam = analysis.Id
+	biz.ErrIsNil(r.RedisKeyAnalysisApp.DeleteById(rc.MetaCtx, analysis.Id))
+}
+
+// 获取key分析记录，并校验是否有该redis的操作权限
+func (r *Redis) getKeyAnalysis(rc *req.Ctx) *entity.RedisKeyAnalysis {
+	redisId := r.checkRedisAccess(rc)
+	analysis, err := r.RedisKeyAnalysisApp.GetById(uint64(rc.PathParamInt("analysisId")))
+	biz.ErrIsNil(err, "分析记录不存在")
+	biz.IsTrue(analysis.RedisId == redisId, "分析记录不存在")
+	return analysis
+}
+
+// 校验当前账号是否可访问路径参数中的redis，并返回redis id
+func (r *Redis) checkRedisAccess(rc *req.Ctx) uint64 {
+	redisId := uint64(rc.PathParamInt("id"))
+	ri, err := r.RedisApp.GetRedisConn(redisId, 0)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(r.TagApp.CanAccess(rc.GetLoginAccount().Id, ri.Info.CodePath...), "%s")
+	return redisId
+}
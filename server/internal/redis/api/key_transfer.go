@@ -0,0 +1,73 @@
+package api
+
+import (
+	"fmt"
+	"mayfly-go/internal/redis/api/form"
+	"mayfly-go/internal/redis/application/dto"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+	"time"
+)
+
+// 复制匹配的key至其他redis
+func (r *Redis) CopyKeys(rc *req.Ctx) {
+	form := req.BindJsonAndValid(rc, new(form.CopyKeysForm))
+	srcConn := r.getRedisConn(rc)
+
+	targetConn, err := r.RedisApp.GetRedisConn(form.TargetId, form.TargetDb)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(r.TagApp.CanAccess(rc.GetLoginAccount().Id, targetConn.Info.CodePath...), "%s")
+	biz.IsTrue(srcConn.Id != targetConn.Id, "源redis与目标redis不能相同")
+
+	rc.ReqParam = collx.Kvs("src", srcConn.Info, "target", targetConn.Info, "form", form)
+
+	res, err := r.RedisKeyTransferApp.CopyKeys(rc.MetaCtx, &dto.CopyKeys{
+		SrcConn:          srcConn,
+		TargetConn:       targetConn,
+		Match:            form.Match,
+		ConflictStrategy: dto.KeyConflictStrategy(form.ConflictStrategy),
+		RenameSuffix:     form.RenameSuffix,
+	})
+	biz.ErrIsNilAppendErr(err, "复制key失败: %s")
+	rc.ResData = res
+}
+
+// 导出匹配的key至文件
+func (r *Redis) ExportKeys(rc *req.Ctx) {
+	ri := r.getRedisConn(rc)
+	match := rc.Query("match")
+	biz.NotEmpty(match, "match不能为空")
+
+	_, db := getIdAndDbNum(rc)
+	filename := fmt.Sprintf("%s-db%d.%s.rkeys", ri.Info.Name, db, time.Now().Format("20060102150405"))
+	rc.Header("Content-Type", "application/octet-stream")
+	rc.Header("Content-Disposition", "attachment; filename="+filename)
+
+	count, err := r.RedisKeyTransferApp.ExportKeys(rc.MetaCtx, ri, match, rc.GetWriter())
+	rc.ReqParam = collx.Kvs("redis", ri.Info, "match", match, "count", count)
+	biz.ErrIsNilAppendErr(err, "导出key失败: %s")
+}
+
+// 导入ExportKeys导出的key文件
+func (r *Redis) ImportKeys(rc *req.Ctx) {
+	ri := r.getRedisConn(rc)
+	conflictStrategy := rc.PostForm("conflictStrategy")
+
+	fileheader, err := rc.FormFile("file")
+	biz.ErrIsNilAppendErr(err, "读取文件失败: %s")
+	file, _ := fileheader.Open()
+	defer file.Close()
+
+	rc.ReqParam = collx.Kvs("redis", ri.Info, "filename", fileheader.Filename, "conflictStrategy", conflictStrategy)
+
+	res, err := r.RedisKeyTransferApp.ImportKeys(rc.MetaCtx, &dto.ImportKeys{
+		Conn:             ri,
+		Reader:           file,
+		Filename:         fileheader.Filename,
+		ConflictStrategy: dto.KeyConflictStrategy(conflictStrategy),
+		RenameSuffix:     rc.PostForm("renameSuffix"),
+	})
+	biz.ErrIsNilAppendErr(err, "导入key失败: %s")
+	rc.ResData = res
+}
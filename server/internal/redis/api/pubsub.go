@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"mayfly-go/internal/redis/rdm"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/stringx"
+	"mayfly-go/pkg/ws"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// 客户端发送的控制台操作
+type consoleOp struct {
+	Type     string   `json:"type"` // subscribe、psubscribe、unsubscribe、punsubscribe、xread、xstop
+	Channels []string `json:"channels"`
+	Stream   string   `json:"stream"`
+	Id       string   `json:"id"` // xread起始消息id，为空则只读取新消息
+}
+
+// pub/sub及stream控制台，通过websocket推送订阅消息及stream新增消息
+func (r *Redis) WsPubSub(g *gin.Context) {
+	wsConn, err := ws.Upgrader.Upgrade(g.Writer, g.Request, nil)
+	defer func() {
+		if wsConn != nil {
+			if err := recover(); err != nil {
+				wsConn.WriteJSON(&rdm.ConsoleMsg{Type: rdm.ConsoleMsgTypeError, Msg: anyx.ToString(err)})
+			}
+			wsConn.Close()
+		}
+	}()
+	biz.ErrIsNilAppendErr(err, "升级websocket失败: %s")
+
+	// 权限校验
+	rc := req.NewCtxWithGin(g).WithRequiredPermission(req.NewPermission("redis:data"))
+	if err = req.PermissionHandler(rc); err != nil {
+		panic(errorx.NewBiz("您没有权限操作该redis, 请重新登录后再试~"))
+	}
+	ri := r.getRedisConn(rc)
+
+	rc.WithLog(req.NewLogSave("redis-pubsub控制台"))
+	rc.ReqParam = ri.Info
+	req.LogHandler(rc)
+
+	wsClient := ws.NewClient(ws.UserId(rc.GetLoginAccount().Id), stringx.Rand(16), wsConn)
+	// websocket连接不支持并发写
+	writeMu := &sync.Mutex{}
+	session := rdm.NewPubSubSession(ri, func(msg *rdm.ConsoleMsg) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if err := wsClient.WriteMsg(&ws.Msg{Type: ws.JsonMsg, Data: msg}); err != nil {
+			logx.Warnf("redis pubsub消息发送失败: %s", err.Error())
+		}
+	})
+	defer session.Close()
+
+	for {
+		_, data, err := wsConn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure, websocket.CloseNoStatusReceived) {
+				logx.Debugf("redis pubsub控制台读取消息失败: %s", err.Error())
+			}
+			return
+		}
+
+		op := new(consoleOp)
+		if err := json.Unmarshal(data, op); err != nil {
+			session.Send(&rdm.ConsoleMsg{Type: rdm.ConsoleMsgTypeError, Msg: "消息格式错误"})
+			continue
+		}
+		if err := handleConsoleOp(session, op); err != nil {
+			session.Send(&rdm.ConsoleMsg{Type: rdm.ConsoleMsgTypeError, Msg: err.Error()})
+			continue
+		}
+		session.Send(&rdm.ConsoleMsg{Type: rdm.ConsoleMsgTypeInfo, Msg: op.Type + " success"})
+	}
+}
+
+func handleConsoleOp(session *rdm.PubSubSession, op *consoleOp) error {
+	switch op.Type {
+	case "subscribe":
+		if len(op.Channels) == 0 {
+			return errorx.NewBiz("channels不能为空")
+		}
+		return session.Subscribe(op.Channels...)
+	case "psubscribe":
+		if len(op.Channels) == 0 {
+			return errorx.NewBiz("channels不能为空")
+		}
+		return session.PSubscribe(op.Channels...)
+	case "unsubscribe":
+		return session.Unsubscribe(op.Channels...)
+	case "punsubscribe":
+		return session.PUnsubscribe(op.Channels...)
+	case "xread":
+		if op.Stream == "" {
+			return errorx.NewBiz("stream不能为空")
+		}
+		session.TailStream(op.Stream, op.Id)
+		return nil
+	case "xstop":
+		session.StopStream(op.Stream)
+		return nil
+	}
+	return errorx.NewBiz("不支持的操作类型: %s", op.Type)
+}
@@ -24,8 +24,10 @@ import (
 )
 
 type Redis struct {
-	RedisApp application.Redis `inject:""`
-	TagApp   tagapp.TagTree    `inject:"TagTreeApp"`
+	RedisApp            application.Redis            `inject:""`
+	RedisKeyTransferApp application.RedisKeyTransfer `inject:""`
+	RedisKeyAnalysisApp application.RedisKeyAnalysis `inject:""`
+	TagApp              tagapp.TagTree               `inject:"TagTreeApp"`
 }
 
 func (r *Redis) RedisList(rc *req.Ctx) {
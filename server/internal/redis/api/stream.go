@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"mayfly-go/internal/redis/api/form"
+	"mayfly-go/internal/redis/application/dto"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 获取stream的消费者组信息
+func (r *Redis) StreamGroups(rc *req.Ctx) {
+	ri, key := r.checkKeyAndGetRedisConn(rc)
+	res, err := ri.GetCmdable().XInfoGroups(context.Background(), key).Result()
+	biz.ErrIsNilAppendErr(err, "获取消费者组失败: %s")
+	rc.ResData = res
+}
+
+// 获取stream消费者组中的消费者信息
+func (r *Redis) StreamConsumers(rc *req.Ctx) {
+	ri, key := r.checkKeyAndGetRedisConn(rc)
+	group := rc.Query("group")
+	biz.NotEmpty(group, "group不能为空")
+	res, err := ri.GetCmdable().XInfoConsumers(context.Background(), key, group).Result()
+	biz.ErrIsNilAppendErr(err, "获取消费者失败: %s")
+	rc.ResData = res
+}
+
+// 获取消费者组中待确认的消息
+func (r *Redis) StreamPending(rc *req.Ctx) {
+	ri, key := r.checkKeyAndGetRedisConn(rc)
+	group := rc.Query("group")
+	biz.NotEmpty(group, "group不能为空")
+
+	start := rc.Query("start")
+	if start == "" {
+		start = "-"
+	}
+	end := rc.Query("end")
+	if end == "" {
+		end = "+"
+	}
+
+	res, err := ri.GetCmdable().XPendingExt(context.Background(), &redis.XPendingExtArgs{
+		Stream:   key,
+		Group:    group,
+		Start:    start,
+		End:      end,
+		Count:    int64(rc.QueryIntDefault("count", 100)),
+		Consumer: rc.Query("consumer"),
+	}).Result()
+	biz.ErrIsNilAppendErr(err, "获取待确认消息失败: %s")
+	rc.ResData = res
+}
+
+// 确认消息，若开启了审批流程则需审批通过后执行
+func (r *Redis) StreamAck(rc *req.Ctx) {
+	form := req.BindJsonAndValid(rc, new(form.StreamAckForm))
+	ri := r.getRedisConn(rc)
+	id, db := getIdAndDbNum(rc)
+
+	cmd := []any{"XACK", form.Key, form.Group}
+	for _, msgId := range form.Ids {
+		cmd = append(cmd, msgId)
+	}
+	rc.ReqParam = collx.Kvs("redis", ri.Info, "cmd", cmd)
+
+	res, err := r.RedisApp.RunCmd(rc.MetaCtx, ri, &dto.RunCmd{Id: id, Db: db, Cmd: cmd, Remark: form.Remark})
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+// 将待确认消息转移至指定消费者，若开启了审批流程则需审批通过后执行
+func (r *Redis) StreamClaim(rc *req.Ctx) {
+	form := req.BindJsonAndValid(rc, new(form.StreamClaimForm))
+	ri := r.getRedisConn(rc)
+	id, db := getIdAndDbNum(rc)
+
+	cmd := []any{"XCLAIM", form.Key, form.Group, form.Consumer, form.MinIdle}
+	for _, msgId := range form.Ids {
+		cmd = append(cmd, msgId)
+	}
+	rc.ReqParam = collx.Kvs("redis", ri.Info, "cmd", cmd)
+
+	res, err := r.RedisApp.RunCmd(rc.MetaCtx, ri, &dto.RunCmd{Id: id, Db: db, Cmd: cmd, Remark: form.Remark})
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
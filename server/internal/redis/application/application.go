@@ -6,8 +6,12 @@ import (
 
 func InitIoc() {
 	ioc.Register(new(redisAppImpl), ioc.WithComponentName("RedisApp"))
+	ioc.Register(new(redisKeyTransferAppImpl), ioc.WithComponentName("RedisKeyTransferApp"))
+	ioc.Register(new(redisKeyAnalysisAppImpl), ioc.WithComponentName("RedisKeyAnalysisApp"))
 }
 
 func Init() {
 	InitRedisFlowHandler()
+
+	ioc.Get[RedisKeyAnalysis]("RedisKeyAnalysisApp").InitJob()
 }
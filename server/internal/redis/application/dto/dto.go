@@ -1,7 +1,10 @@
 package dto
 
 import (
+	"fmt"
+	"io"
 	"mayfly-go/internal/redis/domain/entity"
+	"mayfly-go/internal/redis/rdm"
 	tagentity "mayfly-go/internal/tag/domain/entity"
 )
 
@@ -17,3 +20,101 @@ type RunCmd struct {
 	Cmd    []any  `json:"cmd"`
 	Remark string
 }
+
+// key冲突处理策略
+type KeyConflictStrategy string
+
+const (
+	KeyConflictSkip    KeyConflictStrategy = "skip"    // 目标已存在则跳过
+	KeyConflictReplace KeyConflictStrategy = "replace" // 覆盖目标已存在的key
+	KeyConflictRename  KeyConflictStrategy = "rename"  // 目标已存在则添加后缀重命名
+)
+
+// 复制key参数
+type CopyKeys struct {
+	SrcConn          *rdm.RedisConn
+	TargetConn       *rdm.RedisConn
+	Match            string // key匹配规则，如: user:*
+	ConflictStrategy KeyConflictStrategy
+	RenameSuffix     string // 重命名策略时添加的后缀，默认为_copy
+}
+
+// 导入key参数
+type ImportKeys struct {
+	Conn             *rdm.RedisConn
+	Reader           io.Reader
+	Filename         string // 导入的文件名
+	ConflictStrategy KeyConflictStrategy
+	RenameSuffix     string
+}
+
+// key复制、导入审批流程的业务表单
+type KeyTransferBizForm struct {
+	Type             string              `json:"type"` // 操作类型: copy、import
+	SrcId            uint64              `json:"srcId,omitempty"`
+	SrcDb            int                 `json:"srcDb,omitempty"`
+	Id               uint64              `json:"id"` // 写入的redis id
+	Db               int                 `json:"db"`
+	Match            string              `json:"match,omitempty"`
+	Filename         string              `json:"filename,omitempty"`
+	ImportFile       string              `json:"importFile,omitempty"` // 导入文件暂存文件名，位于redis配置的导入文件暂存路径下，审批结束后删除
+	ConflictStrategy KeyConflictStrategy `json:"conflictStrategy"`
+	RenameSuffix     string              `json:"renameSuffix"`
+}
+
+// key导出文件中的单条记录
+type KeyDumpRecord struct {
+	Key   string `json:"key"`
+	Ttl   int64  `json:"ttl"`   // 剩余过期时间(毫秒)，0为永不过期
+	Value []byte `json:"value"` // DUMP命令序列化后的值
+}
+
+// key复制或导入结果
+type KeyTransferRes struct {
+	Total   int      `json:"total"`
+	Success int      `json:"success"`
+	Skip    int      `json:"skip"`
+	Fail    int      `json:"fail"`
+	Errors  []string `json:"errors"` // 失败信息，最多保留50条
+}
+
+func (r *KeyTransferRes) AddFail(key string, err error) {
+	r.Fail++
+	if len(r.Errors) < 50 {
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: %s", key, err.Error()))
+	}
+}
+
+// key分析参数
+type KeyAnalysis struct {
+	RedisId     uint64
+	Db          int
+	Match       string // key匹配规则，为空则分析全部key
+	TopN        int    // 返回内存占用及元素数量前N的key，默认100
+	Separator   string // key前缀分隔符，默认为:
+	PrefixDepth int    // 前缀层级，如a:b:c层级为2时前缀为a:b，默认为1
+}
+
+// key分析结果
+type KeyAnalysisResult struct {
+	TopMemoryKeys []*KeyStat    `json:"topMemoryKeys"` // 内存占用前N的key
+	TopLenKeys    []*KeyStat    `json:"topLenKeys"`    // 元素数量前N的key
+	HotKeys       []*KeyStat    `json:"hotKeys"`       // 访问频率前N的key，仅maxmemory-policy为lfu时有值
+	PrefixStats   []*PrefixStat `json:"prefixStats"`   // 按key前缀统计的内存分布
+}
+
+// 单个key的统计信息
+type KeyStat struct {
+	Key    string `json:"key"`
+	Type   string `json:"type"`
+	Memory int64  `json:"memory"` // 内存占用(字节)
+	Len    int64  `json:"len"`    // 元素数量，string类型则为长度
+	Freq   int64  `json:"freq"`   // lfu访问频率
+}
+
+// key前缀统计信息
+type PrefixStat struct {
+	Prefix   string `json:"prefix"`
+	KeyCount int64  `json:"keyCount"`
+	Memory   int64  `json:"memory"`
+}
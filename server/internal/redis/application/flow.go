@@ -7,8 +7,10 @@ import (
 
 const (
 	RedisRunWriteCmdFlowBizType = "redis_run_write_cmd_flow"
+	RedisKeyTransferFlowBizType = "redis_key_transfer_flow" // key复制、导入
 )
 
 func InitRedisFlowHandler() {
 	flowapp.RegisterBizHandler(RedisRunWriteCmdFlowBizType, ioc.Get[Redis]("RedisApp"))
+	flowapp.RegisterBizHandler(RedisKeyTransferFlowBizType, ioc.Get[RedisKeyTransfer]("RedisKeyTransferApp"))
 }
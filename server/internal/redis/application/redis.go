@@ -57,6 +57,8 @@ type redisAppImpl struct {
 	procdefApp          flowapp.Procdef         `inject:"ProcdefApp"`
 	procinstApp         flowapp.Procinst        `inject:"ProcinstApp"`
 	resourceAuthCertApp tagapp.ResourceAuthCert `inject:"ResourceAuthCertApp"`
+
+	redisKeyAnalysisRepo repository.RedisKeyAnalysis `inject:"RedisKeyAnalysisRepo"`
 }
 
 // 注入RedisRepo
@@ -183,6 +185,8 @@ func (r *redisAppImpl) Delete(ctx context.Context, id uint64) error {
 
 	return r.Tx(ctx, func(ctx context.Context) error {
 		return r.DeleteById(ctx, id)
+	}, func(ctx context.Context) error {
+		return r.redisKeyAnalysisRepo.DeleteByCond(ctx, &entity.RedisKeyAnalysis{RedisId: id})
 	}, func(ctx context.Context) error {
 		return r.tagApp.SaveResourceTag(ctx, &tagdto.SaveResourceTag{
 			ResourceTag: &tagdto.ResourceTag{
@@ -241,26 +245,25 @@ func (r *redisAppImpl) RunCmd(ctx context.Context, redisConn *rdm.RedisConn, cmd
 	return res, err
 }
 
-func (r *redisAppImpl) FlowBizHandle(ctx context.Context, bizHandleParam *flowapp.BizHandleParam) error {
+func (r *redisAppImpl) FlowBizHandle(ctx context.Context, bizHandleParam *flowapp.BizHandleParam) (any, error) {
 	bizKey := bizHandleParam.BizKey
 	procinstStatus := bizHandleParam.ProcinstStatus
 
 	logx.Debugf("RedisRunWriteCmd FlowBizHandle -> bizKey: %s, procinstStatus: %s", bizKey, flowentity.ProcinstStatusEnum.GetDesc(procinstStatus))
 	// 流程非完成状态，不处理
 	if procinstStatus != flowentity.ProcinstStatusCompleted {
-		return nil
+		return nil, nil
 	}
 
 	runCmdParam, err := jsonx.To(bizHandleParam.BizForm, new(dto.RunCmd))
 	if err != nil {
-		return errorx.NewBiz("业务表单信息解析失败: %s", err.Error())
+		return nil, errorx.NewBiz("业务表单信息解析失败: %s", err.Error())
 	}
 
 	redisConn, err := r.GetRedisConn(runCmdParam.Id, runCmdParam.Db)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = redisConn.RunCmd(ctx, runCmdParam.Cmd...)
-	return err
+	return redisConn.RunCmd(ctx, runCmdParam.Cmd...)
 }
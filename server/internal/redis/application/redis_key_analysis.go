@@ -0,0 +1,312 @@
+package application
+
+import (
+	"container/heap"
+	"context"
+	"mayfly-go/internal/redis/application/dto"
+	"mayfly-go/internal/redis/domain/entity"
+	"mayfly-go/internal/redis/domain/repository"
+	"mayfly-go/internal/redis/rdm"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/jsonx"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	noKeyPrefix = "[无前缀]"
+	// 前缀统计结果最多保留的数量
+	maxPrefixStats = 1000
+)
+
+type RedisKeyAnalysis interface {
+	base.App[*entity.RedisKeyAnalysis]
+
+	GetPageList(condition *entity.RedisKeyAnalysisQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+
+	// StartAnalysis 开启后台key分析任务，扫描所有匹配的key并统计大key、热key及前缀内存分布
+	StartAnalysis(ctx context.Context, param *dto.KeyAnalysis) (*entity.RedisKeyAnalysis, error)
+
+	// InitJob 将服务重启前未执行结束的分析任务置为失败，避免该库无法再次发起分析
+	InitJob()
+}
+
+type redisKeyAnalysisAppImpl struct {
+	base.AppImpl[*entity.RedisKeyAnalysis, repository.RedisKeyAnalysis]
+
+	redisApp Redis `inject:"RedisApp"`
+}
+
+var _ (RedisKeyAnalysis) = (*redisKeyAnalysisAppImpl)(nil)
+
+// 注入RedisKeyAnalysisRepo
+func (r *redisKeyAnalysisAppImpl) InjectRedisKeyAnalysisRepo(repo repository.RedisKeyAnalysis) {
+	r.Repo = repo
+}
+
+func (r *redisKeyAnalysisAppImpl) GetPageList(condition *entity.RedisKeyAnalysisQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
+	return r.GetRepo().GetPageList(condition, pageParam, toEntity, orderBy...)
+}
+
+func (r *redisKeyAnalysisAppImpl) StartAnalysis(ctx context.Context, param *dto.KeyAnalysis) (*entity.RedisKeyAnalysis, error) {
+	if r.CountByCond(&entity.RedisKeyAnalysis{RedisId: param.RedisId, Db: param.Db, Status: entity.RedisKeyAnalysisStatusRunning}) > 0 {
+		return nil, errorx.NewBiz("该库存在正在执行的分析任务")
+	}
+
+	conn, err := r.redisApp.GetRedisConn(param.RedisId, param.Db)
+	if err != nil {
+		return nil, err
+	}
+
+	if param.TopN <= 0 {
+		param.TopN = 100
+	}
+	if param.Separator == "" {
+		param.Separator = ":"
+	}
+	if param.PrefixDepth <= 0 {
+		param.PrefixDepth = 1
+	}
+
+	analysis := &entity.RedisKeyAnalysis{
+		RedisId: param.RedisId,
+		Db:      param.Db,
+		Match:   param.Match,
+		Status:  entity.RedisKeyAnalysisStatusRunning,
+	}
+	if err := r.Insert(ctx, analysis); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				r.endAnalysis(analysis, nil, errorx.NewBiz("%s", anyx.ToString(err)))
+			}
+		}()
+
+		result, err := r.doAnalysis(context.Background(), conn, param, analysis)
+		r.endAnalysis(analysis, result, err)
+	}()
+
+	return analysis, nil
+}
+
+func (r *redisKeyAnalysisAppImpl) InitJob() {
+	now := time.Now()
+	r.UpdateByCond(context.TODO(), &entity.RedisKeyAnalysis{Status: entity.RedisKeyAnalysisStatusFail, ErrMsg: "服务重启，任务已中断", EndTime: &now}, &entity.RedisKeyAnalysis{Status: entity.RedisKeyAnalysisStatusRunning})
+}
+
+func (r *redisKeyAnalysisAppImpl) endAnalysis(analysis *entity.RedisKeyAnalysis, result *dto.KeyAnalysisResult, err error) {
+	now := time.Now()
+	analysis.EndTime = &now
+	if err != nil {
+		logx.Errorf("redis[%d-%d] key分析失败: %s", analysis.RedisId, analysis.Db, err.Error())
+		analysis.Status = entity.RedisKeyAnalysisStatusFail
+		analysis.ErrMsg = err.Error()
+	} else {
+		analysis.Status = entity.RedisKeyAnalysisStatusSuccess
+		analysis.Result = jsonx.ToStr(result)
+	}
+	if err := r.UpdateById(context.Background(), analysis); err != nil {
+		logx.Errorf("更新redis key分析结果失败: %s", err.Error())
+	}
+}
+
+func (r *redisKeyAnalysisAppImpl) doAnalysis(ctx context.Context, conn *rdm.RedisConn, param *dto.KeyAnalysis, analysis *entity.RedisKeyAnalysis) (*dto.KeyAnalysisResult, error) {
+	cmd := conn.GetCmdable()
+	// 仅lfu淘汰策略下才可通过OBJECT FREQ获取key访问频率
+	lfu := false
+	if policy, err := cmd.ConfigGet(ctx, "maxmemory-policy").Result(); err == nil {
+		lfu = strings.Contains(policy["maxmemory-policy"], "lfu")
+	}
+
+	topMemory := newKeyStatTopN(param.TopN, func(ks *dto.KeyStat) int64 { return ks.Memory })
+	topLen := newKeyStatTopN(param.TopN, func(ks *dto.KeyStat) int64 { return ks.Len })
+	hotKeys := newKeyStatTopN(param.TopN, func(ks *dto.KeyStat) int64 { return ks.Freq })
+	prefixStats := make(map[string]*dto.PrefixStat)
+
+	err := scanAllKeys(ctx, conn, param.Match, func(keys []string) error {
+		keyStats, err := getKeyStats(ctx, cmd, keys, lfu)
+		if err != nil {
+			return err
+		}
+
+		for _, ks := range keyStats {
+			analysis.KeyCount++
+			analysis.TotalMemory += ks.Memory
+
+			topMemory.Add(ks)
+			topLen.Add(ks)
+			if lfu {
+				hotKeys.Add(ks)
+			}
+
+			prefix := keyPrefix(ks.Key, param.Separator, param.PrefixDepth)
+			prefixStat := prefixStats[prefix]
+			if prefixStat == nil {
+				prefixStat = &dto.PrefixStat{Prefix: prefix}
+				prefixStats[prefix] = prefixStat
+			}
+			prefixStat.KeyCount++
+			prefixStat.Memory += ks.Memory
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prefixStatList := make([]*dto.PrefixStat, 0, len(prefixStats))
+	for _, ps := range prefixStats {
+		prefixStatList = append(prefixStatList, ps)
+	}
+	sort.Slice(prefixStatList, func(i, j int) bool {
+		return prefixStatList[i].Memory > prefixStatList[j].Memory
+	})
+	if len(prefixStatList) > maxPrefixStats {
+		prefixStatList = prefixStatList[:maxPrefixStats]
+	}
+
+	return &dto.KeyAnalysisResult{
+		TopMemoryKeys: topMemory.Sorted(),
+		TopLenKeys:    topLen.Sorted(),
+		HotKeys:       hotKeys.Sorted(),
+		PrefixStats:   prefixStatList,
+	}, nil
+}
+
+// 使用pipeline批量获取key的类型、内存占用、元素数量等信息
+func getKeyStats(ctx context.Context, cmd redis.Cmdable, keys []string, lfu bool) ([]*dto.KeyStat, error) {
+	typeCmds := make([]*redis.StatusCmd, len(keys))
+	memCmds := make([]*redis.IntCmd, len(keys))
+	freqCmds := make([]*redis.IntCmd, len(keys))
+	_, err := cmd.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			typeCmds[i] = pipe.Type(ctx, key)
+			memCmds[i] = pipe.MemoryUsage(ctx, key)
+			if lfu {
+				freqCmds[i] = pipe.ObjectFreq(ctx, key)
+			}
+		}
+		return nil
+	})
+	// 扫描过程中key可能已被删除，忽略redis.Nil错误
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	keyStats := make([]*dto.KeyStat, 0, len(keys))
+	// 与keyStats一一对应的获取元素数量命令
+	lenCmds := make([]*redis.IntCmd, 0, len(keys))
+	_, err = cmd.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			keyType := typeCmds[i].Val()
+			if keyType == "" || keyType == "none" {
+				continue
+			}
+			ks := &dto.KeyStat{Key: key, Type: keyType, Memory: memCmds[i].Val()}
+			if lfu {
+				ks.Freq = freqCmds[i].Val()
+			}
+			keyStats = append(keyStats, ks)
+
+			var lenCmd *redis.IntCmd
+			switch keyType {
+			case "string":
+				lenCmd = pipe.StrLen(ctx, key)
+			case "list":
+				lenCmd = pipe.LLen(ctx, key)
+			case "hash":
+				lenCmd = pipe.HLen(ctx, key)
+			case "set":
+				lenCmd = pipe.SCard(ctx, key)
+			case "zset":
+				lenCmd = pipe.ZCard(ctx, key)
+			case "stream":
+				lenCmd = pipe.XLen(ctx, key)
+			}
+			lenCmds = append(lenCmds, lenCmd)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	for i, ks := range keyStats {
+		if lenCmds[i] != nil {
+			ks.Len = lenCmds[i].Val()
+		}
+	}
+	return keyStats, nil
+}
+
+// 获取key的前缀，如key为a:b:c，分隔符为:，层级为2，则前缀为a:b:*
+func keyPrefix(key string, separator string, depth int) string {
+	parts := strings.Split(key, separator)
+	if len(parts) <= depth {
+		// 不包含分隔符的key统一归类
+		if len(parts) == 1 {
+			return noKeyPrefix
+		}
+		parts = parts[:len(parts)-1]
+	} else {
+		parts = parts[:depth]
+	}
+	return strings.Join(parts, separator) + separator + "*"
+}
+
+// 基于小顶堆保留值最大的前N个key
+type keyStatTopN struct {
+	n     int
+	val   func(ks *dto.KeyStat) int64
+	items []*dto.KeyStat
+}
+
+func newKeyStatTopN(n int, val func(ks *dto.KeyStat) int64) *keyStatTopN {
+	return &keyStatTopN{n: n, val: val, items: make([]*dto.KeyStat, 0, n)}
+}
+
+func (t *keyStatTopN) Len() int           { return len(t.items) }
+func (t *keyStatTopN) Less(i, j int) bool { return t.val(t.items[i]) < t.val(t.items[j]) }
+func (t *keyStatTopN) Swap(i, j int)      { t.items[i], t.items[j] = t.items[j], t.items[i] }
+func (t *keyStatTopN) Push(x any)         { t.items = append(t.items, x.(*dto.KeyStat)) }
+func (t *keyStatTopN) Pop() any {
+	old := t.items
+	n := len(old)
+	item := old[n-1]
+	t.items = old[:n-1]
+	return item
+}
+
+func (t *keyStatTopN) Add(ks *dto.KeyStat) {
+	if t.val(ks) <= 0 {
+		return
+	}
+	if t.Len() < t.n {
+		heap.Push(t, ks)
+		return
+	}
+	if t.val(ks) > t.val(t.items[0]) {
+		t.items[0] = ks
+		heap.Fix(t, 0)
+	}
+}
+
+// 按值从大到小排序后的结果
+func (t *keyStatTopN) Sorted() []*dto.KeyStat {
+	res := make([]*dto.KeyStat, len(t.items))
+	copy(res, t.items)
+	sort.Slice(res, func(i, j int) bool {
+		return t.val(res[i]) > t.val(res[j])
+	})
+	return res
+}
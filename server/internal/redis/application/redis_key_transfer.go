@@ -0,0 +1,389 @@
+package application
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	flowapp "mayfly-go/internal/flow/application"
+	flowdto "mayfly-go/internal/flow/application/dto"
+	flowentity "mayfly-go/internal/flow/domain/entity"
+	"mayfly-go/internal/redis/application/dto"
+	"mayfly-go/internal/redis/config"
+	"mayfly-go/internal/redis/rdm"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/utils/jsonx"
+	"mayfly-go/pkg/utils/stringx"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisKeyTransfer interface {
+	flowapp.FlowBizHandler
+
+	// CopyKeys 使用DUMP/RESTORE将源redis中匹配的key复制至目标redis，目标redis关联了审批流程则审批通过后执行并返回nil
+	CopyKeys(ctx context.Context, param *dto.CopyKeys) (*dto.KeyTransferRes, error)
+
+	// ExportKeys 将匹配的key以DUMP序列化值的形式写入writer，每行一个key
+	ExportKeys(ctx context.Context, conn *rdm.RedisConn, match string, writer io.Writer) (int, error)
+
+	// ImportKeys 读取ExportKeys导出的内容，并RESTORE至指定redis，redis关联了审批流程则暂存导入文件，审批通过后执行并返回nil
+	ImportKeys(ctx context.Context, param *dto.ImportKeys) (*dto.KeyTransferRes, error)
+}
+
+type redisKeyTransferAppImpl struct {
+	redisApp    Redis            `inject:"RedisApp"`
+	procdefApp  flowapp.Procdef  `inject:"ProcdefApp"`
+	procinstApp flowapp.Procinst `inject:"ProcinstApp"`
+}
+
+var _ (RedisKeyTransfer) = (*redisKeyTransferAppImpl)(nil)
+
+const (
+	scanKeyCount = 500
+
+	keyTransferTypeCopy   = "copy"
+	keyTransferTypeImport = "import"
+)
+
+func (r *redisKeyTransferAppImpl) CopyKeys(ctx context.Context, param *dto.CopyKeys) (*dto.KeyTransferRes, error) {
+	if err := validConflictStrategy(param.ConflictStrategy); err != nil {
+		return nil, err
+	}
+
+	// 复制key为目标redis的写操作，关联了审批流程则需审批通过后执行
+	if procdefId := r.procdefApp.GetProcdefIdByCodePath(ctx, param.TargetConn.Info.CodePath...); procdefId != 0 {
+		return nil, r.startFlow(ctx, procdefId, &dto.KeyTransferBizForm{
+			Type:             keyTransferTypeCopy,
+			SrcId:            param.SrcConn.Info.Id,
+			SrcDb:            param.SrcConn.Info.Db,
+			Id:               param.TargetConn.Info.Id,
+			Db:               param.TargetConn.Info.Db,
+			Match:            param.Match,
+			ConflictStrategy: param.ConflictStrategy,
+			RenameSuffix:     param.RenameSuffix,
+		}, fmt.Sprintf("复制key[%s]至redis[%s-db%d]", param.Match, param.TargetConn.Info.Name, param.TargetConn.Info.Db))
+	}
+	return r.copyKeys(ctx, param)
+}
+
+func (r *redisKeyTransferAppImpl) copyKeys(ctx context.Context, param *dto.CopyKeys) (*dto.KeyTransferRes, error) {
+	srcCmd := param.SrcConn.GetCmdable()
+	res := new(dto.KeyTransferRes)
+	err := scanAllKeys(ctx, param.SrcConn, param.Match, func(keys []string) error {
+		for _, key := range keys {
+			record, err := dumpKey(ctx, srcCmd, key)
+			if err != nil {
+				res.Total++
+				res.AddFail(key, err)
+				continue
+			}
+			// key已过期或被删除
+			if record == nil {
+				continue
+			}
+			restoreKey(ctx, param.TargetConn.GetCmdable(), record, param.ConflictStrategy, param.RenameSuffix, res)
+		}
+		return nil
+	})
+	return res, err
+}
+
+func (r *redisKeyTransferAppImpl) ExportKeys(ctx context.Context, conn *rdm.RedisConn, match string, writer io.Writer) (int, error) {
+	cmd := conn.GetCmdable()
+	encoder := json.NewEncoder(writer)
+	count := 0
+	err := scanAllKeys(ctx, conn, match, func(keys []string) error {
+		for _, key := range keys {
+			record, err := dumpKey(ctx, cmd, key)
+			if err != nil {
+				logx.Warnf("redis导出key[%s]失败: %s", key, err.Error())
+				continue
+			}
+			if record == nil {
+				continue
+			}
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+func (r *redisKeyTransferAppImpl) ImportKeys(ctx context.Context, param *dto.ImportKeys) (*dto.KeyTransferRes, error) {
+	if err := validConflictStrategy(param.ConflictStrategy); err != nil {
+		return nil, err
+	}
+
+	if procdefId := r.procdefApp.GetProcdefIdByCodePath(ctx, param.Conn.Info.CodePath...); procdefId != 0 {
+		// 暂存导入文件，审批通过后再读取导入
+		importFile, err := saveKeyImportFile(param.Reader)
+		if err != nil {
+			return nil, errorx.NewBiz("保存导入文件失败: %s", err.Error())
+		}
+		err = r.startFlow(ctx, procdefId, &dto.KeyTransferBizForm{
+			Type:             keyTransferTypeImport,
+			Id:               param.Conn.Info.Id,
+			Db:               param.Conn.Info.Db,
+			Filename:         param.Filename,
+			ImportFile:       importFile,
+			ConflictStrategy: param.ConflictStrategy,
+			RenameSuffix:     param.RenameSuffix,
+		}, fmt.Sprintf("导入key文件[%s]至redis[%s-db%d]", param.Filename, param.Conn.Info.Name, param.Conn.Info.Db))
+		if err != nil {
+			os.Remove(keyImportFilePath(importFile))
+		}
+		return nil, err
+	}
+	return r.importKeys(ctx, param)
+}
+
+func (r *redisKeyTransferAppImpl) importKeys(ctx context.Context, param *dto.ImportKeys) (*dto.KeyTransferRes, error) {
+	cmd := param.Conn.GetCmdable()
+	res := new(dto.KeyTransferRes)
+
+	scanner := bufio.NewScanner(param.Reader)
+	// 单个key序列化后的值可能较大
+	scanner.Buffer(make([]byte, 0, 64*1024), 512*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		record := new(dto.KeyDumpRecord)
+		if err := json.Unmarshal(line, record); err != nil {
+			return res, errorx.NewBiz("导入文件格式错误: %s", err.Error())
+		}
+		restoreKey(ctx, cmd, record, param.ConflictStrategy, param.RenameSuffix, res)
+	}
+	if err := scanner.Err(); err != nil {
+		return res, errorx.NewBiz("读取导入文件失败: %s", err.Error())
+	}
+	return res, nil
+}
+
+func (r *redisKeyTransferAppImpl) FlowBizHandle(ctx context.Context, bizHandleParam *flowapp.BizHandleParam) (any, error) {
+	procinstStatus := bizHandleParam.ProcinstStatus
+	logx.Debugf("RedisKeyTransfer FlowBizHandle -> bizKey: %s, procinstStatus: %s", bizHandleParam.BizKey, flowentity.ProcinstStatusEnum.GetDesc(procinstStatus))
+	// 流程挂起可重新提交，暂存的导入文件需保留
+	if procinstStatus == flowentity.ProcinstStatusSuspended {
+		return nil, nil
+	}
+
+	bizForm, err := jsonx.To(bizHandleParam.BizForm, new(dto.KeyTransferBizForm))
+	if err != nil {
+		return nil, errorx.NewBiz("业务表单信息解析失败: %s", err.Error())
+	}
+	if bizForm.ImportFile != "" {
+		defer os.Remove(keyImportFilePath(bizForm.ImportFile))
+	}
+	if procinstStatus != flowentity.ProcinstStatusCompleted {
+		return nil, nil
+	}
+
+	conn, err := r.redisApp.GetRedisConn(bizForm.Id, bizForm.Db)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *dto.KeyTransferRes
+	switch bizForm.Type {
+	case keyTransferTypeCopy:
+		srcConn, err := r.redisApp.GetRedisConn(bizForm.SrcId, bizForm.SrcDb)
+		if err != nil {
+			return nil, err
+		}
+		res, err = r.copyKeys(ctx, &dto.CopyKeys{
+			SrcConn:          srcConn,
+			TargetConn:       conn,
+			Match:            bizForm.Match,
+			ConflictStrategy: bizForm.ConflictStrategy,
+			RenameSuffix:     bizForm.RenameSuffix,
+		})
+		if err != nil {
+			return nil, err
+		}
+	case keyTransferTypeImport:
+		file, err := os.Open(keyImportFilePath(bizForm.ImportFile))
+		if err != nil {
+			return nil, errorx.NewBiz("导入文件不存在: %s", err.Error())
+		}
+		defer file.Close()
+		res, err = r.importKeys(ctx, &dto.ImportKeys{
+			Conn:             conn,
+			Reader:           file,
+			Filename:         bizForm.Filename,
+			ConflictStrategy: bizForm.ConflictStrategy,
+			RenameSuffix:     bizForm.RenameSuffix,
+		})
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errorx.NewBiz("不支持的操作类型: %s", bizForm.Type)
+	}
+	return jsonx.ToStr(res), nil
+}
+
+func (r *redisKeyTransferAppImpl) startFlow(ctx context.Context, procdefId uint64, bizForm *dto.KeyTransferBizForm, remark string) error {
+	_, err := r.procinstApp.StartProc(ctx, procdefId, &flowdto.StarProc{
+		BizType: RedisKeyTransferFlowBizType,
+		BizKey:  stringx.Rand(24),
+		BizForm: jsonx.ToStr(bizForm),
+		Remark:  remark,
+	})
+	return err
+}
+
+// 暂存待审批的导入文件，返回文件名。审批可能由其他节点处理，故暂存路径需为各节点共享的存储
+func saveKeyImportFile(reader io.Reader) (string, error) {
+	if err := os.MkdirAll(config.GetRedis().KeyImportPath, os.ModePerm); err != nil {
+		return "", err
+	}
+	fileName := stringx.Rand(24) + ".rkeys"
+	filePath := keyImportFilePath(fileName)
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err := io.Copy(file, reader); err != nil {
+		os.Remove(filePath)
+		return "", err
+	}
+	return fileName, nil
+}
+
+// 获取暂存导入文件的完整路径
+func keyImportFilePath(fileName string) string {
+	return filepath.Join(config.GetRedis().KeyImportPath, filepath.Base(fileName))
+}
+
+func validConflictStrategy(strategy dto.KeyConflictStrategy) error {
+	switch strategy {
+	case dto.KeyConflictSkip, dto.KeyConflictReplace, dto.KeyConflictRename:
+		return nil
+	}
+	return errorx.NewBiz("不支持的key冲突处理策略: %s", strategy)
+}
+
+// 遍历所有匹配的key，集群模式则遍历所有master节点
+func scanAllKeys(ctx context.Context, conn *rdm.RedisConn, match string, handler func(keys []string) error) error {
+	if match == "" {
+		match = "*"
+	}
+
+	mode := conn.Info.Mode
+	if mode == "" || mode == rdm.StandaloneMode || mode == rdm.SentinelMode {
+		return scanClientKeys(ctx, conn.Cli, match, handler)
+	}
+	if mode == rdm.ClusterMode {
+		// 遍历节点的回调函数为异步调用，需加锁保证handler串行执行
+		mu := &sync.Mutex{}
+		return conn.ClusterCli.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			return scanClientKeys(ctx, client, match, func(keys []string) error {
+				mu.Lock()
+				defer mu.Unlock()
+				return handler(keys)
+			})
+		})
+	}
+	return errorx.NewBiz("redis mode error")
+}
+
+func scanClientKeys(ctx context.Context, cli *redis.Client, match string, handler func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, nextCursor, err := cli.Scan(ctx, cursor, match, scanKeyCount).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := handler(keys); err != nil {
+				return err
+			}
+		}
+		if nextCursor == 0 {
+			return nil
+		}
+		cursor = nextCursor
+	}
+}
+
+// 获取key的序列化值及剩余过期时间，key不存在则返回nil
+func dumpKey(ctx context.Context, cmd redis.Cmdable, key string) (*dto.KeyDumpRecord, error) {
+	value, err := cmd.Dump(ctx, key).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ttl, err := cmd.PTTL(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	// -2: key不存在; -1: 未设置过期时间
+	if ttl == -2 {
+		return nil, nil
+	}
+	var ttlMs int64
+	if ttl > 0 {
+		ttlMs = ttl.Milliseconds()
+	}
+	return &dto.KeyDumpRecord{Key: key, Ttl: ttlMs, Value: []byte(value)}, nil
+}
+
+// 根据冲突处理策略将key还原至目标redis
+func restoreKey(ctx context.Context, cmd redis.Cmdable, record *dto.KeyDumpRecord, strategy dto.KeyConflictStrategy, renameSuffix string, res *dto.KeyTransferRes) {
+	res.Total++
+	key := record.Key
+	ttl := time.Duration(record.Ttl) * time.Millisecond
+
+	if strategy == dto.KeyConflictReplace {
+		if err := cmd.RestoreReplace(ctx, key, ttl, string(record.Value)).Err(); err != nil {
+			res.AddFail(key, err)
+			return
+		}
+		res.Success++
+		return
+	}
+
+	exist, err := cmd.Exists(ctx, key).Result()
+	if err != nil {
+		res.AddFail(key, err)
+		return
+	}
+	if exist > 0 {
+		if strategy == dto.KeyConflictSkip {
+			res.Skip++
+			return
+		}
+
+		if renameSuffix == "" {
+			renameSuffix = "_copy"
+		}
+		key = key + renameSuffix
+		// 重命名后的key依然存在，则跳过
+		if exist, err = cmd.Exists(ctx, key).Result(); err != nil || exist > 0 {
+			res.Skip++
+			return
+		}
+	}
+
+	if err := cmd.Restore(ctx, key, ttl, string(record.Value)).Err(); err != nil {
+		res.AddFail(key, err)
+		return
+	}
+	res.Success++
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	sysapp "mayfly-go/internal/sys/application"
+	"path/filepath"
+)
+
+const (
+	ConfigKeyRedis string = "RedisConfig" // redis相关配置
+)
+
+type Redis struct {
+	KeyImportPath string // 待审批的key导入文件暂存路径，审批可能由其他节点处理，多节点部署时需为各节点共享的存储
+}
+
+// 获取redis相关配置
+func GetRedis() *Redis {
+	c := sysapp.GetConfigApp().GetConfig(ConfigKeyRedis)
+	jm := c.GetJsonMap()
+
+	rc := new(Redis)
+
+	keyImportPath := jm["keyImportPath"]
+	if keyImportPath == "" {
+		keyImportPath = "./redis/key-import"
+	}
+	rc.KeyImportPath = filepath.Join(keyImportPath)
+	return rc
+}
@@ -11,3 +11,8 @@ type RedisQuery struct {
 	Codes   []string
 	TagPath string `form:"tagPath"`
 }
+
+type RedisKeyAnalysisQuery struct {
+	RedisId uint64 `json:"redisId" form:"redisId"`
+	Db      *int   `json:"db" form:"db"`
+}
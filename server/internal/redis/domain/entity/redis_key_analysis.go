@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"mayfly-go/pkg/model"
+	"time"
+)
+
+// redis key分析记录，用于分析大key、热key以及key前缀内存分布
+type RedisKeyAnalysis struct {
+	model.Model
+
+	RedisId     uint64     `json:"redisId"`
+	Db          int        `json:"db"`
+	Match       string     `json:"match"`       // key匹配规则
+	Status      int8       `json:"status"`      // 状态 1运行中 2成功 -1失败
+	KeyCount    int64      `json:"keyCount"`    // 扫描的key数量
+	TotalMemory int64      `json:"totalMemory"` // 扫描的key占用内存总数(字节)
+	Result      string     `json:"result"`      // 分析结果json
+	ErrMsg      string     `json:"errMsg"`
+	EndTime     *time.Time `json:"endTime"`
+}
+
+func (a *RedisKeyAnalysis) TableName() string {
+	return "t_redis_key_analysis"
+}
+
+const (
+	RedisKeyAnalysisStatusRunning int8 = 1
+	RedisKeyAnalysisStatusSuccess int8 = 2
+	RedisKeyAnalysisStatusFail    int8 = -1
+)
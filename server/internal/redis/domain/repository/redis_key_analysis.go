@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"mayfly-go/internal/redis/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type RedisKeyAnalysis interface {
+	base.Repo[*entity.RedisKeyAnalysis]
+
+	GetPageList(condition *entity.RedisKeyAnalysisQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+}
@@ -6,4 +6,5 @@ import (
 
 func InitIoc() {
 	ioc.Register(newRedisRepo(), ioc.WithComponentName("RedisRepo"))
+	ioc.Register(newRedisKeyAnalysisRepo(), ioc.WithComponentName("RedisKeyAnalysisRepo"))
 }
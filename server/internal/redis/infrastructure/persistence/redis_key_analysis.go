@@ -0,0 +1,24 @@
+package persistence
+
+import (
+	"mayfly-go/internal/redis/domain/entity"
+	"mayfly-go/internal/redis/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type redisKeyAnalysisRepoImpl struct {
+	base.RepoImpl[*entity.RedisKeyAnalysis]
+}
+
+func newRedisKeyAnalysisRepo() repository.RedisKeyAnalysis {
+	return &redisKeyAnalysisRepoImpl{base.RepoImpl[*entity.RedisKeyAnalysis]{M: new(entity.RedisKeyAnalysis)}}
+}
+
+func (r *redisKeyAnalysisRepoImpl) GetPageList(condition *entity.RedisKeyAnalysisQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
+	qd := model.NewCond().
+		Eq("redis_id", condition.RedisId).
+		Eq("db", condition.Db).
+		OrderBy(orderBy...)
+	return r.PageByCondToAny(qd, pageParam, toEntity)
+}
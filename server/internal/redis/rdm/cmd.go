@@ -73,7 +73,10 @@ var writeCmd = map[string]string{
 	"SUNIONSTORE":      "SUNIONSTORE destination key [key ...]",
 	"SWAPDB":           "SWAPDB index1 index2",
 	"UNLINK":           "UNLINK key [key ...]",
+	"XACK":             "XACK key group ID [ID ...]",
 	"XADD":             "XADD key ID field string [field string ...]",
+	"XAUTOCLAIM":       "XAUTOCLAIM key group consumer min-idle-time start [COUNT count] [JUSTID]",
+	"XCLAIM":           "XCLAIM key group consumer min-idle-time ID [ID ...]",
 	"XDEL":             "XDEL key ID [ID ...]",
 	"XGROUP":           "XGROUP CREATE key groupname id|$ [MKSTREAM], XGROUP CREATECONSUMER key groupname consumername, XGROUP DELCONSUMER key groupname consumername, XGROUP DESTROY key groupname, XGROUP SETID key groupname id|$",
 	"XTRIM":            "XTRIM key MAXLEN [~] count",
@@ -33,6 +33,22 @@ func (r *RedisConn) Scan(cursor uint64, match string, count int64) ([]string, ui
 	return r.GetCmdable().Scan(context.Background(), cursor, match, count).Result()
 }
 
+// 订阅指定频道
+func (r *RedisConn) Subscribe(ctx context.Context, channels ...string) *redis.PubSub {
+	if r.Info.Mode == ClusterMode {
+		return r.ClusterCli.Subscribe(ctx, channels...)
+	}
+	return r.Cli.Subscribe(ctx, channels...)
+}
+
+// 订阅匹配模式的频道
+func (r *RedisConn) PSubscribe(ctx context.Context, patterns ...string) *redis.PubSub {
+	if r.Info.Mode == ClusterMode {
+		return r.ClusterCli.PSubscribe(ctx, patterns...)
+	}
+	return r.Cli.PSubscribe(ctx, patterns...)
+}
+
 // 执行redis命令
 // 如: SET str value命令则args为['SET', 'str', 'val']
 func (r *RedisConn) RunCmd(ctx context.Context, args ...any) (any, error) {
@@ -0,0 +1,185 @@
+package rdm
+
+import (
+	"context"
+	"errors"
+	"mayfly-go/pkg/logx"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 控制台推送至客户端的消息类型
+const (
+	ConsoleMsgTypeMessage = "message" // 订阅频道收到的消息
+	ConsoleMsgTypeStream  = "stream"  // stream新增的消息
+	ConsoleMsgTypeError   = "error"
+	ConsoleMsgTypeInfo    = "info"
+)
+
+// 推送至客户端的消息
+type ConsoleMsg struct {
+	Type    string         `json:"type"`
+	Channel string         `json:"channel,omitempty"`
+	Pattern string         `json:"pattern,omitempty"`
+	Payload string         `json:"payload,omitempty"`
+	Stream  string         `json:"stream,omitempty"`
+	Id      string         `json:"id,omitempty"`
+	Values  map[string]any `json:"values,omitempty"`
+	Msg     string         `json:"msg,omitempty"`
+}
+
+// pub/sub及stream控制台会话，订阅的消息与stream新增的消息通过send函数推送
+type PubSubSession struct {
+	conn *RedisConn
+	send func(msg *ConsoleMsg)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	pubsub  *redis.PubSub
+	streams map[string]*streamTail // 正在tail的stream
+}
+
+// stream读取任务
+type streamTail struct {
+	cancel context.CancelFunc
+}
+
+func NewPubSubSession(conn *RedisConn, send func(msg *ConsoleMsg)) *PubSubSession {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &PubSubSession{
+		conn:    conn,
+		send:    send,
+		ctx:     ctx,
+		cancel:  cancel,
+		streams: make(map[string]*streamTail),
+	}
+}
+
+// 推送消息至客户端
+func (s *PubSubSession) Send(msg *ConsoleMsg) {
+	s.send(msg)
+}
+
+// 订阅频道
+func (s *PubSubSession) Subscribe(channels ...string) error {
+	return s.getPubSub().Subscribe(s.ctx, channels...)
+}
+
+// 按模式订阅频道
+func (s *PubSubSession) PSubscribe(patterns ...string) error {
+	return s.getPubSub().PSubscribe(s.ctx, patterns...)
+}
+
+// 取消订阅频道，channels为空则取消所有订阅
+func (s *PubSubSession) Unsubscribe(channels ...string) error {
+	return s.getPubSub().Unsubscribe(s.ctx, channels...)
+}
+
+// 取消模式订阅，patterns为空则取消所有模式订阅
+func (s *PubSubSession) PUnsubscribe(patterns ...string) error {
+	return s.getPubSub().PUnsubscribe(s.ctx, patterns...)
+}
+
+// 从指定id开始持续读取stream新增的消息，id为空则只读取新消息
+func (s *PubSubSession) TailStream(stream string, id string) {
+	if id == "" {
+		id = "$"
+	}
+
+	s.mu.Lock()
+	if tail := s.streams[stream]; tail != nil {
+		tail.cancel()
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	tail := &streamTail{cancel: cancel}
+	s.streams[stream] = tail
+	s.mu.Unlock()
+
+	go func() {
+		cmd := s.conn.GetCmdable()
+		for {
+			res, err := cmd.XRead(ctx, &redis.XReadArgs{
+				Streams: []string{stream, id},
+				Count:   100,
+				Block:   5 * time.Second,
+			}).Result()
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				if errors.Is(err, redis.Nil) {
+					continue
+				}
+				s.send(&ConsoleMsg{Type: ConsoleMsgTypeError, Stream: stream, Msg: err.Error()})
+				// 仅停止本次的读取，避免误停该stream新发起的读取
+				s.stopStreamTail(stream, tail)
+				return
+			}
+
+			for _, xstream := range res {
+				for _, xmsg := range xstream.Messages {
+					id = xmsg.ID
+					s.send(&ConsoleMsg{Type: ConsoleMsgTypeStream, Stream: xstream.Stream, Id: xmsg.ID, Values: xmsg.Values})
+				}
+			}
+		}
+	}()
+}
+
+// 停止读取stream
+func (s *PubSubSession) StopStream(stream string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tail := s.streams[stream]; tail != nil {
+		tail.cancel()
+		delete(s.streams, stream)
+	}
+}
+
+// 停止指定的stream读取任务，若该stream已被新的读取任务替换则只取消该任务
+func (s *PubSubSession) stopStreamTail(stream string, tail *streamTail) {
+	tail.cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.streams[stream] == tail {
+		delete(s.streams, stream)
+	}
+}
+
+// 关闭会话，取消所有订阅及stream读取
+func (s *PubSubSession) Close() {
+	s.cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pubsub != nil {
+		if err := s.pubsub.Close(); err != nil {
+			logx.Warnf("关闭redis pubsub失败: %s", err.Error())
+		}
+		s.pubsub = nil
+	}
+	s.streams = make(map[string]*streamTail)
+}
+
+// 获取pubsub，不存在则创建并开始接收订阅消息
+func (s *PubSubSession) getPubSub() *redis.PubSub {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pubsub != nil {
+		return s.pubsub
+	}
+
+	s.pubsub = s.conn.Subscribe(s.ctx)
+	msgChan := s.pubsub.Channel()
+	go func() {
+		for msg := range msgChan {
+			s.send(&ConsoleMsg{Type: ConsoleMsgTypeMessage, Channel: msg.Channel, Pattern: msg.Pattern, Payload: msg.Payload})
+		}
+	}()
+	return s.pubsub
+}
@@ -44,7 +44,34 @@ func InitRedisRouter(router *gin.RouterGroup) {
 		req.NewGet(":id/:db/key-ttl", rs.TtlKey),
 
 		req.NewGet(":id/:db/key-memuse", rs.MemoryUsage),
+
+		req.NewPost(":id/:db/keys/copy", rs.CopyKeys).Log(req.NewLogSave("redis-复制key")).RequiredPermissionCode("redis:data:save"),
+
+		req.NewGet(":id/:db/keys/export", rs.ExportKeys).Log(req.NewLogSave("redis-导出key")).NoRes(),
+
+		req.NewPost(":id/:db/keys/import", rs.ImportKeys).Log(req.NewLogSave("redis-导入key")).RequiredPermissionCode("redis:data:save"),
+
+		req.NewPost(":id/:db/key-analysis", rs.StartKeyAnalysis).Log(req.NewLogSave("redis-开始key分析")),
+
+		req.NewGet(":id/key-analysis", rs.KeyAnalysisList),
+
+		req.NewGet(":id/key-analysis/:analysisId", rs.KeyAnalysisDetail),
+
+		req.NewDelete(":id/key-analysis/:analysisId", rs.DeleteKeyAnalysis).Log(req.NewLogSave("redis-删除key分析记录")),
+
+		req.NewGet(":id/:db/stream/groups", rs.StreamGroups),
+
+		req.NewGet(":id/:db/stream/consumers", rs.StreamConsumers),
+
+		req.NewGet(":id/:db/stream/pending", rs.StreamPending),
+
+		req.NewPost(":id/:db/stream/ack", rs.StreamAck).Log(req.NewLogSave("redis-stream消息确认")),
+
+		req.NewPost(":id/:db/stream/claim", rs.StreamClaim).Log(req.NewLogSave("redis-stream消息转移")),
 	}
 
 	req.BatchSetGroup(redis, reqs[:])
+
+	// pub/sub及stream控制台
+	redis.GET(":id/:db/pubsub", rs.WsPubSub)
 }
@@ -37,6 +37,9 @@ type Role interface {
 	// 获取账号关联角色
 	GetAccountRoles(accountId uint64) ([]*entity.AccountRole, error)
 
+	// 获取角色关联的账号id
+	GetRoleAccountIds(roleId uint64) ([]uint64, error)
+
 	// 获取角色关联的用户信息
 	GetRoleAccountPage(condition *entity.RoleAccountQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
 }
@@ -144,6 +147,14 @@ func (m *roleAppImpl) GetAccountRoles(accountId uint64) ([]*entity.AccountRole,
 	return m.accountRoleRepo.SelectByCond(&entity.AccountRole{AccountId: accountId})
 }
 
+func (m *roleAppImpl) GetRoleAccountIds(roleId uint64) ([]uint64, error) {
+	accountRoles, err := m.accountRoleRepo.SelectByCond(&entity.AccountRole{RoleId: roleId})
+	if err != nil {
+		return nil, err
+	}
+	return collx.ArrayMap(accountRoles, func(ar *entity.AccountRole) uint64 { return ar.AccountId }), nil
+}
+
 func (m *roleAppImpl) GetRoleAccountPage(condition *entity.RoleAccountQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
 	return m.accountRoleRepo.GetPageList(condition, pageParam, toEntity, orderBy...)
 }
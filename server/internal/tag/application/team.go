@@ -12,6 +12,7 @@ import (
 	"mayfly-go/pkg/errorx"
 	"mayfly-go/pkg/logx"
 	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/collx"
 )
 
 type Team interface {
@@ -35,6 +36,9 @@ type Team interface {
 
 	IsExistMember(teamId, accounId uint64) bool
 
+	// 获取团队成员账号id
+	GetMemberAccountIds(teamId uint64) ([]uint64, error)
+
 	DeleteTag(tx context.Context, teamId, tagId uint64) error
 }
 
@@ -131,6 +135,14 @@ func (p *teamAppImpl) IsExistMember(teamId, accounId uint64) bool {
 	return p.teamMemberRepo.IsExist(teamId, accounId)
 }
 
+func (p *teamAppImpl) GetMemberAccountIds(teamId uint64) ([]uint64, error) {
+	members, err := p.teamMemberRepo.SelectByCond(&entity.TeamMember{TeamId: teamId})
+	if err != nil {
+		return nil, err
+	}
+	return collx.ArrayMap(members, func(member *entity.TeamMember) uint64 { return member.AccountId }), nil
+}
+
 //--------------- 标签相关接口 ---------------
 
 // 删除关联标签信息
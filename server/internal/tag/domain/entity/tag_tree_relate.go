@@ -18,6 +18,9 @@ const (
 	TagRelateTypeMachineCmd     TagRelateType = 2 // 关联机器命令配置
 	TagRelateTypeMachineCronJob TagRelateType = 3 // 关联机器定时任务配置
 	TagRelateTypeFlowDef        TagRelateType = 4 // 关联流程定义
+	TagRelateTypeMachineAlert   TagRelateType = 5 // 关联机器监控告警规则
+	TagRelateTypeDbDataMask     TagRelateType = 6 // 关联数据库数据脱敏规则
+	TagRelateTypeDbSqlReview    TagRelateType = 7 // 关联数据库sql审核规则
 )
 
 // 关联标签信息，如果要实现填充关联标签信息，则结构体需要实现该接口
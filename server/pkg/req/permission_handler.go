@@ -71,6 +71,14 @@ func PermissionHandler(rc *Ctx) error {
 	return nil
 }
 
+// 校验用户是否拥有指定权限code
+func HasPermissionCode(userId uint64, code string) bool {
+	if permissionCodeRegistry == nil {
+		return false
+	}
+	return permissionCodeRegistry.HasCode(userId, code)
+}
+
 // 保存用户权限code
 func SavePermissionCodes(userId uint64, codes []string) {
 	permissionCodeRegistry.SaveCodes(userId, codes)
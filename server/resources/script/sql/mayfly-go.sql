@@ -83,6 +83,89 @@ CREATE TABLE `t_db_transfer_task` (
   PRIMARY KEY (`id`)
 )  COMMENT='数据库迁移任务表';
 
+-- ----------------------------
+-- Table structure for t_db_data_mask_rule
+-- ----------------------------
+DROP TABLE IF EXISTS `t_db_data_mask_rule`;
+CREATE TABLE `t_db_data_mask_rule` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(100) DEFAULT NULL COMMENT '名称',
+  `db` varchar(200) DEFAULT NULL COMMENT '库名，为空则匹配所有库',
+  `table` varchar(200) DEFAULT NULL COMMENT '表名，为空则匹配所有表',
+  `columns` varchar(1000) NOT NULL COMMENT '需要脱敏的列名',
+  `mask_type` varchar(32) NOT NULL COMMENT '脱敏方式 phone、idcard、email、regex、hash、full',
+  `pattern` varchar(500) DEFAULT NULL COMMENT '正则脱敏时匹配的正则表达式',
+  `replacement` varchar(255) DEFAULT NULL COMMENT '正则脱敏时的替换内容',
+  `row_column` varchar(200) DEFAULT NULL COMMENT '行级脱敏条件列',
+  `row_pattern` varchar(500) DEFAULT NULL COMMENT '行级脱敏条件列值需匹配的正则表达式',
+  `exempt_role_ids` varchar(500) DEFAULT NULL COMMENT '豁免脱敏的角色id',
+  `status` tinyint(4) DEFAULT NULL COMMENT '状态 1启用 -1禁用',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `create_time` datetime NOT NULL,
+  `creator_id` bigint(20) NOT NULL,
+  `creator` varchar(36) NOT NULL,
+  `update_time` datetime NOT NULL,
+  `modifier_id` bigint(20) NOT NULL,
+  `modifier` varchar(36) NOT NULL,
+  `is_deleted` tinyint(4) DEFAULT '0',
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='数据库数据脱敏规则';
+
+-- ----------------------------
+-- Table structure for t_db_query_job
+-- ----------------------------
+DROP TABLE IF EXISTS `t_db_query_job`;
+CREATE TABLE `t_db_query_job` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `db_id` bigint(20) NOT NULL COMMENT '数据库id',
+  `db` varchar(150) NOT NULL COMMENT '数据库名',
+  `sql` text NOT NULL COMMENT '查询sql',
+  `format` varchar(16) NOT NULL COMMENT '导出文件格式 csv、xlsx、json',
+  `status` tinyint(4) NOT NULL COMMENT '状态 1执行中 2成功 -1失败 -2已取消',
+  `row_count` bigint(20) DEFAULT NULL COMMENT '导出行数',
+  `file_name` varchar(255) DEFAULT NULL COMMENT '导出文件名',
+  `file_path` varchar(500) DEFAULT NULL COMMENT '导出文件存储路径',
+  `file_size` bigint(20) DEFAULT NULL COMMENT '导出文件大小',
+  `err_msg` varchar(2000) DEFAULT NULL COMMENT '执行失败信息',
+  `end_time` datetime DEFAULT NULL COMMENT '执行结束时间',
+  `expire_time` datetime DEFAULT NULL COMMENT '导出文件过期时间',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `create_time` datetime NOT NULL,
+  `creator_id` bigint(20) NOT NULL,
+  `creator` varchar(36) NOT NULL,
+  `update_time` datetime NOT NULL,
+  `modifier_id` bigint(20) NOT NULL,
+  `modifier` varchar(36) NOT NULL,
+  `is_deleted` tinyint(4) DEFAULT '0',
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_creator_id` (`creator_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='数据库异步查询任务';
+
+-- ----------------------------
+-- Table structure for t_db_sql_review_rule
+-- ----------------------------
+DROP TABLE IF EXISTS `t_db_sql_review_rule`;
+CREATE TABLE `t_db_sql_review_rule` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(100) DEFAULT NULL COMMENT '名称',
+  `rule_type` varchar(32) NOT NULL COMMENT '规则类型 noSelectStar、ddlRequireComment、noImplicitConversion、maxAffectedRows、changeWindow',
+  `level` varchar(16) NOT NULL COMMENT '处理级别 error禁止执行 warn警告',
+  `param` varchar(255) DEFAULT NULL COMMENT '规则参数，如最大影响行数、变更窗口时间段',
+  `status` tinyint(4) DEFAULT NULL COMMENT '状态 1启用 -1禁用',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `create_time` datetime NOT NULL,
+  `creator_id` bigint(20) NOT NULL,
+  `creator` varchar(36) NOT NULL,
+  `update_time` datetime NOT NULL,
+  `modifier_id` bigint(20) NOT NULL,
+  `modifier` varchar(36) NOT NULL,
+  `is_deleted` tinyint(4) DEFAULT '0',
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='数据库sql审核规则';
+
 -- ----------------------------
 -- Table structure for t_db_sql
 -- ----------------------------
@@ -121,6 +204,7 @@ CREATE TABLE `t_db_sql_exec` (
   `status` tinyint DEFAULT NULL COMMENT '执行状态',
   `flow_biz_key` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT NULL COMMENT '流程关联的业务key',
   `res` varchar(1000) COLLATE utf8mb4_bin DEFAULT NULL COMMENT '执行结果',
+  `rollback_id` bigint(20) NOT NULL DEFAULT 0 COMMENT '回滚该记录的sql执行记录id',
   `create_time` datetime NOT NULL,
   `creator` varchar(36) NOT NULL,
   `creator_id` bigint(20) NOT NULL,
@@ -388,6 +472,30 @@ CREATE TABLE `t_machine_file` (
 BEGIN;
 COMMIT;
 
+-- ----------------------------
+-- Table structure for t_machine_alert_rule
+-- ----------------------------
+DROP TABLE IF EXISTS `t_machine_alert_rule`;
+CREATE TABLE `t_machine_alert_rule` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(100) DEFAULT NULL COMMENT '名称',
+  `metric` varchar(32) NOT NULL COMMENT '告警指标 cpu、mem、disk、load1',
+  `threshold` float(255,2) NOT NULL COMMENT '阈值',
+  `duration` int(11) DEFAULT 0 COMMENT '持续时间(分钟)',
+  `receivers` varchar(500) DEFAULT NULL COMMENT '告警接收人用户名',
+  `status` tinyint(4) DEFAULT NULL COMMENT '状态 1启用 -1禁用',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `create_time` datetime NOT NULL,
+  `creator_id` bigint(20) NOT NULL,
+  `creator` varchar(36) NOT NULL,
+  `update_time` datetime NOT NULL,
+  `modifier_id` bigint(20) NOT NULL,
+  `modifier` varchar(36) NOT NULL,
+  `is_deleted` tinyint(4) DEFAULT '0',
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='机器监控告警规则';
+
 -- ----------------------------
 -- Table structure for t_machine_monitor
 -- ----------------------------
@@ -398,11 +506,18 @@ CREATE TABLE `t_machine_monitor` (
   `cpu_rate` float(255,2) DEFAULT NULL,
   `mem_rate` float(255,2) DEFAULT NULL,
   `sys_load` varchar(32) DEFAULT NULL,
+  `load1` float(255,2) DEFAULT NULL COMMENT '1分钟平均负载',
+  `disk_rate` float(255,2) DEFAULT NULL COMMENT '使用率最高的文件系统使用率',
+  `fs_infos` text COMMENT '各文件系统使用信息',
+  `net_rx_rate` bigint(20) DEFAULT NULL COMMENT '网络接收速率(字节/秒)',
+  `net_tx_rate` bigint(20) DEFAULT NULL COMMENT '网络发送速率(字节/秒)',
   `create_time` datetime NOT NULL,
   `is_deleted` tinyint(8) NOT NULL DEFAULT 0,
   `delete_time` datetime DEFAULT NULL,
-  PRIMARY KEY (`id`)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+  PRIMARY KEY (`id`),
+  KEY `idx_machine_id_create_time` (`machine_id`, `create_time`) USING BTREE,
+  KEY `idx_create_time` (`create_time`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='机器监控记录';
 
 
 -- ----------------------------
@@ -450,6 +565,12 @@ CREATE TABLE `t_machine_cron_job` (
   `status` tinyint DEFAULT NULL COMMENT '状态',
   `save_exec_res_type` tinyint DEFAULT NULL COMMENT '保存执行记录类型',
   `last_exec_time` datetime DEFAULT NULL COMMENT '最后执行时间',
+  `timeout` int DEFAULT 0 COMMENT '单次执行最大时间(秒)，0为不限制',
+  `retry_count` int DEFAULT 0 COMMENT '失败重试次数',
+  `retry_interval` int DEFAULT 0 COMMENT '首次重试间隔(秒)',
+  `skip_if_running` tinyint DEFAULT NULL COMMENT '上次执行未结束时是否跳过 1跳过',
+  `fail_notify_threshold` int DEFAULT 0 COMMENT '连续失败通知阈值',
+  `receivers` varchar(500) DEFAULT NULL COMMENT '失败通知接收人',
   `creator_id` bigint DEFAULT NULL,
   `creator` varchar(32) DEFAULT NULL,
   `modifier_id` bigint DEFAULT NULL,
@@ -474,11 +595,86 @@ CREATE TABLE `t_machine_cron_job_exec` (
   PRIMARY KEY (`id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='机器计划任务执行记录';
 
+DROP TABLE IF EXISTS `t_machine_batch_exec`;
+CREATE TABLE `t_machine_batch_exec` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(100) DEFAULT NULL COMMENT '名称',
+  `script` varchar(5000) DEFAULT NULL COMMENT '执行的命令',
+  `tag_paths` varchar(1000) DEFAULT NULL COMMENT '执行的机器所属标签',
+  `concurrency` int DEFAULT NULL COMMENT '并发执行的机器数',
+  `timeout` int DEFAULT NULL COMMENT '单台机器执行超时时间(秒)',
+  `status` tinyint DEFAULT NULL COMMENT '状态 1执行中 2全部成功 3存在失败',
+  `machine_count` int DEFAULT 0 COMMENT '机器数',
+  `success_count` int DEFAULT 0 COMMENT '成功数',
+  `fail_count` int DEFAULT 0 COMMENT '失败数',
+  `start_time` datetime DEFAULT NULL COMMENT '开始时间',
+  `end_time` datetime DEFAULT NULL COMMENT '结束时间',
+  `create_time` datetime NOT NULL,
+  `creator_id` bigint NOT NULL,
+  `creator` varchar(36) NOT NULL,
+  `update_time` datetime NOT NULL,
+  `modifier_id` bigint NOT NULL,
+  `modifier` varchar(36) NOT NULL,
+  `is_deleted` tinyint DEFAULT '0',
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='机器批量执行任务';
+
+DROP TABLE IF EXISTS `t_machine_batch_exec_res`;
+CREATE TABLE `t_machine_batch_exec_res` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `batch_exec_id` bigint unsigned NOT NULL COMMENT '批量执行任务id',
+  `machine_id` bigint unsigned NOT NULL COMMENT '机器id',
+  `machine_code` varchar(36) DEFAULT NULL COMMENT '机器编号',
+  `machine_name` varchar(100) DEFAULT NULL COMMENT '机器名称',
+  `ip` varchar(100) DEFAULT NULL COMMENT 'ip',
+  `status` tinyint DEFAULT NULL COMMENT '状态 1成功 -1失败 -2超时',
+  `res` text COMMENT '执行输出',
+  `start_time` datetime DEFAULT NULL COMMENT '开始时间',
+  `end_time` datetime DEFAULT NULL COMMENT '结束时间',
+  `is_deleted` tinyint DEFAULT '0',
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_batch_exec_id` (`batch_exec_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='机器批量执行结果';
+
+DROP TABLE IF EXISTS `t_machine_port_forward`;
+CREATE TABLE `t_machine_port_forward` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `machine_id` bigint unsigned NOT NULL COMMENT '机器id',
+  `machine_code` varchar(36) DEFAULT NULL COMMENT '机器编号',
+  `machine_name` varchar(100) DEFAULT NULL COMMENT '机器名称',
+  `auth_cert_name` varchar(100) DEFAULT NULL COMMENT '授权凭证名',
+  `local_port` int DEFAULT NULL COMMENT '服务端监听端口',
+  `remote_host` varchar(100) DEFAULT NULL COMMENT '目标地址',
+  `remote_port` int DEFAULT NULL COMMENT '目标端口',
+  `duration` int DEFAULT NULL COMMENT '有效时长(分钟)',
+  `status` tinyint DEFAULT NULL COMMENT '状态 1转发中 2手动关闭 3到期关闭 -1异常关闭',
+  `expire_time` datetime DEFAULT NULL COMMENT '到期时间',
+  `close_time` datetime DEFAULT NULL COMMENT '关闭时间',
+  `closer` varchar(36) DEFAULT NULL COMMENT '手动关闭人',
+  `conn_count` bigint DEFAULT 0 COMMENT '累计连接数',
+  `bytes_in` bigint DEFAULT 0 COMMENT '客户端发送字节数',
+  `bytes_out` bigint DEFAULT 0 COMMENT '目标地址返回字节数',
+  `create_time` datetime NOT NULL,
+  `creator_id` bigint NOT NULL,
+  `creator` varchar(36) NOT NULL,
+  `update_time` datetime NOT NULL,
+  `modifier_id` bigint NOT NULL,
+  `modifier` varchar(36) NOT NULL,
+  `is_deleted` tinyint DEFAULT '0',
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_creator_id` (`creator_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='机器端口转发记录';
+
 DROP TABLE IF EXISTS `t_machine_term_op`;
 CREATE TABLE `t_machine_term_op` (
   `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT 'id',
   `machine_id` bigint NOT NULL COMMENT '机器id',
+  `protocol` tinyint DEFAULT NULL COMMENT '会话协议 1ssh 2rdp 3vnc',
   `username` varchar(60) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT NULL COMMENT '登录用户名',
+  `target` varchar(255) DEFAULT NULL COMMENT '终端目标，为空则为机器shell，否则为进入的容器',
   `record_file_path` varchar(191) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT NULL COMMENT '终端回放文件路径',
   `exec_cmds` TEXT NULL COMMENT '执行的命令记录',
   `creator_id` bigint unsigned DEFAULT NULL,
@@ -490,6 +686,23 @@ CREATE TABLE `t_machine_term_op` (
   PRIMARY KEY (`id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='机器终端操作记录表';
 
+DROP TABLE IF EXISTS `t_machine_term_op_index`;
+CREATE TABLE `t_machine_term_op_index` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT 'id',
+  `term_op_id` bigint unsigned NOT NULL COMMENT '终端操作记录id',
+  `machine_id` bigint unsigned NOT NULL COMMENT '机器id',
+  `creator` varchar(191) DEFAULT NULL COMMENT '终端操作人',
+  `type` tinyint NOT NULL COMMENT '内容类型 1执行命令 2终端输出',
+  `content` varchar(1000) COLLATE utf8mb4_general_ci DEFAULT NULL COMMENT '命令或输出行内容（不区分大小写，便于检索）',
+  `rec_offset` double DEFAULT 0 COMMENT '距回放开始的秒数',
+  `op_time` datetime DEFAULT NULL COMMENT '命令执行或输出时间',
+  `is_deleted` tinyint DEFAULT '0',
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_term_op_id` (`term_op_id`),
+  KEY `idx_machine_id_op_time` (`machine_id`, `op_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='机器终端操作记录检索索引';
+
 DROP TABLE IF EXISTS `t_machine_cmd_conf`;
 CREATE TABLE `t_machine_cmd_conf` (
   `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
@@ -497,6 +710,7 @@ CREATE TABLE `t_machine_cmd_conf` (
   `cmds` varchar(500) COLLATE utf8_bin DEFAULT NULL COMMENT '命令配置',
   `status` tinyint(4) DEFAULT NULL COMMENT '状态',
   `stratege` varchar(100) COLLATE utf8_bin DEFAULT NULL COMMENT '策略',
+  `receivers` varchar(500) COLLATE utf8_bin DEFAULT NULL COMMENT '告警接收人或审批人',
   `remark` varchar(50) COLLATE utf8_bin DEFAULT NULL COMMENT '备注',
   `create_time` datetime NOT NULL,
   `creator_id` bigint(20) NOT NULL,
@@ -554,6 +768,30 @@ CREATE TABLE `t_redis` (
   PRIMARY KEY (`id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='redis信息';
 
+DROP TABLE IF EXISTS `t_redis_key_analysis`;
+CREATE TABLE `t_redis_key_analysis` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `redis_id` bigint NOT NULL COMMENT 'redis id',
+  `db` int DEFAULT NULL COMMENT '库号',
+  `match` varchar(255) DEFAULT NULL COMMENT 'key匹配规则',
+  `status` tinyint DEFAULT NULL COMMENT '状态 1运行中 2成功 -1失败',
+  `key_count` bigint DEFAULT NULL COMMENT '扫描的key数量',
+  `total_memory` bigint DEFAULT NULL COMMENT '扫描的key占用内存总数(字节)',
+  `result` longtext COMMENT '分析结果',
+  `err_msg` varchar(1000) DEFAULT NULL COMMENT '失败信息',
+  `end_time` datetime DEFAULT NULL COMMENT '结束时间',
+  `creator` varchar(32) DEFAULT NULL,
+  `creator_id` bigint DEFAULT NULL,
+  `create_time` datetime DEFAULT NULL,
+  `modifier` varchar(32) DEFAULT NULL,
+  `modifier_id` bigint DEFAULT NULL,
+  `update_time` datetime DEFAULT NULL,
+  `is_deleted` tinyint NOT NULL DEFAULT 0,
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_redis_id` (`redis_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='redis key分析记录';
+
 
 DROP TABLE IF EXISTS `t_oauth2_account`;
 CREATE TABLE `t_oauth2_account` (
@@ -645,11 +883,12 @@ INSERT INTO `t_sys_config` (name, `key`, params, value, remark, create_time, cre
 INSERT INTO `t_sys_config` (name, `key`, params, value, remark, permission, create_time, creator_id, creator, update_time, modifier_id, modifier, is_deleted, delete_time) VALUES('oauth2登录配置', 'Oauth2Login', '[{"name":"是否启用","model":"enable","placeholder":"是否启用oauth2登录","options":"true,false"},{"name":"名称","model":"name","placeholder":"oauth2名称"},{"name":"Client ID","model":"clientId","placeholder":"Client ID"},{"name":"Client Secret","model":"clientSecret","placeholder":"Client Secret"},{"name":"Authorization URL","model":"authorizationURL","placeholder":"Authorization URL"},{"name":"AccessToken URL","model":"accessTokenURL","placeholder":"AccessToken URL"},{"name":"Redirect URL","model":"redirectURL","placeholder":"本系统地址"},{"name":"Scopes","model":"scopes","placeholder":"Scopes"},{"name":"Resource URL","model":"resourceURL","placeholder":"获取用户信息资源地址"},{"name":"UserIdentifier","model":"userIdentifier","placeholder":"用户唯一标识字段;格式为type:fieldPath(string:username)"},{"name":"是否自动注册","model":"autoRegister","placeholder":"","options":"true,false"}]', '', 'oauth2登录相关配置信息', 'admin,', '2023-07-22 13:58:51', 1, 'admin', '2023-07-22 19:34:37', 1, 'admin', 0, NULL);
 INSERT INTO `t_sys_config` (name, `key`, params, value, remark, permission, create_time, creator_id, creator, update_time, modifier_id, modifier, is_deleted, delete_time) VALUES('ldap登录配置', 'LdapLogin', '[{"name":"是否启用","model":"enable","placeholder":"是否启用","options":"true,false"},{"name":"host","model":"host","placeholder":"host"},{"name":"port","model":"port","placeholder":"port"},{"name":"bindDN","model":"bindDN","placeholder":"LDAP 服务的管理员账号，如: \\"cn=admin,dc=example,dc=com\\""},{"name":"bindPwd","model":"bindPwd","placeholder":"LDAP 服务的管理员密码"},{"name":"baseDN","model":"baseDN","placeholder":"用户所在的 base DN, 如: \\"ou=users,dc=example,dc=com\\""},{"name":"userFilter","model":"userFilter","placeholder":"过滤用户的方式, 如: \\"(uid=%s)、(&(objectClass=organizationalPerson)(uid=%s))\\""},{"name":"uidMap","model":"uidMap","placeholder":"用户id和 LDAP 字段名之间的映射关系,如: cn"},{"name":"udnMap","model":"udnMap","placeholder":"用户姓名(dispalyName)和 LDAP 字段名之间的映射关系,如: displayName"},{"name":"emailMap","model":"emailMap","placeholder":"用户email和 LDAP 字段名之间的映射关系"},{"name":"skipTLSVerify","model":"skipTLSVerify","placeholder":"客户端是否跳过 TLS 证书验证","options":"true,false"},{"name":"安全协议","model":"securityProtocol","placeholder":"安全协议（为Null不使用安全协议），如: StartTLS, LDAPS","options":"Null,StartTLS,LDAPS"}]', '', 'ldap登录相关配置', 'admin,', '2023-08-25 21:47:20', 1, 'admin', '2023-08-25 22:56:07', 1, 'admin', 0, NULL);
 INSERT INTO `t_sys_config` (`name`, `key`, `params`, `value`, `remark`, `permission`, `create_time`, `creator_id`, `creator`, `update_time`, `modifier_id`, `modifier`, `is_deleted`, `delete_time`) VALUES('系统全局样式设置', 'SysStyleConfig', '[{"model":"logoIcon","name":"logo图标","placeholder":"系统logo图标（base64编码, 建议svg格式，不超过10k）","required":false},{"model":"title","name":"菜单栏标题","placeholder":"系统菜单栏标题展示","required":false},{"model":"viceTitle","name":"登录页标题","placeholder":"登录页标题展示","required":false},{"model":"useWatermark","name":"是否启用水印","placeholder":"是否启用系统水印","options":"true,false","required":false},{"model":"watermarkContent","name":"水印补充信息","placeholder":"额外水印信息","required":false}]', '{"title":"mayfly-go","viceTitle":"mayfly-go","logoIcon":"","useWatermark":"true","watermarkContent":""}', '系统icon、标题、水印信息等配置', 'all', '2024-01-04 15:17:18', 1, 'admin', '2024-01-05 09:40:44', 1, 'admin', 0, NULL);
-INSERT INTO t_sys_config ( name, `key`, params, value, remark, permission, create_time, creator_id, creator, update_time, modifier_id, modifier, is_deleted, delete_time) VALUES('机器相关配置', 'MachineConfig', '[{"name":"终端回放存储路径","model":"terminalRecPath","placeholder":"终端回放存储路径"},{"name":"uploadMaxFileSize","model":"uploadMaxFileSize","placeholder":"允许上传的最大文件大小(1MB、2GB等)"},{"model":"termOpSaveDays","name":"终端记录保存时间","placeholder":"终端记录保存时间（单位天）"},{"model":"guacdHost","name":"guacd服务ip","placeholder":"guacd服务ip，默认 127.0.0.1","required":false},{"name":"guacd服务端口","model":"guacdPort","placeholder":"guacd服务端口，默认 4822","required":false},{"model":"guacdFilePath","name":"guacd服务文件存储位置","placeholder":"guacd服务文件存储位置，用于挂载RDP文件夹"},{"name":"guacd服务记录存储位置","model":"guacdRecPath","placeholder":"guacd服务记录存储位置，用于记录rdp操作记录"}]', '{"terminalRecPath":"./rec","uploadMaxFileSize":"1000MB","termOpSaveDays":"30","guacdHost":"","guacdPort":"","guacdFilePath":"./guacd/rdp-file","guacdRecPath":"./guacd/rdp-rec"}', '机器相关配置，如终端回放路径等', 'all', '2023-07-13 16:26:44', 1, 'admin', '2024-04-06 12:25:03', 1, 'admin', 0, NULL);
+INSERT INTO t_sys_config ( name, `key`, params, value, remark, permission, create_time, creator_id, creator, update_time, modifier_id, modifier, is_deleted, delete_time) VALUES('机器相关配置', 'MachineConfig', '[{"name":"终端回放存储路径","model":"terminalRecPath","placeholder":"终端回放存储路径"},{"name":"uploadMaxFileSize","model":"uploadMaxFileSize","placeholder":"允许上传的最大文件大小(1MB、2GB等)"},{"model":"termOpSaveDays","name":"终端记录保存时间","placeholder":"终端记录保存时间（单位天）"},{"model":"guacdHost","name":"guacd服务ip","placeholder":"guacd服务ip，默认 127.0.0.1","required":false},{"name":"guacd服务端口","model":"guacdPort","placeholder":"guacd服务端口，默认 4822","required":false},{"model":"guacdFilePath","name":"guacd服务文件存储位置","placeholder":"guacd服务文件存储位置，用于挂载RDP文件夹"},{"name":"guacd服务记录存储位置","model":"guacdRecPath","placeholder":"guacd服务记录存储位置，用于记录rdp操作记录"},{"model":"monitorSaveDays","name":"监控数据保存时间","placeholder":"机器监控数据保存时间（单位天），默认7天"},{"model":"portForwardBindAddr","name":"端口转发监听地址","placeholder":"端口转发监听地址，默认 127.0.0.1"},{"model":"portForwardRemoteHosts","name":"端口转发允许的目标地址","placeholder":"ip、网段或主机名，多个逗号分隔，*为任意地址，默认只允许机器本地"}]', '{"terminalRecPath":"./rec","uploadMaxFileSize":"1000MB","termOpSaveDays":"30","guacdHost":"","guacdPort":"","guacdFilePath":"./guacd/rdp-file","guacdRecPath":"./guacd/rdp-rec"}', '机器相关配置，如终端回放路径等', 'all', '2023-07-13 16:26:44', 1, 'admin', '2024-04-06 12:25:03', 1, 'admin', 0, NULL);
 INSERT INTO `t_sys_config` (`name`, `key`, `params`, `value`, `remark`, `permission`, `create_time`, `creator_id`, `creator`, `update_time`, `modifier_id`, `modifier`, `is_deleted`, `delete_time`) VALUES('数据库备份恢复', 'DbBackupRestore', '[{"model":"backupPath","name":"备份路径","placeholder":"备份文件存储路径"}]', '{"backupPath":"./db/backup"}', '', 'admin,', '2023-12-29 09:55:26', 1, 'admin', '2023-12-29 15:45:24', 1, 'admin', 0, NULL);
 INSERT INTO `t_sys_config` (`name`, `key`, `params`, `value`, `remark`, `permission`, `create_time`, `creator_id`, `creator`, `update_time`, `modifier_id`, `modifier`, `is_deleted`, `delete_time`) VALUES('Mysql可执行文件', 'MysqlBin', '[{"model":"path","name":"路径","placeholder":"可执行文件路径","required":true},{"model":"mysql","name":"mysql","placeholder":"mysql命令路径(空则为 路径/mysql)","required":false},{"model":"mysqldump","name":"mysqldump","placeholder":"mysqldump命令路径(空则为 路径/mysqldump)","required":false},{"model":"mysqlbinlog","name":"mysqlbinlog","placeholder":"mysqlbinlog命令路径(空则为 路径/mysqlbinlog)","required":false}]', '{"mysql":"","mysqldump":"","mysqlbinlog":"","path":"./db/mysql/bin"}', '', 'admin,', '2023-12-29 10:01:33', 1, 'admin', '2023-12-29 13:34:40', 1, 'admin', 0, NULL);
 INSERT INTO `t_sys_config` (`name`, `key`, `params`, `value`, `remark`, `permission`, `create_time`, `creator_id`, `creator`, `update_time`, `modifier_id`, `modifier`, `is_deleted`, `delete_time`) VALUES('MariaDB可执行文件', 'MariadbBin', '[{"model":"path","name":"路径","placeholder":"可执行文件路径","required":true},{"model":"mysql","name":"mysql","placeholder":"mysql命令路径(空则为 路径/mysql)","required":false},{"model":"mysqldump","name":"mysqldump","placeholder":"mysqldump命令路径(空则为 路径/mysqldump)","required":false},{"model":"mysqlbinlog","name":"mysqlbinlog","placeholder":"mysqlbinlog命令路径(空则为 路径/mysqlbinlog)","required":false}]', '{"mysql":"","mysqldump":"","mysqlbinlog":"","path":"./db/mariadb/bin"}', '', 'admin,', '2023-12-29 10:01:33', 1, 'admin', '2023-12-29 13:34:40', 1, 'admin', 0, NULL);
-INSERT INTO `t_sys_config` (`name`, `key`, `params`, `value`, `remark`, `permission`, `create_time`, `creator_id`, `creator`, `update_time`, `modifier_id`, `modifier`, `is_deleted`, `delete_time`) VALUES('DBMS配置', 'DbmsConfig', '[{"model":"querySqlSave","name":"记录查询sql","placeholder":"是否记录查询类sql","options":"true,false"},{"model":"maxResultSet","name":"最大结果集","placeholder":"允许sql查询的最大结果集数。注: 0=不限制","options":""},{"model":"sqlExecTl","name":"sql执行时间限制","placeholder":"超过该时间（单位：秒），执行将被取消"}]', '{"querySqlSave":"false","maxResultSet":"0","sqlExecTl":"60"}', 'DBMS相关配置', 'admin,', '2024-03-06 13:30:51', 1, 'admin', '2024-03-06 14:07:16', 1, 'admin', 0, NULL);
+INSERT INTO `t_sys_config` (`name`, `key`, `params`, `value`, `remark`, `permission`, `create_time`, `creator_id`, `creator`, `update_time`, `modifier_id`, `modifier`, `is_deleted`, `delete_time`) VALUES('DBMS配置', 'DbmsConfig', '[{"model":"querySqlSave","name":"记录查询sql","placeholder":"是否记录查询类sql","options":"true,false"},{"model":"maxResultSet","name":"最大结果集","placeholder":"允许sql查询的最大结果集数。注: 0=不限制","options":""},{"model":"sqlExecTl","name":"sql执行时间限制","placeholder":"超过该时间（单位：秒），执行将被取消"},{"model":"queryJobPath","name":"异步查询导出路径","placeholder":"异步查询任务导出文件存储路径，默认./db/query-job"},{"model":"queryJobExpireHours","name":"异步查询导出有效期","placeholder":"导出文件有效时间（单位：小时），过期后将被删除，默认24"}]', '{"querySqlSave":"false","maxResultSet":"0","sqlExecTl":"60","queryJobPath":"./db/query-job","queryJobExpireHours":"24"}', 'DBMS相关配置', 'admin,', '2024-03-06 13:30:51', 1, 'admin', '2024-03-06 14:07:16', 1, 'admin', 0, NULL);
+INSERT INTO `t_sys_config` (`name`, `key`, `params`, `value`, `remark`, `permission`, `create_time`, `creator_id`, `creator`, `update_time`, `modifier_id`, `modifier`, `is_deleted`, `delete_time`) VALUES('redis配置', 'RedisConfig', '[{"model":"keyImportPath","name":"key导入文件暂存路径","placeholder":"待审批的key导入文件暂存路径，默认./redis/key-import。多节点部署时需为各节点共享的存储"}]', '{"keyImportPath":"./redis/key-import"}', 'redis相关配置', 'admin,', '2025-10-15 10:00:00', 1, 'admin', '2025-10-15 10:00:00', 1, 'admin', 0, NULL);
 COMMIT;
 
 -- ----------------------------
@@ -728,6 +967,11 @@ INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight
 INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(5, 4, 'Xlqig32x/UGxla231/', 1, 1, '资源管理', 'resources', 9999999, '{"component":"system/resource/ResourceList","icon":"Menu","isKeepAlive":true,"routeName":"ResourceList"}', 1, 'admin', 1, 'admin', '2021-05-26 15:23:07', '2023-03-14 15:44:34', 0, NULL);
 INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(11, 4, 'Xlqig32x/lxqSiae1/', 1, 1, '角色管理', 'roles', 10000001, '{"component":"system/role/RoleList","icon":"Menu","isKeepAlive":true,"routeName":"RoleList"}', 1, 'admin', 1, 'admin', '2021-05-27 11:15:35', '2023-03-14 15:44:22', 0, NULL);
 INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(12, 3, '12sSjal1/lskeiql1/Alw1Xkq3/', 2, 1, '机器终端按钮', 'machine:terminal', 40000000, '', 1, 'admin', 1, 'admin', '2021-05-28 14:06:02', '2021-05-31 17:47:59', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515200, 3, '12sSjal1/lskeiql1/Tm7sKqRx/', 2, 1, '终端会话监控', 'machine:terminal:monitor', 40000001, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515400, 3, '12sSjal1/lskeiql1/Sr4cTmRe/', 2, 1, '终端记录检索', 'machine:termrec:search', 40000002, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515500, 3, '12sSjal1/lskeiql1/Pf5wDkLo/', 2, 1, '端口转发', 'machine:portforward', 40000003, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515501, 3, '12sSjal1/lskeiql1/Pf6mGtRe/', 2, 1, '端口转发管理', 'machine:portforward:manage', 40000004, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515704, 3, '12sSjal1/lskeiql1/Bx8eRnMc/', 2, 1, '批量执行', 'machine:batchexec:run', 40000005, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
 INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(14, 4, 'Xlqig32x/sfslfel/', 1, 1, '账号管理', 'accounts', 9999999, '{"component":"system/account/AccountList","icon":"Menu","isKeepAlive":true,"routeName":"AccountList"}', 1, 'admin', 1, 'admin', '2021-05-28 14:56:25', '2023-03-14 15:44:10', 0, NULL);
 INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(15, 3, '12sSjal1/lskeiql1/Lsew24Kx/', 2, 1, '文件管理按钮', 'machine:file', 50000000, NULL, 1, 'admin', 1, 'admin', '2021-05-31 17:44:37', '2021-05-31 17:48:07', 0, NULL);
 INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(16, 3, '12sSjal1/lskeiql1/exIsqL31/', 2, 1, '机器添加按钮', 'machine:add', 10000000, NULL, 1, 'admin', 1, 'admin', '2021-05-31 17:46:11', '2021-05-31 19:34:15', 0, NULL);
@@ -815,6 +1059,14 @@ INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight
 INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(150, 36, 'Jra0n7De/', 1, 1, '数据同步', 'sync', 1693040707, '{"component":"ops/db/SyncTaskList","icon":"Coin","isKeepAlive":true,"routeName":"SyncTaskList"}', 12, 'liuzongyang', 12, 'liuzongyang', '2023-12-22 09:51:34', '2023-12-27 10:16:57', 0, NULL);
 INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(160, 135, 'dbms23ax/X0f4BxT0/3NUXQFIO/', 2, 1, '数据库备份', 'db:backup', 1705973876, 'null', 1, 'admin', 1, 'admin', '2024-01-23 09:37:56', '2024-01-23 09:37:56', 0, NULL);
 INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(161, 135, 'dbms23ax/X0f4BxT0/ghErkTdb/', 2, 1, '数据库恢复', 'db:restore', 1705973909, 'null', 1, 'admin', 1, 'admin', '2024-01-23 09:38:29', '2024-01-23 09:38:29', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515600, 135, 'dbms23ax/X0f4BxT0/Dm5kRsVe/', 2, 1, '脱敏规则-保存', 'db:datamask:save', 1760515600, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515601, 135, 'dbms23ax/X0f4BxT0/Dm5kRdEl/', 2, 1, '脱敏规则-删除', 'db:datamask:del', 1760515601, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515602, 135, 'dbms23ax/X0f4BxT0/Sq7rVwSv/', 2, 1, 'sql审核规则-保存', 'db:sqlreview:save', 1760515602, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515603, 135, 'dbms23ax/X0f4BxT0/Sq7rVwDl/', 2, 1, 'sql审核规则-删除', 'db:sqlreview:del', 1760515603, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515703, 135, 'dbms23ax/X0f4BxT0/Rb5kSqEx/', 2, 1, 'sql执行记录-回滚', 'db:sqlexec:rollback', 1760515703, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515700, 135, 'dbms23ax/X0f4BxT0/Se3sNlSt/', 2, 1, '会话-查看', 'db:session:list', 1760515700, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515701, 135, 'dbms23ax/X0f4BxT0/Se3sNkIl/', 2, 1, '会话-终止', 'db:session:kill', 1760515701, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515702, 135, 'dbms23ax/X0f4BxT0/Se3sNcQy/', 2, 1, '会话-取消查询', 'db:session:cancel', 1760515702, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
 INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1709208354, 1708911264, '6egfEVYr/fw0Hhvye/b4cNf3iq/', 2, 1, '删除流程', 'flow:procdef:del', 1709208354, 'null', 1, 'admin', 1, 'admin', '2024-02-29 20:05:54', '2024-02-29 20:05:54', 0, NULL);
 INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1709208339, 1708911264, '6egfEVYr/fw0Hhvye/r9ZMTHqC/', 2, 1, '保存流程', 'flow:procdef:save', 1709208339, 'null', 1, 'admin', 1, 'admin', '2024-02-29 20:05:40', '2024-02-29 20:05:40', 0, NULL);
 INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1709103180, 1708910975, '6egfEVYr/oNCIbynR/', 1, 1, '我的流程', 'procinsts', 1708911263, '{"component":"flow/ProcinstList","icon":"Tickets","isKeepAlive":true,"routeName":"ProcinstList"}', 1, 'admin', 1, 'admin', '2024-02-28 14:53:00', '2024-02-29 20:36:07', 0, NULL);
@@ -832,6 +1084,8 @@ INSERT INTO `t_sys_resource` (`id`, `pid`, `type`, `status`, `name`, `code`, `we
 INSERT INTO `t_sys_resource` (`id`, `pid`, `type`, `status`, `name`, `code`, `weight`, `meta`, `creator_id`, `creator`, `modifier_id`, `modifier`, `create_time`, `update_time`, `ui_path`, `is_deleted`, `delete_time`) VALUES(1709196755, 1709194669, 2, 1, '运行', 'db:transfer:run', 1709196755, 'null', 12, 'liuzongyang', 12, 'liuzongyang', '2024-02-29 16:52:36', '2024-02-29 16:52:36', 'SmLcpu6c/b6yHt6V2/', 0, NULL);
 INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1714032002, 1713875842, '12sSjal1/UnWIUhW0/0tJwC3Gf/', 2, 1, '命令配置-删除', 'cmdconf:del', 1714032002, 'null', 1, 'admin', 1, 'admin', '2024-04-25 16:00:02', '2024-04-25 16:00:02', 0, NULL);
 INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1714031981, 1713875842, '12sSjal1/UnWIUhW0/tEzIKecl/', 2, 1, '命令配置-保存', 'cmdconf:save', 1714031981, 'null', 1, 'admin', 1, 'admin', '2024-04-25 15:59:41', '2024-04-25 15:59:41', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515300, 1713875842, '12sSjal1/UnWIUhW0/Rk3mAlSv/', 2, 1, '告警规则-保存', 'alertrule:save', 1760515300, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515301, 1713875842, '12sSjal1/UnWIUhW0/Qe8nAlDl/', 2, 1, '告警规则-删除', 'alertrule:del', 1760515301, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
 INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1713875842, 2, '12sSjal1/UnWIUhW0/', 1, 1, '安全配置', 'security', 1713875842, '{"component":"ops/machine/security/SecurityConfList","icon":"Setting","isKeepAlive":true,"routeName":"SecurityConfList"}', 1, 'admin', 1, 'admin', '2024-04-23 20:37:22', '2024-04-23 20:37:22', 0, NULL);
 COMMIT;
 
@@ -1098,6 +1352,8 @@ CREATE TABLE `t_flow_procinst_task` (
   `remark` varchar(191) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT NULL,
   `end_time` datetime DEFAULT NULL COMMENT '结束时间',
   `duration` bigint DEFAULT NULL COMMENT '任务持续时间（开始到结束）',
+  `remind_time` datetime DEFAULT NULL COMMENT '超时提醒时间',
+  `escalated` tinyint(1) DEFAULT '0' COMMENT '是否为超时升级后创建的任务',
   `create_time` datetime NOT NULL COMMENT '任务开始时间',
   `creator` varchar(191) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT NULL,
   `creator_id` bigint NOT NULL,
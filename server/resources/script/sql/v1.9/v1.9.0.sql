@@ -0,0 +1,258 @@
+ALTER TABLE `t_flow_procinst_task`
+    ADD COLUMN `remind_time` datetime DEFAULT NULL COMMENT '超时提醒时间' AFTER `duration`,
+    ADD COLUMN `escalated` tinyint(1) DEFAULT '0' COMMENT '是否为超时升级后创建的任务' AFTER `remind_time`;
+
+CREATE TABLE IF NOT EXISTS `t_redis_key_analysis` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `redis_id` bigint NOT NULL COMMENT 'redis id',
+  `db` int DEFAULT NULL COMMENT '库号',
+  `match` varchar(255) DEFAULT NULL COMMENT 'key匹配规则',
+  `status` tinyint DEFAULT NULL COMMENT '状态 1运行中 2成功 -1失败',
+  `key_count` bigint DEFAULT NULL COMMENT '扫描的key数量',
+  `total_memory` bigint DEFAULT NULL COMMENT '扫描的key占用内存总数(字节)',
+  `result` longtext COMMENT '分析结果',
+  `err_msg` varchar(1000) DEFAULT NULL COMMENT '失败信息',
+  `end_time` datetime DEFAULT NULL COMMENT '结束时间',
+  `creator` varchar(32) DEFAULT NULL,
+  `creator_id` bigint DEFAULT NULL,
+  `create_time` datetime DEFAULT NULL,
+  `modifier` varchar(32) DEFAULT NULL,
+  `modifier_id` bigint DEFAULT NULL,
+  `update_time` datetime DEFAULT NULL,
+  `is_deleted` tinyint NOT NULL DEFAULT 0,
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_redis_id` (`redis_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='redis key分析记录';
+
+INSERT INTO `t_sys_config` (`name`, `key`, `params`, `value`, `remark`, `permission`, `create_time`, `creator_id`, `creator`, `update_time`, `modifier_id`, `modifier`, `is_deleted`, `delete_time`) VALUES('redis配置', 'RedisConfig', '[{"model":"keyImportPath","name":"key导入文件暂存路径","placeholder":"待审批的key导入文件暂存路径，默认./redis/key-import。多节点部署时需为各节点共享的存储"}]', '{"keyImportPath":"./redis/key-import"}', 'redis相关配置', 'admin,', '2025-10-15 10:00:00', 1, 'admin', '2025-10-15 10:00:00', 1, 'admin', 0, NULL);
+
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515200, 3, '12sSjal1/lskeiql1/Tm7sKqRx/', 2, 1, '终端会话监控', 'machine:terminal:monitor', 40000001, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+
+UPDATE `t_sys_config` SET `params` = '[{"name":"终端回放存储路径","model":"terminalRecPath","placeholder":"终端回放存储路径"},{"name":"uploadMaxFileSize","model":"uploadMaxFileSize","placeholder":"允许上传的最大文件大小(1MB、2GB等)"},{"model":"termOpSaveDays","name":"终端记录保存时间","placeholder":"终端记录保存时间（单位天）"},{"model":"guacdHost","name":"guacd服务ip","placeholder":"guacd服务ip，默认 127.0.0.1","required":false},{"name":"guacd服务端口","model":"guacdPort","placeholder":"guacd服务端口，默认 4822","required":false},{"model":"guacdFilePath","name":"guacd服务文件存储位置","placeholder":"guacd服务文件存储位置，用于挂载RDP文件夹"},{"name":"guacd服务记录存储位置","model":"guacdRecPath","placeholder":"guacd服务记录存储位置，用于记录rdp操作记录"},{"model":"monitorSaveDays","name":"监控数据保存时间","placeholder":"机器监控数据保存时间（单位天），默认7天"},{"model":"portForwardBindAddr","name":"端口转发监听地址","placeholder":"端口转发监听地址，默认 127.0.0.1"},{"model":"portForwardRemoteHosts","name":"端口转发允许的目标地址","placeholder":"ip、网段或主机名，多个逗号分隔，*为任意地址，默认只允许机器本地"}]' WHERE `key` = 'MachineConfig';
+
+ALTER TABLE `t_machine_monitor`
+    ADD COLUMN `load1` float(255,2) DEFAULT NULL COMMENT '1分钟平均负载' AFTER `sys_load`,
+    ADD COLUMN `disk_rate` float(255,2) DEFAULT NULL COMMENT '使用率最高的文件系统使用率' AFTER `load1`,
+    ADD COLUMN `fs_infos` text COMMENT '各文件系统使用信息' AFTER `disk_rate`,
+    ADD COLUMN `net_rx_rate` bigint(20) DEFAULT NULL COMMENT '网络接收速率(字节/秒)' AFTER `fs_infos`,
+    ADD COLUMN `net_tx_rate` bigint(20) DEFAULT NULL COMMENT '网络发送速率(字节/秒)' AFTER `net_rx_rate`,
+    ADD INDEX `idx_machine_id_create_time` (`machine_id`, `create_time`) USING BTREE,
+    ADD INDEX `idx_create_time` (`create_time`) USING BTREE;
+
+CREATE TABLE IF NOT EXISTS `t_machine_alert_rule` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(100) DEFAULT NULL COMMENT '名称',
+  `metric` varchar(32) NOT NULL COMMENT '告警指标 cpu、mem、disk、load1',
+  `threshold` float(255,2) NOT NULL COMMENT '阈值',
+  `duration` int(11) DEFAULT 0 COMMENT '持续时间(分钟)',
+  `receivers` varchar(500) DEFAULT NULL COMMENT '告警接收人用户名',
+  `status` tinyint(4) DEFAULT NULL COMMENT '状态 1启用 -1禁用',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `create_time` datetime NOT NULL,
+  `creator_id` bigint(20) NOT NULL,
+  `creator` varchar(36) NOT NULL,
+  `update_time` datetime NOT NULL,
+  `modifier_id` bigint(20) NOT NULL,
+  `modifier` varchar(36) NOT NULL,
+  `is_deleted` tinyint(4) DEFAULT '0',
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='机器监控告警规则';
+
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515300, 1713875842, '12sSjal1/UnWIUhW0/Rk3mAlSv/', 2, 1, '告警规则-保存', 'alertrule:save', 1760515300, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515301, 1713875842, '12sSjal1/UnWIUhW0/Qe8nAlDl/', 2, 1, '告警规则-删除', 'alertrule:del', 1760515301, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+
+ALTER TABLE t_machine_cmd_conf ADD COLUMN receivers varchar(500) DEFAULT NULL COMMENT '告警接收人或审批人' AFTER stratege;
+
+CREATE TABLE IF NOT EXISTS `t_machine_term_op_index` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT 'id',
+  `term_op_id` bigint unsigned NOT NULL COMMENT '终端操作记录id',
+  `machine_id` bigint unsigned NOT NULL COMMENT '机器id',
+  `creator` varchar(191) DEFAULT NULL COMMENT '终端操作人',
+  `type` tinyint NOT NULL COMMENT '内容类型 1执行命令 2终端输出',
+  `content` varchar(1000) COLLATE utf8mb4_general_ci DEFAULT NULL COMMENT '命令或输出行内容（不区分大小写，便于检索）',
+  `rec_offset` double DEFAULT 0 COMMENT '距回放开始的秒数',
+  `op_time` datetime DEFAULT NULL COMMENT '命令执行或输出时间',
+  `is_deleted` tinyint DEFAULT '0',
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_term_op_id` (`term_op_id`),
+  KEY `idx_machine_id_op_time` (`machine_id`, `op_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='机器终端操作记录检索索引';
+
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515400, 3, '12sSjal1/lskeiql1/Sr4cTmRe/', 2, 1, '终端记录检索', 'machine:termrec:search', 40000002, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+
+CREATE TABLE IF NOT EXISTS `t_machine_batch_exec` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(100) DEFAULT NULL COMMENT '名称',
+  `script` varchar(5000) DEFAULT NULL COMMENT '执行的命令',
+  `tag_paths` varchar(1000) DEFAULT NULL COMMENT '执行的机器所属标签',
+  `concurrency` int DEFAULT NULL COMMENT '并发执行的机器数',
+  `timeout` int DEFAULT NULL COMMENT '单台机器执行超时时间(秒)',
+  `status` tinyint DEFAULT NULL COMMENT '状态 1执行中 2全部成功 3存在失败',
+  `machine_count` int DEFAULT 0 COMMENT '机器数',
+  `success_count` int DEFAULT 0 COMMENT '成功数',
+  `fail_count` int DEFAULT 0 COMMENT '失败数',
+  `start_time` datetime DEFAULT NULL COMMENT '开始时间',
+  `end_time` datetime DEFAULT NULL COMMENT '结束时间',
+  `create_time` datetime NOT NULL,
+  `creator_id` bigint NOT NULL,
+  `creator` varchar(36) NOT NULL,
+  `update_time` datetime NOT NULL,
+  `modifier_id` bigint NOT NULL,
+  `modifier` varchar(36) NOT NULL,
+  `is_deleted` tinyint DEFAULT '0',
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='机器批量执行任务';
+
+CREATE TABLE IF NOT EXISTS `t_machine_batch_exec_res` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `batch_exec_id` bigint unsigned NOT NULL COMMENT '批量执行任务id',
+  `machine_id` bigint unsigned NOT NULL COMMENT '机器id',
+  `machine_code` varchar(36) DEFAULT NULL COMMENT '机器编号',
+  `machine_name` varchar(100) DEFAULT NULL COMMENT '机器名称',
+  `ip` varchar(100) DEFAULT NULL COMMENT 'ip',
+  `status` tinyint DEFAULT NULL COMMENT '状态 1成功 -1失败 -2超时',
+  `res` text COMMENT '执行输出',
+  `start_time` datetime DEFAULT NULL COMMENT '开始时间',
+  `end_time` datetime DEFAULT NULL COMMENT '结束时间',
+  `is_deleted` tinyint DEFAULT '0',
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_batch_exec_id` (`batch_exec_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='机器批量执行结果';
+
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515704, 3, '12sSjal1/lskeiql1/Bx8eRnMc/', 2, 1, '批量执行', 'machine:batchexec:run', 40000005, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+
+ALTER TABLE t_machine_cron_job ADD COLUMN timeout int DEFAULT 0 COMMENT '单次执行最大时间(秒)，0为不限制';
+ALTER TABLE t_machine_cron_job ADD COLUMN retry_count int DEFAULT 0 COMMENT '失败重试次数';
+ALTER TABLE t_machine_cron_job ADD COLUMN retry_interval int DEFAULT 0 COMMENT '首次重试间隔(秒)';
+ALTER TABLE t_machine_cron_job ADD COLUMN skip_if_running tinyint DEFAULT NULL COMMENT '上次执行未结束时是否跳过 1跳过';
+ALTER TABLE t_machine_cron_job ADD COLUMN fail_notify_threshold int DEFAULT 0 COMMENT '连续失败通知阈值';
+ALTER TABLE t_machine_cron_job ADD COLUMN receivers varchar(500) DEFAULT NULL COMMENT '失败通知接收人';
+
+CREATE TABLE IF NOT EXISTS `t_machine_port_forward` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `machine_id` bigint unsigned NOT NULL COMMENT '机器id',
+  `machine_code` varchar(36) DEFAULT NULL COMMENT '机器编号',
+  `machine_name` varchar(100) DEFAULT NULL COMMENT '机器名称',
+  `auth_cert_name` varchar(100) DEFAULT NULL COMMENT '授权凭证名',
+  `local_port` int DEFAULT NULL COMMENT '服务端监听端口',
+  `remote_host` varchar(100) DEFAULT NULL COMMENT '目标地址',
+  `remote_port` int DEFAULT NULL COMMENT '目标端口',
+  `duration` int DEFAULT NULL COMMENT '有效时长(分钟)',
+  `status` tinyint DEFAULT NULL COMMENT '状态 1转发中 2手动关闭 3到期关闭 -1异常关闭',
+  `expire_time` datetime DEFAULT NULL COMMENT '到期时间',
+  `close_time` datetime DEFAULT NULL COMMENT '关闭时间',
+  `closer` varchar(36) DEFAULT NULL COMMENT '手动关闭人',
+  `conn_count` bigint DEFAULT 0 COMMENT '累计连接数',
+  `bytes_in` bigint DEFAULT 0 COMMENT '客户端发送字节数',
+  `bytes_out` bigint DEFAULT 0 COMMENT '目标地址返回字节数',
+  `create_time` datetime NOT NULL,
+  `creator_id` bigint NOT NULL,
+  `creator` varchar(36) NOT NULL,
+  `update_time` datetime NOT NULL,
+  `modifier_id` bigint NOT NULL,
+  `modifier` varchar(36) NOT NULL,
+  `is_deleted` tinyint DEFAULT '0',
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_creator_id` (`creator_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='机器端口转发记录';
+
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515500, 3, '12sSjal1/lskeiql1/Pf5wDkLo/', 2, 1, '端口转发', 'machine:portforward', 40000003, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515501, 3, '12sSjal1/lskeiql1/Pf6mGtRe/', 2, 1, '端口转发管理', 'machine:portforward:manage', 40000004, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+
+ALTER TABLE t_machine_term_op ADD COLUMN protocol tinyint DEFAULT NULL COMMENT '会话协议 1ssh 2rdp 3vnc';
+ALTER TABLE t_machine_term_op ADD COLUMN target varchar(255) DEFAULT NULL COMMENT '终端目标，为空则为机器shell，否则为进入的容器';
+
+CREATE TABLE IF NOT EXISTS `t_db_data_mask_rule` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(100) DEFAULT NULL COMMENT '名称',
+  `db` varchar(200) DEFAULT NULL COMMENT '库名，为空则匹配所有库',
+  `table` varchar(200) DEFAULT NULL COMMENT '表名，为空则匹配所有表',
+  `columns` varchar(1000) NOT NULL COMMENT '需要脱敏的列名',
+  `mask_type` varchar(32) NOT NULL COMMENT '脱敏方式 phone、idcard、email、regex、hash、full',
+  `pattern` varchar(500) DEFAULT NULL COMMENT '正则脱敏时匹配的正则表达式',
+  `replacement` varchar(255) DEFAULT NULL COMMENT '正则脱敏时的替换内容',
+  `row_column` varchar(200) DEFAULT NULL COMMENT '行级脱敏条件列',
+  `row_pattern` varchar(500) DEFAULT NULL COMMENT '行级脱敏条件列值需匹配的正则表达式',
+  `exempt_role_ids` varchar(500) DEFAULT NULL COMMENT '豁免脱敏的角色id',
+  `status` tinyint(4) DEFAULT NULL COMMENT '状态 1启用 -1禁用',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `create_time` datetime NOT NULL,
+  `creator_id` bigint(20) NOT NULL,
+  `creator` varchar(36) NOT NULL,
+  `update_time` datetime NOT NULL,
+  `modifier_id` bigint(20) NOT NULL,
+  `modifier` varchar(36) NOT NULL,
+  `is_deleted` tinyint(4) DEFAULT '0',
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='数据库数据脱敏规则';
+
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515600, 135, 'dbms23ax/X0f4BxT0/Dm5kRsVe/', 2, 1, '脱敏规则-保存', 'db:datamask:save', 1760515600, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515601, 135, 'dbms23ax/X0f4BxT0/Dm5kRdEl/', 2, 1, '脱敏规则-删除', 'db:datamask:del', 1760515601, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+
+CREATE TABLE IF NOT EXISTS `t_db_sql_review_rule` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(100) DEFAULT NULL COMMENT '名称',
+  `rule_type` varchar(32) NOT NULL COMMENT '规则类型 noSelectStar、ddlRequireComment、noImplicitConversion、maxAffectedRows、changeWindow',
+  `level` varchar(16) NOT NULL COMMENT '处理级别 error禁止执行 warn警告',
+  `param` varchar(255) DEFAULT NULL COMMENT '规则参数，如最大影响行数、变更窗口时间段',
+  `status` tinyint(4) DEFAULT NULL COMMENT '状态 1启用 -1禁用',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `create_time` datetime NOT NULL,
+  `creator_id` bigint(20) NOT NULL,
+  `creator` varchar(36) NOT NULL,
+  `update_time` datetime NOT NULL,
+  `modifier_id` bigint(20) NOT NULL,
+  `modifier` varchar(36) NOT NULL,
+  `is_deleted` tinyint(4) DEFAULT '0',
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='数据库sql审核规则';
+
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515602, 135, 'dbms23ax/X0f4BxT0/Sq7rVwSv/', 2, 1, 'sql审核规则-保存', 'db:sqlreview:save', 1760515602, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515603, 135, 'dbms23ax/X0f4BxT0/Sq7rVwDl/', 2, 1, 'sql审核规则-删除', 'db:sqlreview:del', 1760515603, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+
+CREATE TABLE IF NOT EXISTS `t_db_query_job` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `db_id` bigint(20) NOT NULL COMMENT '数据库id',
+  `db` varchar(150) NOT NULL COMMENT '数据库名',
+  `sql` text NOT NULL COMMENT '查询sql',
+  `format` varchar(16) NOT NULL COMMENT '导出文件格式 csv、xlsx、json',
+  `status` tinyint(4) NOT NULL COMMENT '状态 1执行中 2成功 -1失败 -2已取消',
+  `row_count` bigint(20) DEFAULT NULL COMMENT '导出行数',
+  `file_name` varchar(255) DEFAULT NULL COMMENT '导出文件名',
+  `file_path` varchar(500) DEFAULT NULL COMMENT '导出文件存储路径',
+  `file_size` bigint(20) DEFAULT NULL COMMENT '导出文件大小',
+  `err_msg` varchar(2000) DEFAULT NULL COMMENT '执行失败信息',
+  `end_time` datetime DEFAULT NULL COMMENT '执行结束时间',
+  `expire_time` datetime DEFAULT NULL COMMENT '导出文件过期时间',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `create_time` datetime NOT NULL,
+  `creator_id` bigint(20) NOT NULL,
+  `creator` varchar(36) NOT NULL,
+  `update_time` datetime NOT NULL,
+  `modifier_id` bigint(20) NOT NULL,
+  `modifier` varchar(36) NOT NULL,
+  `is_deleted` tinyint(4) DEFAULT '0',
+  `delete_time` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_creator_id` (`creator_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='数据库异步查询任务';
+
+UPDATE `t_sys_config` SET `params` = '[{"model":"querySqlSave","name":"记录查询sql","placeholder":"是否记录查询类sql","options":"true,false"},{"model":"maxResultSet","name":"最大结果集","placeholder":"允许sql查询的最大结果集数。注: 0=不限制","options":""},{"model":"sqlExecTl","name":"sql执行时间限制","placeholder":"超过该时间（单位：秒），执行将被取消"},{"model":"queryJobPath","name":"异步查询导出路径","placeholder":"异步查询任务导出文件存储路径，默认./db/query-job"},{"model":"queryJobExpireHours","name":"异步查询导出有效期","placeholder":"导出文件有效时间（单位：小时），过期后将被删除，默认24"}]' WHERE `key` = 'DbmsConfig';
+
+-- 数据库会话管理权限
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515700, 135, 'dbms23ax/X0f4BxT0/Se3sNlSt/', 2, 1, '会话-查看', 'db:session:list', 1760515700, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515701, 135, 'dbms23ax/X0f4BxT0/Se3sNkIl/', 2, 1, '会话-终止', 'db:session:kill', 1760515701, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515702, 135, 'dbms23ax/X0f4BxT0/Se3sNcQy/', 2, 1, '会话-取消查询', 'db:session:cancel', 1760515702, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);
+
+-- sql执行记录回滚
+ALTER TABLE `t_db_sql_exec`
+    ADD COLUMN `rollback_id` bigint(20) NOT NULL DEFAULT 0 COMMENT '回滚该记录的sql执行记录id' AFTER `res`;
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(1760515703, 135, 'dbms23ax/X0f4BxT0/Rb5kSqEx/', 2, 1, 'sql执行记录-回滚', 'db:sqlexec:rollback', 1760515703, 'null', 1, 'admin', 1, 'admin', '2025-10-15 10:00:00', '2025-10-15 10:00:00', 0, NULL);